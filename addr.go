@@ -17,6 +17,7 @@ type Addr struct {
 	Broadcast   net.IP
 	PreferedLft int
 	ValidLft    int
+	RtPriority  int // metric of the prefix route, the kernel default when 0
 }
 
 // String returns $ip/$netmask $label
@@ -1,6 +1,7 @@
 package netlink
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net"
@@ -28,16 +29,61 @@ func (h *Handle) AddrAdd(link Link, addr *Addr) error {
 }
 
 // AddrReplace will replace (or, if not present, add) an IP address on a link device.
+// The address is updated in place, so lifetimes and flags can be changed
+// without the address ever leaving the link. Broadcast and scope are kept
+// from the existing address when not specified. Only when the kernel
+// refuses the update with EOPNOTSUPP is the address removed and added again.
 // Equivalent to: `ip addr replace $addr dev $link`
 func AddrReplace(link Link, addr *Addr) error {
 	return pkgHandle.AddrReplace(link, addr)
 }
 
 // AddrReplace will replace (or, if not present, add) an IP address on a link device.
+// The address is updated in place, so lifetimes and flags can be changed
+// without the address ever leaving the link. Broadcast and scope are kept
+// from the existing address when not specified. Only when the kernel
+// refuses the update with EOPNOTSUPP is the address removed and added again.
 // Equivalent to: `ip addr replace $addr dev $link`
 func (h *Handle) AddrReplace(link Link, addr *Addr) error {
+	replace := *addr
+	if replace.Broadcast == nil || replace.Scope == 0 {
+		if existing, err := h.addrFind(link, addr); err == nil && existing != nil {
+			if replace.Broadcast == nil {
+				replace.Broadcast = existing.Broadcast
+			}
+			if replace.Scope == 0 {
+				replace.Scope = existing.Scope
+			}
+		}
+	}
+
 	req := h.newNetlinkRequest(syscall.RTM_NEWADDR, syscall.NLM_F_CREATE|syscall.NLM_F_REPLACE|syscall.NLM_F_ACK)
-	return h.addrHandle(link, addr, req)
+	err := h.addrHandle(link, &replace, req)
+	if !errors.Is(err, syscall.EOPNOTSUPP) {
+		return err
+	}
+
+	// The kernel refused the in-place update, so remove and re-add it.
+	if err := h.AddrDel(link, &replace); err != nil && !errors.Is(err, syscall.EADDRNOTAVAIL) {
+		return err
+	}
+	return h.AddrAdd(link, &replace)
+}
+
+// addrFind returns the address on link matching addr's ip and prefix
+// length, or nil if there is none.
+func (h *Handle) addrFind(link Link, addr *Addr) (*Addr, error) {
+	addrs, err := h.AddrList(link, nl.GetIPFamily(addr.IP))
+	if err != nil {
+		return nil, err
+	}
+	ones, _ := addr.Mask.Size()
+	for i := range addrs {
+		if o, _ := addrs[i].Mask.Size(); o == ones && addrs[i].IP.Equal(addr.IP) {
+			return &addrs[i], nil
+		}
+	}
+	return nil, nil
 }
 
 // AddrDel will delete an IP address from a link device.
@@ -53,7 +99,48 @@ func (h *Handle) AddrDel(link Link, addr *Addr) error {
 	return h.addrHandle(link, addr, req)
 }
 
+// AddrReplaceAtomic removes an IP address from a link device and adds
+// another one, sending both requests in a single write so that the link
+// is left without either address as briefly as possible. The kernel
+// still handles them one after the other, so this is not truly atomic.
+// The add is sent even when the remove fails, the error of each is
+// returned.
+func AddrReplaceAtomic(link Link, remove, add *Addr) (removeErr, addErr error) {
+	return pkgHandle.AddrReplaceAtomic(link, remove, add)
+}
+
+// AddrReplaceAtomic removes an IP address from a link device and adds
+// another one, sending both requests in a single write so that the link
+// is left without either address as briefly as possible. The kernel
+// still handles them one after the other, so this is not truly atomic.
+// The add is sent even when the remove fails, the error of each is
+// returned.
+func (h *Handle) AddrReplaceAtomic(link Link, remove, add *Addr) (removeErr, addErr error) {
+	delReq := h.newNetlinkRequest(syscall.RTM_DELADDR, syscall.NLM_F_ACK)
+	if err := h.addrRequest(link, remove, delReq); err != nil {
+		return err, err
+	}
+	addReq := h.newNetlinkRequest(syscall.RTM_NEWADDR, syscall.NLM_F_CREATE|syscall.NLM_F_EXCL|syscall.NLM_F_ACK)
+	if err := h.addrRequest(link, add, addReq); err != nil {
+		return err, err
+	}
+	errs, err := nl.ExecuteBatch(syscall.NETLINK_ROUTE, []*nl.NetlinkRequest{delReq, addReq})
+	if errs == nil {
+		return err, err
+	}
+	return errs[0], errs[1]
+}
+
 func (h *Handle) addrHandle(link Link, addr *Addr, req *nl.NetlinkRequest) error {
+	if err := h.addrRequest(link, addr, req); err != nil {
+		return err
+	}
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+// addrRequest fills req with the header and attributes of addr on link.
+func (h *Handle) addrRequest(link Link, addr *Addr, req *nl.NetlinkRequest) error {
 	base := link.Attrs()
 	if addr.Label != "" && !strings.HasPrefix(addr.Label, base.Name) {
 		return fmt.Errorf("label must begin with interface name")
@@ -117,8 +204,18 @@ func (h *Handle) addrHandle(link Link, addr *Addr, req *nl.NetlinkRequest) error
 		req.AddData(labelData)
 	}
 
-	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
-	return err
+	if addr.ValidLft > 0 || addr.PreferedLft > 0 {
+		cachedata := nl.IfaCacheInfo{
+			IfaValid:    uint32(addr.ValidLft),
+			IfaPrefered: uint32(addr.PreferedLft),
+		}
+		req.AddData(nl.NewRtAttr(nl.IFA_CACHEINFO, cachedata.Serialize()))
+	}
+
+	if addr.RtPriority > 0 {
+		req.AddData(nl.NewRtAttr(nl.IFA_RT_PRIORITY, nl.Uint32Attr(uint32(addr.RtPriority))))
+	}
+	return nil
 }
 
 // AddrList gets a list of IP addresses in the system.
@@ -133,7 +230,8 @@ func AddrList(link Link, family int) ([]Addr, error) {
 // The list can be filtered by link and ip family.
 func (h *Handle) AddrList(link Link, family int) ([]Addr, error) {
 	req := h.newNetlinkRequest(syscall.RTM_GETADDR, syscall.NLM_F_DUMP)
-	msg := nl.NewIfInfomsg(family)
+	// strict checking rejects any other header
+	msg := nl.NewIfAddrmsg(family)
 	req.AddData(msg)
 
 	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWADDR)
@@ -210,6 +308,8 @@ func parseAddr(m []byte) (addr Addr, family, index int, err error) {
 			ci := nl.DeserializeIfaCacheInfo(attr.Value)
 			addr.PreferedLft = int(ci.IfaPrefered)
 			addr.ValidLft = int(ci.IfaValid)
+		case nl.IFA_RT_PRIORITY:
+			addr.RtPriority = int(native.Uint32(attr.Value[0:4]))
 		}
 	}
 
@@ -257,6 +357,23 @@ func addrSubscribe(newNs, curNs netns.NsHandle, ch chan<- AddrUpdate, done <-cha
 			s.Close()
 		}()
 	}
+	receiveAddrUpdates(s, ch)
+	return nil
+}
+
+// AddrSubscribe works like the package level AddrSubscribe, but subscribes
+// in the handle's network namespace. The subscription is also stopped
+// when the handle is closed.
+func (h *Handle) AddrSubscribe(ch chan<- AddrUpdate, done <-chan struct{}) error {
+	s, err := h.subscribe(done, syscall.NETLINK_ROUTE, syscall.RTNLGRP_IPV4_IFADDR, syscall.RTNLGRP_IPV6_IFADDR)
+	if err != nil {
+		return err
+	}
+	receiveAddrUpdates(s, ch)
+	return nil
+}
+
+func receiveAddrUpdates(s *nl.NetlinkSocket, ch chan<- AddrUpdate) {
 	go func() {
 		defer close(ch)
 		for {
@@ -288,6 +405,4 @@ func addrSubscribe(newNs, curNs netns.NsHandle, ch chan<- AddrUpdate, done <-cha
 			}
 		}
 	}()
-
-	return nil
 }
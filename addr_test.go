@@ -7,6 +7,7 @@ import (
 	"os"
 	"syscall"
 	"testing"
+	"time"
 )
 
 func TestAddrAdd(t *testing.T) {
@@ -191,3 +192,178 @@ func TestAddrAddReplace(t *testing.T) {
 		t.Fatal("Address not removed properly")
 	}
 }
+
+func TestAddrReplaceLifetime(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	address := &net.IPNet{IP: net.IPv4(127, 0, 0, 2), Mask: net.CIDRMask(24, 32)}
+	if err = AddrAdd(link, &Addr{IPNet: address, PreferedLft: 100, ValidLft: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan AddrUpdate)
+	done := make(chan struct{})
+	defer close(done)
+	if err := AddrSubscribe(ch, done); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = AddrReplace(link, &Addr{IPNet: address, PreferedLft: 1000, ValidLft: 1000}); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case update := <-ch:
+			if !update.LinkAddress.IP.Equal(address.IP) {
+				continue
+			}
+			if !update.NewAddr {
+				t.Fatal("Address was removed during replace")
+			}
+			if update.ValidLft <= 100 {
+				continue
+			}
+			addrs, err := AddrList(link, FAMILY_V4)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, a := range addrs {
+				if a.IP.Equal(address.IP) && a.ValidLft <= 100 {
+					t.Fatalf("Address lifetime not extended, got=%d", a.ValidLft)
+				}
+			}
+			return
+		case <-timeout:
+			t.Fatal("Timed out waiting for address update")
+		}
+	}
+}
+
+func TestAddrRtPriority(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	address := &net.IPNet{IP: net.IPv4(192, 168, 7, 1), Mask: net.CIDRMask(24, 32)}
+	if err = AddrAdd(link, &Addr{IPNet: address, RtPriority: 300}); err != nil {
+		t.Fatal(err)
+	}
+
+	addrs, err := AddrList(link, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 1 || !addrs[0].IP.Equal(address.IP) {
+		t.Fatalf("Address not added properly: %v", addrs)
+	}
+	if addrs[0].RtPriority != 300 {
+		t.Fatalf("Address rt priority is %d, expected 300", addrs[0].RtPriority)
+	}
+
+	routes, err := RouteList(link, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := &net.IPNet{IP: address.IP.Mask(address.Mask), Mask: address.Mask}
+	found := false
+	for _, route := range routes {
+		if route.Dst == nil || route.Dst.String() != prefix.String() {
+			continue
+		}
+		if route.Priority != 300 {
+			t.Fatalf("Prefix route metric is %d, expected 300", route.Priority)
+		}
+		found = true
+	}
+	if !found {
+		t.Fatalf("Prefix route %s not found: %v", prefix, routes)
+	}
+}
+
+func TestAddrReplaceAtomic(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	oldAddr := &Addr{IPNet: &net.IPNet{IP: net.IPv4(192, 168, 7, 1), Mask: net.CIDRMask(24, 32)}}
+	newAddr := &Addr{IPNet: &net.IPNet{IP: net.IPv4(192, 168, 7, 2), Mask: net.CIDRMask(24, 32)}}
+	if err = AddrAdd(link, oldAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan AddrUpdate)
+	done := make(chan struct{})
+	defer close(done)
+	if err := AddrSubscribe(ch, done); err != nil {
+		t.Fatal(err)
+	}
+
+	removeErr, addErr := AddrReplaceAtomic(link, oldAddr, newAddr)
+	if removeErr != nil || addErr != nil {
+		t.Fatalf("Failed to swap addresses: remove %v, add %v", removeErr, addErr)
+	}
+
+	var removed, added time.Time
+	timeout := time.After(time.Second)
+	for removed.IsZero() || added.IsZero() {
+		select {
+		case update := <-ch:
+			if !update.NewAddr && update.LinkAddress.IP.Equal(oldAddr.IP) {
+				removed = time.Now()
+			}
+			if update.NewAddr && update.LinkAddress.IP.Equal(newAddr.IP) {
+				added = time.Now()
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for address updates")
+		}
+	}
+	t.Logf("Link had neither address for %s", added.Sub(removed))
+
+	addrs, err := AddrList(link, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 1 || !addrs[0].IP.Equal(newAddr.IP) {
+		t.Fatalf("Addresses not swapped: %v", addrs)
+	}
+
+	// the add is still sent when the remove fails
+	removeErr, addErr = AddrReplaceAtomic(link, oldAddr, oldAddr)
+	if removeErr == nil || addErr != nil {
+		t.Fatalf("Swap from a missing address returned remove %v, add %v", removeErr, addErr)
+	}
+}
@@ -16,6 +16,89 @@ func BridgeVlanList() (map[int32][]*nl.BridgeVlanInfo, error) {
 // BridgeVlanList gets a map of device id to bridge vlan infos.
 // Equivalent to: `bridge vlan show`
 func (h *Handle) BridgeVlanList() (map[int32][]*nl.BridgeVlanInfo, error) {
+	ret := make(map[int32][]*nl.BridgeVlanInfo)
+	err := h.bridgeAfSpecList(func(index int32, nestAttr syscall.NetlinkRouteAttr) error {
+		switch nestAttr.Attr.Type {
+		case nl.IFLA_BRIDGE_VLAN_INFO:
+			vlanInfo := nl.DeserializeBridgeVlanInfo(nestAttr.Value)
+			ret[index] = append(ret[index], vlanInfo)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// BridgeVlanTunnel maps the vlan Vid of a bridge port to the tunnel id
+// Vni, the port is usually a vxlan link in external mode.
+type BridgeVlanTunnel struct {
+	Vid uint16
+	Vni uint32
+}
+
+// BridgeVlanTunnelList gets a map of device id to the vlan tunnel
+// mappings of the bridge ports, only ports with vlan tunnels enabled
+// report them. Ranges of mappings are expanded.
+// Equivalent to: `bridge vlan tunnelshow`
+func BridgeVlanTunnelList() (map[int32][]BridgeVlanTunnel, error) {
+	return pkgHandle.BridgeVlanTunnelList()
+}
+
+// BridgeVlanTunnelList gets a map of device id to the vlan tunnel
+// mappings of the bridge ports, only ports with vlan tunnels enabled
+// report them. Ranges of mappings are expanded.
+// Equivalent to: `bridge vlan tunnelshow`
+func (h *Handle) BridgeVlanTunnelList() (map[int32][]BridgeVlanTunnel, error) {
+	ret := make(map[int32][]BridgeVlanTunnel)
+	var rangeBegin *BridgeVlanTunnel
+	err := h.bridgeAfSpecList(func(index int32, nestAttr syscall.NetlinkRouteAttr) error {
+		if nestAttr.Attr.Type != nl.IFLA_BRIDGE_VLAN_TUNNEL_INFO {
+			return nil
+		}
+		attrs, err := nl.ParseRouteAttr(nestAttr.Value)
+		if err != nil {
+			return fmt.Errorf("failed to parse vlan tunnel info %v", err)
+		}
+		var tunnel BridgeVlanTunnel
+		var flags uint16
+		for _, attr := range attrs {
+			switch attr.Attr.Type {
+			case nl.IFLA_BRIDGE_VLAN_TUNNEL_ID:
+				tunnel.Vni = native.Uint32(attr.Value[0:4])
+			case nl.IFLA_BRIDGE_VLAN_TUNNEL_VID:
+				tunnel.Vid = native.Uint16(attr.Value[0:2])
+			case nl.IFLA_BRIDGE_VLAN_TUNNEL_FLAGS:
+				flags = native.Uint16(attr.Value[0:2])
+			}
+		}
+		switch {
+		case flags&nl.BRIDGE_VLAN_INFO_RANGE_BEGIN != 0:
+			rangeBegin = &tunnel
+		case flags&nl.BRIDGE_VLAN_INFO_RANGE_END != 0 && rangeBegin != nil:
+			for vid := rangeBegin.Vid; vid < tunnel.Vid; vid++ {
+				ret[index] = append(ret[index], BridgeVlanTunnel{
+					Vid: vid,
+					Vni: rangeBegin.Vni + uint32(vid-rangeBegin.Vid),
+				})
+			}
+			ret[index] = append(ret[index], tunnel)
+			rangeBegin = nil
+		default:
+			ret[index] = append(ret[index], tunnel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// bridgeAfSpecList dumps the bridge ports and calls fn with the index of
+// the port for each attribute nested in their IFLA_AF_SPEC.
+func (h *Handle) bridgeAfSpecList(fn func(index int32, nestAttr syscall.NetlinkRouteAttr) error) error {
 	req := h.newNetlinkRequest(syscall.RTM_GETLINK, syscall.NLM_F_DUMP)
 	msg := nl.NewIfInfomsg(syscall.AF_BRIDGE)
 	req.AddData(msg)
@@ -23,15 +106,14 @@ func (h *Handle) BridgeVlanList() (map[int32][]*nl.BridgeVlanInfo, error) {
 
 	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWLINK)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	ret := make(map[int32][]*nl.BridgeVlanInfo)
 	for _, m := range msgs {
 		msg := nl.DeserializeIfInfomsg(m)
 
 		attrs, err := nl.ParseRouteAttr(m[msg.Len():])
 		if err != nil {
-			return nil, err
+			return err
 		}
 		for _, attr := range attrs {
 			switch attr.Attr.Type {
@@ -39,19 +121,17 @@ func (h *Handle) BridgeVlanList() (map[int32][]*nl.BridgeVlanInfo, error) {
 				//nested attr
 				nestAttrs, err := nl.ParseRouteAttr(attr.Value)
 				if err != nil {
-					return nil, fmt.Errorf("failed to parse nested attr %v", err)
+					return fmt.Errorf("failed to parse nested attr %v", err)
 				}
 				for _, nestAttr := range nestAttrs {
-					switch nestAttr.Attr.Type {
-					case nl.IFLA_BRIDGE_VLAN_INFO:
-						vlanInfo := nl.DeserializeBridgeVlanInfo(nestAttr.Value)
-						ret[msg.Index] = append(ret[msg.Index], vlanInfo)
+					if err := fn(msg.Index, nestAttr); err != nil {
+						return err
 					}
 				}
 			}
 		}
 	}
-	return ret, nil
+	return nil
 }
 
 // BridgeVlanAdd adds a new vlan filter entry
@@ -113,3 +193,51 @@ func (h *Handle) bridgeVlanModify(cmd int, link Link, vid uint16, pvid, untagged
 	}
 	return nil
 }
+
+// BridgeVlanTunnelAdd maps the vlan of the bridge port to the tunnel id,
+// the vlan must already be on the port and vlan tunnels enabled on it
+// with LinkSetBrVlanTunnel.
+// Equivalent to: `bridge vlan add dev DEV vid VID tunnel_info id VNI`
+func BridgeVlanTunnelAdd(link Link, vid uint16, vni uint32) error {
+	return pkgHandle.BridgeVlanTunnelAdd(link, vid, vni)
+}
+
+// BridgeVlanTunnelAdd maps the vlan of the bridge port to the tunnel id,
+// the vlan must already be on the port and vlan tunnels enabled on it
+// with LinkSetBrVlanTunnel.
+// Equivalent to: `bridge vlan add dev DEV vid VID tunnel_info id VNI`
+func (h *Handle) BridgeVlanTunnelAdd(link Link, vid uint16, vni uint32) error {
+	return h.bridgeVlanTunnelModify(syscall.RTM_SETLINK, link, vid, vni)
+}
+
+// BridgeVlanTunnelDel removes the tunnel id mapping of the vlan of the
+// bridge port, the vlan itself stays on the port.
+// Equivalent to: `bridge vlan del dev DEV vid VID tunnel_info id VNI`
+func BridgeVlanTunnelDel(link Link, vid uint16, vni uint32) error {
+	return pkgHandle.BridgeVlanTunnelDel(link, vid, vni)
+}
+
+// BridgeVlanTunnelDel removes the tunnel id mapping of the vlan of the
+// bridge port, the vlan itself stays on the port.
+// Equivalent to: `bridge vlan del dev DEV vid VID tunnel_info id VNI`
+func (h *Handle) BridgeVlanTunnelDel(link Link, vid uint16, vni uint32) error {
+	return h.bridgeVlanTunnelModify(syscall.RTM_DELLINK, link, vid, vni)
+}
+
+func (h *Handle) bridgeVlanTunnelModify(cmd int, link Link, vid uint16, vni uint32) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(cmd, syscall.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(syscall.AF_BRIDGE)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	br := nl.NewRtAttr(nl.IFLA_AF_SPEC, nil)
+	info := nl.NewRtAttrChild(br, nl.IFLA_BRIDGE_VLAN_TUNNEL_INFO, nil)
+	nl.NewRtAttrChild(info, nl.IFLA_BRIDGE_VLAN_TUNNEL_ID, nl.Uint32Attr(vni))
+	nl.NewRtAttrChild(info, nl.IFLA_BRIDGE_VLAN_TUNNEL_VID, nl.Uint16Attr(vid))
+	req.AddData(br)
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
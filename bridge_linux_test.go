@@ -78,3 +78,48 @@ func TestBridgeVlan(t *testing.T) {
 		}
 	}
 }
+
+func TestBridgeVlanTunnel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	vlanFiltering := true
+	bridge := &Bridge{LinkAttrs: LinkAttrs{Name: "foo"}, VlanFiltering: &vlanFiltering}
+	if err := LinkAdd(bridge); err != nil {
+		t.Fatal(err)
+	}
+	vxlan := &Vxlan{LinkAttrs: LinkAttrs{Name: "vx0"}, FlowBased: true}
+	if err := LinkAdd(vxlan); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetMaster(vxlan, bridge); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetBrVlanTunnel(vxlan, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := BridgeVlanAdd(vxlan, 10, false, false, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := BridgeVlanTunnelAdd(vxlan, 10, 10010); err != nil {
+		t.Fatal(err)
+	}
+
+	tunnelMap, err := BridgeVlanTunnelList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tunnels := tunnelMap[int32(vxlan.Index)]; len(tunnels) != 1 || tunnels[0] != (BridgeVlanTunnel{Vid: 10, Vni: 10010}) {
+		t.Fatalf("unexpected vlan tunnels %v", tunnelMap)
+	}
+
+	if err := BridgeVlanTunnelDel(vxlan, 10, 10010); err != nil {
+		t.Fatal(err)
+	}
+	tunnelMap, err = BridgeVlanTunnelList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tunnels := tunnelMap[int32(vxlan.Index)]; len(tunnels) != 0 {
+		t.Fatalf("unexpected vlan tunnels after delete %v", tunnels)
+	}
+}
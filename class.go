@@ -62,6 +62,45 @@ func (q *HtbClass) Type() string {
 	return "htb"
 }
 
+// DrrClass represents a class of a Drr qdisc. Quantum is the number of
+// bytes the class may dequeue per round; zero uses the device MTU.
+type DrrClass struct {
+	ClassAttrs
+	Quantum uint32
+}
+
+func (q DrrClass) String() string {
+	return fmt.Sprintf("{Quantum: %d}", q.Quantum)
+}
+
+func (q *DrrClass) Attrs() *ClassAttrs {
+	return &q.ClassAttrs
+}
+
+func (q *DrrClass) Type() string {
+	return "drr"
+}
+
+// QfqClass represents a class of a Qfq qdisc. MaxPkt is the maximum
+// packet size of the class in bytes.
+type QfqClass struct {
+	ClassAttrs
+	Weight uint32
+	MaxPkt uint32
+}
+
+func (q QfqClass) String() string {
+	return fmt.Sprintf("{Weight: %d, MaxPkt: %d}", q.Weight, q.MaxPkt)
+}
+
+func (q *QfqClass) Attrs() *ClassAttrs {
+	return &q.ClassAttrs
+}
+
+func (q *QfqClass) Type() string {
+	return "qfq"
+}
+
 // GenericClass classes represent types that are not currently understood
 // by this netlink library.
 type GenericClass struct {
@@ -153,6 +153,17 @@ func classPayload(req *nl.NetlinkRequest, class Class) error {
 		nl.NewRtAttrChild(options, nl.TCA_HTB_PARMS, opt.Serialize())
 		nl.NewRtAttrChild(options, nl.TCA_HTB_RTAB, SerializeRtab(rtab))
 		nl.NewRtAttrChild(options, nl.TCA_HTB_CTAB, SerializeRtab(ctab))
+	} else if drr, ok := class.(*DrrClass); ok {
+		if drr.Quantum > 0 {
+			nl.NewRtAttrChild(options, nl.TCA_DRR_QUANTUM, nl.Uint32Attr(drr.Quantum))
+		}
+	} else if qfq, ok := class.(*QfqClass); ok {
+		if qfq.Weight > 0 {
+			nl.NewRtAttrChild(options, nl.TCA_QFQ_WEIGHT, nl.Uint32Attr(qfq.Weight))
+		}
+		if qfq.MaxPkt > 0 {
+			nl.NewRtAttrChild(options, nl.TCA_QFQ_LMAX, nl.Uint32Attr(qfq.MaxPkt))
+		}
 	}
 	req.AddData(options)
 	return nil
@@ -210,6 +221,10 @@ func (h *Handle) ClassList(link Link, parent uint32) ([]Class, error) {
 				switch classType {
 				case "htb":
 					class = &HtbClass{}
+				case "drr":
+					class = &DrrClass{}
+				case "qfq":
+					class = &QfqClass{}
 				default:
 					class = &GenericClass{ClassType: classType}
 				}
@@ -224,6 +239,18 @@ func (h *Handle) ClassList(link Link, parent uint32) ([]Class, error) {
 					if err != nil {
 						return nil, err
 					}
+				case "drr":
+					data, err := nl.ParseRouteAttr(attr.Value)
+					if err != nil {
+						return nil, err
+					}
+					parseDrrClassData(class, data)
+				case "qfq":
+					data, err := nl.ParseRouteAttr(attr.Value)
+					if err != nil {
+						return nil, err
+					}
+					parseQfqClassData(class, data)
 				}
 			}
 		}
@@ -252,3 +279,25 @@ func parseHtbClassData(class Class, data []syscall.NetlinkRouteAttr) (bool, erro
 	}
 	return detailed, nil
 }
+
+func parseDrrClassData(class Class, data []syscall.NetlinkRouteAttr) {
+	drr := class.(*DrrClass)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_DRR_QUANTUM:
+			drr.Quantum = native.Uint32(datum.Value[0:4])
+		}
+	}
+}
+
+func parseQfqClassData(class Class, data []syscall.NetlinkRouteAttr) {
+	qfq := class.(*QfqClass)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_QFQ_WEIGHT:
+			qfq.Weight = native.Uint32(datum.Value[0:4])
+		case nl.TCA_QFQ_LMAX:
+			qfq.MaxPkt = native.Uint32(datum.Value[0:4])
+		}
+	}
+}
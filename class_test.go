@@ -423,3 +423,150 @@ func TestHtbClassAddHtbClassChangeDel(t *testing.T) {
 		t.Fatal("Failed to remove qdisc")
 	}
 }
+
+func TestDrrClassAddDel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &Drr{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(1, 0),
+			Parent:    HANDLE_ROOT,
+		},
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	if _, ok := qdiscs[0].(*Drr); !ok {
+		t.Fatal("Qdisc is the wrong type")
+	}
+
+	class := &DrrClass{
+		ClassAttrs: ClassAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    MakeHandle(1, 0),
+			Handle:    MakeHandle(1, 1),
+		},
+		Quantum: 3000,
+	}
+	if err := ClassAdd(class); err != nil {
+		t.Fatal(err)
+	}
+	classes, err := ClassList(link, MakeHandle(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(classes) != 1 {
+		t.Fatal("Failed to add class")
+	}
+	drr, ok := classes[0].(*DrrClass)
+	if !ok {
+		t.Fatal("Class is the wrong type")
+	}
+	if drr.Quantum != class.Quantum {
+		t.Fatalf("Quantum doesn't match, got=%d, expected=%d", drr.Quantum, class.Quantum)
+	}
+
+	if err := ClassDel(class); err != nil {
+		t.Fatal(err)
+	}
+	classes, err = ClassList(link, MakeHandle(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(classes) != 0 {
+		t.Fatal("Failed to remove class")
+	}
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQfqClassAddDel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &Qfq{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(1, 0),
+			Parent:    HANDLE_ROOT,
+		},
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	if _, ok := qdiscs[0].(*Qfq); !ok {
+		t.Fatal("Qdisc is the wrong type")
+	}
+
+	class := &QfqClass{
+		ClassAttrs: ClassAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    MakeHandle(1, 0),
+			Handle:    MakeHandle(1, 1),
+		},
+		Weight: 10,
+		MaxPkt: 1514,
+	}
+	if err := ClassAdd(class); err != nil {
+		t.Fatal(err)
+	}
+	classes, err := ClassList(link, MakeHandle(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(classes) != 1 {
+		t.Fatal("Failed to add class")
+	}
+	qfq, ok := classes[0].(*QfqClass)
+	if !ok {
+		t.Fatal("Class is the wrong type")
+	}
+	if qfq.Weight != class.Weight {
+		t.Fatalf("Weight doesn't match, got=%d, expected=%d", qfq.Weight, class.Weight)
+	}
+	if qfq.MaxPkt != class.MaxPkt {
+		t.Fatalf("MaxPkt doesn't match, got=%d, expected=%d", qfq.MaxPkt, class.MaxPkt)
+	}
+
+	if err := ClassDel(class); err != nil {
+		t.Fatal(err)
+	}
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+}
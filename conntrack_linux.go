@@ -51,6 +51,12 @@ func ConntrackTableList(table ConntrackTableType, family InetFamily) ([]*Conntra
 	return pkgHandle.ConntrackTableList(table, family)
 }
 
+// ConntrackCreate injects a new flow into the conntrack table
+// conntrack -I [table] parameters         Create a conntrack or expectation
+func ConntrackCreate(flow *ConntrackFlow) error {
+	return pkgHandle.ConntrackCreate(flow)
+}
+
 // ConntrackTableFlush flushes all the flows of a specified table
 // conntrack -F [table]            Flush table
 // The flush operation applies to all the family types
@@ -58,12 +64,25 @@ func ConntrackTableFlush(table ConntrackTableType) error {
 	return pkgHandle.ConntrackTableFlush(table)
 }
 
+// ConntrackTableListFiltered returns the flows of a table of a specific family matching the filter
+// conntrack -L [table] parameters         List conntrack or expectation table
+func ConntrackTableListFiltered(table ConntrackTableType, family InetFamily, filter CustomConntrackFilter) ([]*ConntrackFlow, error) {
+	return pkgHandle.ConntrackTableListFiltered(table, family, filter)
+}
+
 // ConntrackDeleteFilter deletes entries on the specified table on the base of the filter
 // conntrack -D [table] parameters         Delete conntrack or expectation
 func ConntrackDeleteFilter(table ConntrackTableType, family InetFamily, filter CustomConntrackFilter) (uint, error) {
 	return pkgHandle.ConntrackDeleteFilter(table, family, filter)
 }
 
+// ConntrackExpectList returns the expectations the conntrack helpers
+// installed for a specific family
+// conntrack -L expect [options]          List expectation table
+func ConntrackExpectList(family InetFamily) ([]*ConntrackExpectEntry, error) {
+	return pkgHandle.ConntrackExpectList(family)
+}
+
 // ConntrackTableList returns the flow list of a table of a specific family using the netlink handle passed
 // conntrack -L [table] [options]          List conntrack or expectation table
 func (h *Handle) ConntrackTableList(table ConntrackTableType, family InetFamily) ([]*ConntrackFlow, error) {
@@ -81,6 +100,43 @@ func (h *Handle) ConntrackTableList(table ConntrackTableType, family InetFamily)
 	return result, nil
 }
 
+// ConntrackTableListFiltered returns the flows of a table of a specific family matching the filter
+// using the netlink handle passed
+// conntrack -L [table] parameters         List conntrack or expectation table
+func (h *Handle) ConntrackTableListFiltered(table ConntrackTableType, family InetFamily, filter CustomConntrackFilter) ([]*ConntrackFlow, error) {
+	res, err := h.dumpConntrackTable(table, family)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*ConntrackFlow
+	for _, dataRaw := range res {
+		if flow := parseRawData(dataRaw); filter.MatchConntrackFlow(flow) {
+			result = append(result, flow)
+		}
+	}
+
+	return result, nil
+}
+
+// ConntrackCreate injects a new flow into the conntrack table using the netlink handle passed
+// conntrack -I [table] parameters         Create a conntrack or expectation
+// The flow must carry both tuples and a non zero Timeout, its Mark, Status
+// and Zone are only sent when non zero.
+func (h *Handle) ConntrackCreate(flow *ConntrackFlow) error {
+	family := flow.FamilyType
+	if family == 0 {
+		family = uint8(nl.GetIPFamily(flow.Forward.SrcIP))
+	}
+	req := h.newConntrackRequest(ConntrackTable, InetFamily(family), nl.IPCTNL_MSG_CT_NEW,
+		syscall.NLM_F_CREATE|syscall.NLM_F_EXCL|syscall.NLM_F_ACK)
+	for _, attr := range flow.toNlData(family) {
+		req.AddData(attr)
+	}
+	_, err := req.Execute(syscall.NETLINK_NETFILTER, 0)
+	return err
+}
+
 // ConntrackTableFlush flushes all the flows of a specified table using the netlink handle passed
 // conntrack -F [table]            Flush table
 // The flush operation applies to all the family types
@@ -113,6 +169,28 @@ func (h *Handle) ConntrackDeleteFilter(table ConntrackTableType, family InetFami
 	return matched, nil
 }
 
+// ConntrackExpectList returns the expectations the conntrack helpers
+// installed for a specific family using the netlink handle passed
+// conntrack -L expect [options]          List expectation table
+func (h *Handle) ConntrackExpectList(family InetFamily) ([]*ConntrackExpectEntry, error) {
+	req := h.newConntrackRequest(ConntrackExpectTable, family, nl.IPCTNL_MSG_EXP_GET, syscall.NLM_F_DUMP)
+	res, err := req.Execute(syscall.NETLINK_NETFILTER, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*ConntrackExpectEntry
+	for _, dataRaw := range res {
+		exp, err := parseRawExpectData(dataRaw)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, exp)
+	}
+
+	return result, nil
+}
+
 func (h *Handle) newConntrackRequest(table ConntrackTableType, family InetFamily, operation, flags int) *nl.NetlinkRequest {
 	// Create the Netlink request object
 	req := h.newNetlinkRequest((int(table)<<8)|operation, flags)
@@ -147,6 +225,37 @@ type ConntrackFlow struct {
 	Forward    ipTuple
 	Reverse    ipTuple
 	Mark       uint32
+	Status     uint32 // bitmask of nl.IPS_* flags
+	Timeout    uint32 // in seconds
+	Zone       uint16
+	TCPState   uint8 // nl.TCP_CONNTRACK_* state of TCP flows
+}
+
+// ConntrackExpectEntry is an expectation a conntrack helper installed
+// for a related connection, e.g. the data channel of an FTP session.
+// Master is the original tuple of the flow that created it and the
+// fields of Tuple are only compared where Mask is non zero.
+type ConntrackExpectEntry struct {
+	FamilyType uint8
+	Master     ipTuple
+	Tuple      ipTuple
+	Mask       ipTuple
+	Timeout    uint32 // in seconds
+	HelperName string
+	Zone       uint16
+	Flags      uint32
+	Class      uint32
+}
+
+func (e *ConntrackExpectEntry) String() string {
+	// conntrack -L expect output:
+	// 297 proto=6 src=127.0.0.1 dst=127.0.0.1 sport=0 dport=20000 mask-src=255.255.255.255 mask-dst=255.255.255.255 sport=0 dport=65535 master-src=127.0.0.1 master-dst=127.0.0.1 sport=4000 dport=21 class=0 helper=ftp
+	return fmt.Sprintf("%d proto=%d src=%s dst=%s sport=%d dport=%d mask-src=%s mask-dst=%s sport=%d dport=%d master-src=%s master-dst=%s sport=%d dport=%d class=%d helper=%s",
+		e.Timeout, e.Tuple.Protocol,
+		e.Tuple.SrcIP, e.Tuple.DstIP, e.Tuple.SrcPort, e.Tuple.DstPort,
+		e.Mask.SrcIP, e.Mask.DstIP, e.Mask.SrcPort, e.Mask.DstPort,
+		e.Master.SrcIP, e.Master.DstIP, e.Master.SrcPort, e.Master.DstPort,
+		e.Class, e.HelperName)
 }
 
 func (s *ConntrackFlow) String() string {
@@ -158,6 +267,54 @@ func (s *ConntrackFlow) String() string {
 		s.Reverse.SrcIP.String(), s.Reverse.DstIP.String(), s.Reverse.SrcPort, s.Reverse.DstPort, s.Mark)
 }
 
+func (t *ipTuple) addNlAttrs(parent *nl.RtAttr, family uint8) {
+	srcType, dstType := nl.CTA_IP_V4_SRC, nl.CTA_IP_V4_DST
+	srcIP, dstIP := t.SrcIP.To4(), t.DstIP.To4()
+	if family == syscall.AF_INET6 {
+		srcType, dstType = nl.CTA_IP_V6_SRC, nl.CTA_IP_V6_DST
+		srcIP, dstIP = t.SrcIP.To16(), t.DstIP.To16()
+	}
+
+	ip := nl.NewRtAttrChild(parent, nl.NLA_F_NESTED|nl.CTA_TUPLE_IP, nil)
+	nl.NewRtAttrChild(ip, srcType, srcIP)
+	nl.NewRtAttrChild(ip, dstType, dstIP)
+
+	proto := nl.NewRtAttrChild(parent, nl.NLA_F_NESTED|nl.CTA_TUPLE_PROTO, nil)
+	nl.NewRtAttrChild(proto, nl.CTA_PROTO_NUM, nl.Uint8Attr(t.Protocol))
+	nl.NewRtAttrChild(proto, nl.CTA_PROTO_SRC_PORT, nl.BEUint16Attr(t.SrcPort))
+	nl.NewRtAttrChild(proto, nl.CTA_PROTO_DST_PORT, nl.BEUint16Attr(t.DstPort))
+}
+
+func (s *ConntrackFlow) toNlData(family uint8) []*nl.RtAttr {
+	orig := nl.NewRtAttr(nl.NLA_F_NESTED|nl.CTA_TUPLE_ORIG, nil)
+	s.Forward.addNlAttrs(orig, family)
+	reply := nl.NewRtAttr(nl.NLA_F_NESTED|nl.CTA_TUPLE_REPLY, nil)
+	s.Reverse.addNlAttrs(reply, family)
+
+	attrs := []*nl.RtAttr{orig, reply}
+	if s.Timeout != 0 {
+		attrs = append(attrs, nl.NewRtAttr(nl.CTA_TIMEOUT, nl.BEUint32Attr(s.Timeout)))
+	}
+	if s.Mark != 0 {
+		attrs = append(attrs, nl.NewRtAttr(nl.CTA_MARK, nl.BEUint32Attr(s.Mark)))
+	}
+	if s.Status != 0 {
+		// The kernel marks injected flows confirmed before applying the
+		// status, and refuses one that would clear the bit
+		attrs = append(attrs, nl.NewRtAttr(nl.CTA_STATUS, nl.BEUint32Attr(s.Status|nl.IPS_CONFIRMED)))
+	}
+	if s.Zone != 0 {
+		attrs = append(attrs, nl.NewRtAttr(nl.CTA_ZONE, nl.BEUint16Attr(s.Zone)))
+	}
+	if s.Forward.Protocol == TCP_PROTO && s.TCPState != nl.TCP_CONNTRACK_NONE {
+		info := nl.NewRtAttr(nl.NLA_F_NESTED|nl.CTA_PROTOINFO, nil)
+		tcp := nl.NewRtAttrChild(info, nl.NLA_F_NESTED|nl.CTA_PROTOINFO_TCP, nil)
+		nl.NewRtAttrChild(tcp, nl.CTA_PROTOINFO_TCP_STATE, nl.Uint8Attr(s.TCPState))
+		attrs = append(attrs, info)
+	}
+	return attrs
+}
+
 // This method parse the ip tuple structure
 // The message structure is the following:
 // <len, [CTA_IP_V4_SRC|CTA_IP_V6_SRC], 16 bytes for the IP>
@@ -222,7 +379,6 @@ func parseBERaw16(r *bytes.Reader, v *uint16) {
 
 func parseRawData(data []byte) *ConntrackFlow {
 	s := &ConntrackFlow{}
-	var proto uint8
 	// First there is the Nfgenmsg header
 	// consume only the family field
 	reader := bytes.NewReader(data)
@@ -241,7 +397,7 @@ func parseRawData(data []byte) *ConntrackFlow {
 		nested, t, l := parseNfAttrTL(reader)
 		if nested && t == nl.CTA_TUPLE_ORIG {
 			if nested, t, _ = parseNfAttrTL(reader); nested && t == nl.CTA_TUPLE_IP {
-				proto = parseIpTuple(reader, &s.Forward)
+				parseIpTuple(reader, &s.Forward)
 			}
 		} else if nested && t == nl.CTA_TUPLE_REPLY {
 			if nested, t, _ = parseNfAttrTL(reader); nested && t == nl.CTA_TUPLE_IP {
@@ -255,22 +411,139 @@ func parseRawData(data []byte) *ConntrackFlow {
 			}
 		}
 	}
-	if proto == TCP_PROTO {
-		reader.Seek(64, seekCurrent)
-		_, t, _, v := parseNfAttrTLV(reader)
-		if t == nl.CTA_MARK {
-			s.Mark = uint32(v[3])
+	// The remaining top level attributes follow the tuples
+	for reader.Len() > 0 {
+		_, t, l := parseNfAttrTL(reader)
+		switch t {
+		case nl.CTA_MARK:
+			binary.Read(reader, binary.BigEndian, &s.Mark)
+		case nl.CTA_STATUS:
+			binary.Read(reader, binary.BigEndian, &s.Status)
+		case nl.CTA_TIMEOUT:
+			binary.Read(reader, binary.BigEndian, &s.Timeout)
+		case nl.CTA_ZONE:
+			binary.Read(reader, binary.BigEndian, &s.Zone)
+		case nl.CTA_PROTOINFO:
+			info := make([]byte, l)
+			reader.Read(info)
+			s.TCPState = parseProtoInfoTCPState(info)
+		default:
+			reader.Seek(int64(l), seekCurrent)
 		}
-	} else if proto == UDP_PROTO {
-		reader.Seek(16, seekCurrent)
-		_, t, _, v := parseNfAttrTLV(reader)
-		if t == nl.CTA_MARK {
-			s.Mark = uint32(v[3])
+		// attributes are padded to 4 bytes
+		if pad := (4 - l%4) % 4; pad != 0 {
+			reader.Seek(int64(pad), seekCurrent)
 		}
 	}
 	return s
 }
 
+// parseRawExpectData decodes an expectation of the IPCTNL_MSG_EXP_GET
+// dump. Unlike the flows its tuples are walked as attributes, the mask
+// tuple of a protocol without ports simply carries none.
+func parseRawExpectData(data []byte) (*ConntrackExpectEntry, error) {
+	if len(data) < nl.SizeofNfgenmsg {
+		return nil, fmt.Errorf("expectation message too short: %d bytes", len(data))
+	}
+	e := &ConntrackExpectEntry{
+		FamilyType: nl.DeserializeNfgenmsg(data).NfgenFamily,
+	}
+	attrs, err := nl.ParseRouteAttr(data[nl.SizeofNfgenmsg:])
+	if err != nil {
+		return nil, err
+	}
+	for _, attr := range attrs {
+		switch attr.Attr.Type &^ nl.NLA_F_NESTED {
+		case nl.CTA_EXPECT_MASTER:
+			err = parseIpTupleAttrs(attr.Value, &e.Master)
+		case nl.CTA_EXPECT_TUPLE:
+			err = parseIpTupleAttrs(attr.Value, &e.Tuple)
+		case nl.CTA_EXPECT_MASK:
+			err = parseIpTupleAttrs(attr.Value, &e.Mask)
+		case nl.CTA_EXPECT_TIMEOUT:
+			e.Timeout = binary.BigEndian.Uint32(attr.Value)
+		case nl.CTA_EXPECT_HELP_NAME:
+			e.HelperName = string(bytes.TrimRight(attr.Value, "\x00"))
+		case nl.CTA_EXPECT_ZONE:
+			e.Zone = binary.BigEndian.Uint16(attr.Value)
+		case nl.CTA_EXPECT_FLAGS:
+			e.Flags = binary.BigEndian.Uint32(attr.Value)
+		case nl.CTA_EXPECT_CLASS:
+			e.Class = binary.BigEndian.Uint32(attr.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// parseIpTupleAttrs fills tpl from the CTA_TUPLE_IP and CTA_TUPLE_PROTO
+// attributes nested in a tuple.
+func parseIpTupleAttrs(b []byte, tpl *ipTuple) error {
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return err
+	}
+	for _, attr := range attrs {
+		t := attr.Attr.Type &^ nl.NLA_F_NESTED
+		if t != nl.CTA_TUPLE_IP && t != nl.CTA_TUPLE_PROTO {
+			continue
+		}
+		children, err := nl.ParseRouteAttr(attr.Value)
+		if err != nil {
+			return err
+		}
+		switch t {
+		case nl.CTA_TUPLE_IP:
+			for _, a := range children {
+				switch a.Attr.Type {
+				case nl.CTA_IP_V4_SRC, nl.CTA_IP_V6_SRC:
+					tpl.SrcIP = net.IP(a.Value)
+				case nl.CTA_IP_V4_DST, nl.CTA_IP_V6_DST:
+					tpl.DstIP = net.IP(a.Value)
+				}
+			}
+		case nl.CTA_TUPLE_PROTO:
+			for _, a := range children {
+				switch a.Attr.Type {
+				case nl.CTA_PROTO_NUM:
+					tpl.Protocol = a.Value[0]
+				case nl.CTA_PROTO_SRC_PORT:
+					tpl.SrcPort = binary.BigEndian.Uint16(a.Value)
+				case nl.CTA_PROTO_DST_PORT:
+					tpl.DstPort = binary.BigEndian.Uint16(a.Value)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseProtoInfoTCPState returns the TCP state carried in the nested
+// attributes of CTA_PROTOINFO, TCP_CONNTRACK_NONE if there is none.
+func parseProtoInfoTCPState(b []byte) uint8 {
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return nl.TCP_CONNTRACK_NONE
+	}
+	for _, attr := range attrs {
+		if attr.Attr.Type&^nl.NLA_F_NESTED != nl.CTA_PROTOINFO_TCP {
+			continue
+		}
+		tcp, err := nl.ParseRouteAttr(attr.Value)
+		if err != nil {
+			break
+		}
+		for _, a := range tcp {
+			if a.Attr.Type == nl.CTA_PROTOINFO_TCP_STATE && len(a.Value) > 0 {
+				return a.Value[0]
+			}
+		}
+	}
+	return nl.TCP_CONNTRACK_NONE
+}
+
 // Conntrack parameters and options:
 //   -n, --src-nat ip                      source NAT ip
 //   -g, --dst-nat ip                      destination NAT ip
@@ -316,7 +589,10 @@ type CustomConntrackFilter interface {
 }
 
 type ConntrackFilter struct {
-	ipFilter map[ConntrackFilterType]net.IP
+	ipFilter       map[ConntrackFilterType]net.IP
+	zoneFilter     *uint16
+	protoFilter    *uint8
+	tcpStateFilter *uint8
 }
 
 // AddIP adds an IP to the conntrack filter
@@ -331,17 +607,59 @@ func (f *ConntrackFilter) AddIP(tp ConntrackFilterType, ip net.IP) error {
 	return nil
 }
 
+// AddZone restricts the conntrack filter to flows in the given zone
+func (f *ConntrackFilter) AddZone(zone uint16) error {
+	if f.zoneFilter != nil {
+		return errors.New("Filter attribute already present")
+	}
+	f.zoneFilter = &zone
+	return nil
+}
+
+// AddProtocol restricts the conntrack filter to flows of the given L4 protocol, e.g. TCP_PROTO
+func (f *ConntrackFilter) AddProtocol(proto uint8) error {
+	if f.protoFilter != nil {
+		return errors.New("Filter attribute already present")
+	}
+	f.protoFilter = &proto
+	return nil
+}
+
+// AddTCPState restricts the conntrack filter to TCP flows in the given nl.TCP_CONNTRACK_* state
+func (f *ConntrackFilter) AddTCPState(state uint8) error {
+	if f.tcpStateFilter != nil {
+		return errors.New("Filter attribute already present")
+	}
+	f.tcpStateFilter = &state
+	return nil
+}
+
 // MatchConntrackFlow applies the filter to the flow and returns true if the flow matches the filter
 // false otherwise
 func (f *ConntrackFilter) MatchConntrackFlow(flow *ConntrackFlow) bool {
-	if len(f.ipFilter) == 0 {
+	if len(f.ipFilter) == 0 && f.zoneFilter == nil && f.protoFilter == nil && f.tcpStateFilter == nil {
 		// empty filter always not match
 		return false
 	}
 
 	match := true
+	// -w, --zone value   Conntrack zone
+	if f.zoneFilter != nil {
+		match = *f.zoneFilter == flow.Zone
+	}
+
+	// -p, --protonum proto   Layer 4 Protocol
+	if f.protoFilter != nil {
+		match = match && *f.protoFilter == flow.Forward.Protocol
+	}
+
+	// --state state   TCP state
+	if f.tcpStateFilter != nil {
+		match = match && flow.Forward.Protocol == TCP_PROTO && *f.tcpStateFilter == flow.TCPState
+	}
+
 	// -orig-src ip   Source address from original direction
-	if elem, found := f.ipFilter[ConntrackOrigSrcIP]; found {
+	if elem, found := f.ipFilter[ConntrackOrigSrcIP]; match && found {
 		match = match && elem.Equal(flow.Forward.SrcIP)
 	}
 
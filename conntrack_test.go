@@ -3,10 +3,12 @@ package netlink
 import (
 	"fmt"
 	"net"
+	"os"
 	"runtime"
 	"syscall"
 	"testing"
 
+	"github.com/vishvananda/netlink/nl"
 	"github.com/vishvananda/netns"
 )
 
@@ -129,6 +131,331 @@ func TestConntrackTableList(t *testing.T) {
 	netns.Set(*origns)
 }
 
+// TestConntrackCreate test the creation of a flow
+// Injects an assured udp flow and checks that it is fetched from the conntrack table
+func TestConntrackCreate(t *testing.T) {
+	skipUnlessRoot(t)
+
+	// Creates a new namespace and bring up the loopback interface
+	origns, ns, h := nsCreateAndEnter(t)
+	defer netns.Set(*origns)
+	defer origns.Close()
+	defer ns.Close()
+	defer runtime.UnlockOSThread()
+
+	flow := &ConntrackFlow{
+		FamilyType: syscall.AF_INET,
+		Forward: ipTuple{
+			SrcIP:    net.ParseIP("10.0.0.1"),
+			DstIP:    net.ParseIP("10.0.0.2"),
+			Protocol: UDP_PROTO,
+			SrcPort:  5000,
+			DstPort:  6000,
+		},
+		Reverse: ipTuple{
+			SrcIP:    net.ParseIP("10.0.0.2"),
+			DstIP:    net.ParseIP("10.0.0.1"),
+			Protocol: UDP_PROTO,
+			SrcPort:  6000,
+			DstPort:  5000,
+		},
+		Mark:    0x1234,
+		Status:  nl.IPS_SEEN_REPLY | nl.IPS_ASSURED,
+		Timeout: 100,
+	}
+	err := h.ConntrackCreate(flow)
+	CheckErrorFail(t, err)
+
+	// Creating the same flow twice must fail
+	if err := h.ConntrackCreate(flow); err == nil {
+		t.Fatal("Creating a duplicate flow should fail")
+	}
+
+	flows, err := h.ConntrackTableList(ConntrackTable, syscall.AF_INET)
+	CheckErrorFail(t, err)
+
+	var found *ConntrackFlow
+	for _, f := range flows {
+		if f.Forward.Protocol == UDP_PROTO &&
+			f.Forward.SrcIP.Equal(flow.Forward.SrcIP) &&
+			f.Forward.DstPort == flow.Forward.DstPort &&
+			f.Reverse.SrcPort == flow.Reverse.SrcPort {
+			found = f
+		}
+	}
+	if found == nil {
+		t.Fatalf("Created flow not found in %v", flows)
+	}
+	if found.Mark != flow.Mark {
+		t.Fatalf("Mark is %#x, expected %#x", found.Mark, flow.Mark)
+	}
+	if found.Status&(nl.IPS_SEEN_REPLY|nl.IPS_ASSURED) != nl.IPS_SEEN_REPLY|nl.IPS_ASSURED {
+		t.Fatalf("Status %#x is missing the assured and seen reply bits", found.Status)
+	}
+	if found.Timeout == 0 || found.Timeout > flow.Timeout {
+		t.Fatalf("Timeout is %d, expected at most %d", found.Timeout, flow.Timeout)
+	}
+
+	// Switch back to the original namespace
+	netns.Set(*origns)
+}
+
+// TestConntrackExpectList test listing the expectations of a helper
+// Injects an FTP control flow bound to the ftp helper together with the
+// expectation the helper installs for a PORT command and checks that it is listed
+func TestConntrackExpectList(t *testing.T) {
+	skipUnlessRoot(t)
+	// nf_conntrack_ftp may be built in so /proc/modules is not enough
+	if _, err := os.Stat("/sys/module/nf_conntrack_ftp"); err != nil {
+		t.Skip("Skipped test because it requres kmodule nf_conntrack_ftp.")
+	}
+
+	// Creates a new namespace and bring up the loopback interface
+	origns, ns, h := nsCreateAndEnter(t)
+	defer netns.Set(*origns)
+	defer origns.Close()
+	defer ns.Close()
+	defer runtime.UnlockOSThread()
+
+	control := &ConntrackFlow{
+		FamilyType: syscall.AF_INET,
+		Forward: ipTuple{
+			SrcIP:    net.ParseIP("127.0.0.1"),
+			DstIP:    net.ParseIP("127.0.0.2"),
+			Protocol: TCP_PROTO,
+			SrcPort:  4000,
+			DstPort:  21,
+		},
+		Reverse: ipTuple{
+			SrcIP:    net.ParseIP("127.0.0.2"),
+			DstIP:    net.ParseIP("127.0.0.1"),
+			Protocol: TCP_PROTO,
+			SrcPort:  21,
+			DstPort:  4000,
+		},
+		Status:   nl.IPS_SEEN_REPLY | nl.IPS_ASSURED,
+		Timeout:  100,
+		TCPState: nl.TCP_CONNTRACK_ESTABLISHED,
+	}
+	req := h.newConntrackRequest(ConntrackTable, syscall.AF_INET, nl.IPCTNL_MSG_CT_NEW,
+		syscall.NLM_F_CREATE|syscall.NLM_F_EXCL|syscall.NLM_F_ACK)
+	for _, attr := range control.toNlData(syscall.AF_INET) {
+		req.AddData(attr)
+	}
+	help := nl.NewRtAttr(nl.NLA_F_NESTED|nl.CTA_HELP, nil)
+	nl.NewRtAttrChild(help, nl.CTA_HELP_NAME, nl.ZeroTerminated("ftp"))
+	req.AddData(help)
+	_, err := req.Execute(syscall.NETLINK_NETFILTER, 0)
+	CheckErrorFail(t, err)
+
+	// PORT 127,0,0,1,78,32 announces a data channel to 127.0.0.1:20000
+	expected := ipTuple{
+		SrcIP:    net.ParseIP("127.0.0.2"),
+		DstIP:    net.ParseIP("127.0.0.1"),
+		Protocol: TCP_PROTO,
+		DstPort:  20000,
+	}
+	mask := ipTuple{
+		SrcIP:    net.IPv4bcast,
+		DstIP:    net.IPv4bcast,
+		Protocol: TCP_PROTO,
+		DstPort:  0xffff,
+	}
+	req = h.newConntrackRequest(ConntrackExpectTable, syscall.AF_INET, nl.IPCTNL_MSG_EXP_NEW,
+		syscall.NLM_F_CREATE|syscall.NLM_F_EXCL|syscall.NLM_F_ACK)
+	master := nl.NewRtAttr(nl.NLA_F_NESTED|nl.CTA_EXPECT_MASTER, nil)
+	control.Forward.addNlAttrs(master, syscall.AF_INET)
+	tuple := nl.NewRtAttr(nl.NLA_F_NESTED|nl.CTA_EXPECT_TUPLE, nil)
+	expected.addNlAttrs(tuple, syscall.AF_INET)
+	maskAttr := nl.NewRtAttr(nl.NLA_F_NESTED|nl.CTA_EXPECT_MASK, nil)
+	mask.addNlAttrs(maskAttr, syscall.AF_INET)
+	req.AddData(master)
+	req.AddData(tuple)
+	req.AddData(maskAttr)
+	req.AddData(nl.NewRtAttr(nl.CTA_EXPECT_TIMEOUT, nl.BEUint32Attr(300)))
+	_, err = req.Execute(syscall.NETLINK_NETFILTER, 0)
+	CheckErrorFail(t, err)
+
+	exps, err := h.ConntrackExpectList(syscall.AF_INET)
+	CheckErrorFail(t, err)
+	if len(exps) != 1 {
+		t.Fatalf("Found %d expectations, expected 1: %v", len(exps), exps)
+	}
+	exp := exps[0]
+	if exp.FamilyType != syscall.AF_INET {
+		t.Fatalf("Family is %d, expected %d", exp.FamilyType, syscall.AF_INET)
+	}
+	if exp.HelperName != "ftp" {
+		t.Fatalf("Helper is %q, expected ftp", exp.HelperName)
+	}
+	if !exp.Master.SrcIP.Equal(control.Forward.SrcIP) || !exp.Master.DstIP.Equal(control.Forward.DstIP) ||
+		exp.Master.SrcPort != control.Forward.SrcPort || exp.Master.DstPort != control.Forward.DstPort {
+		t.Fatalf("Master tuple is %+v, expected %+v", exp.Master, control.Forward)
+	}
+	if !exp.Tuple.SrcIP.Equal(expected.SrcIP) || !exp.Tuple.DstIP.Equal(expected.DstIP) ||
+		exp.Tuple.Protocol != TCP_PROTO || exp.Tuple.DstPort != expected.DstPort {
+		t.Fatalf("Expected tuple is %+v, expected %+v", exp.Tuple, expected)
+	}
+	if !exp.Mask.DstIP.Equal(net.IPv4bcast) || exp.Mask.SrcPort != 0 || exp.Mask.DstPort != 0xffff {
+		t.Fatalf("Mask is %+v, expected %+v", exp.Mask, mask)
+	}
+	if exp.Timeout == 0 || exp.Timeout > 300 {
+		t.Fatalf("Timeout is %d, expected at most 300", exp.Timeout)
+	}
+
+	// The expectations go away with their master
+	err = h.ConntrackTableFlush(ConntrackTable)
+	CheckErrorFail(t, err)
+	exps, err = h.ConntrackExpectList(syscall.AF_INET)
+	CheckErrorFail(t, err)
+	if len(exps) != 0 {
+		t.Fatalf("Found %d expectations after the flush: %v", len(exps), exps)
+	}
+
+	// Switch back to the original namespace
+	netns.Set(*origns)
+}
+
+// TestConntrackTableListFiltered test listing flows filtered by protocol and TCP state
+// Injects TCP flows in different states and an UDP flow and checks that each filter selects the right ones
+func TestConntrackTableListFiltered(t *testing.T) {
+	skipUnlessRoot(t)
+
+	// Creates a new namespace and bring up the loopback interface
+	origns, ns, h := nsCreateAndEnter(t)
+	defer netns.Set(*origns)
+	defer origns.Close()
+	defer ns.Close()
+	defer runtime.UnlockOSThread()
+
+	newFlow := func(proto uint8, srcPort uint16, state uint8) *ConntrackFlow {
+		return &ConntrackFlow{
+			FamilyType: syscall.AF_INET,
+			Forward: ipTuple{
+				SrcIP:    net.ParseIP("10.0.0.1"),
+				DstIP:    net.ParseIP("10.0.0.2"),
+				Protocol: proto,
+				SrcPort:  srcPort,
+				DstPort:  80,
+			},
+			Reverse: ipTuple{
+				SrcIP:    net.ParseIP("10.0.0.2"),
+				DstIP:    net.ParseIP("10.0.0.1"),
+				Protocol: proto,
+				SrcPort:  80,
+				DstPort:  srcPort,
+			},
+			Status:   nl.IPS_SEEN_REPLY | nl.IPS_ASSURED,
+			Timeout:  100,
+			TCPState: state,
+		}
+	}
+	for _, flow := range []*ConntrackFlow{
+		newFlow(TCP_PROTO, 5000, nl.TCP_CONNTRACK_ESTABLISHED),
+		newFlow(TCP_PROTO, 5001, nl.TCP_CONNTRACK_ESTABLISHED),
+		newFlow(TCP_PROTO, 5002, nl.TCP_CONNTRACK_TIME_WAIT),
+		newFlow(UDP_PROTO, 5003, nl.TCP_CONNTRACK_NONE),
+	} {
+		CheckErrorFail(t, h.ConntrackCreate(flow))
+	}
+
+	filter := &ConntrackFilter{}
+	CheckErrorFail(t, filter.AddProtocol(TCP_PROTO))
+	flows, err := h.ConntrackTableListFiltered(ConntrackTable, syscall.AF_INET, filter)
+	CheckErrorFail(t, err)
+	if len(flows) != 3 {
+		t.Fatalf("Expected 3 tcp flows, got %v", flows)
+	}
+
+	filter = &ConntrackFilter{}
+	CheckErrorFail(t, filter.AddTCPState(nl.TCP_CONNTRACK_TIME_WAIT))
+	flows, err = h.ConntrackTableListFiltered(ConntrackTable, syscall.AF_INET, filter)
+	CheckErrorFail(t, err)
+	if len(flows) != 1 || flows[0].Forward.SrcPort != 5002 || flows[0].TCPState != nl.TCP_CONNTRACK_TIME_WAIT {
+		t.Fatalf("Expected the time wait flow, got %v", flows)
+	}
+
+	filter = &ConntrackFilter{}
+	CheckErrorFail(t, filter.AddProtocol(UDP_PROTO))
+	flows, err = h.ConntrackTableListFiltered(ConntrackTable, syscall.AF_INET, filter)
+	CheckErrorFail(t, err)
+	if len(flows) != 1 || flows[0].Forward.SrcPort != 5003 {
+		t.Fatalf("Expected the udp flow, got %v", flows)
+	}
+
+	// Switch back to the original namespace
+	netns.Set(*origns)
+}
+
+// TestConntrackZones test flows with identical tuples in different zones
+// Injects the same flow in two zones and checks that each zone is listed and deleted independently
+func TestConntrackZones(t *testing.T) {
+	skipUnlessRoot(t)
+
+	// Creates a new namespace and bring up the loopback interface
+	origns, ns, h := nsCreateAndEnter(t)
+	defer netns.Set(*origns)
+	defer origns.Close()
+	defer ns.Close()
+	defer runtime.UnlockOSThread()
+
+	for _, zone := range []uint16{10, 20} {
+		flow := &ConntrackFlow{
+			FamilyType: syscall.AF_INET,
+			Forward: ipTuple{
+				SrcIP:    net.ParseIP("10.0.0.1"),
+				DstIP:    net.ParseIP("10.0.0.2"),
+				Protocol: UDP_PROTO,
+				SrcPort:  5000,
+				DstPort:  6000,
+			},
+			Reverse: ipTuple{
+				SrcIP:    net.ParseIP("10.0.0.2"),
+				DstIP:    net.ParseIP("10.0.0.1"),
+				Protocol: UDP_PROTO,
+				SrcPort:  6000,
+				DstPort:  5000,
+			},
+			Timeout: 100,
+			Zone:    zone,
+		}
+		err := h.ConntrackCreate(flow)
+		CheckErrorFail(t, err)
+	}
+
+	countZone := func(zone uint16) int {
+		filter := &ConntrackFilter{}
+		CheckErrorFail(t, filter.AddZone(zone))
+		flows, err := h.ConntrackTableList(ConntrackTable, syscall.AF_INET)
+		CheckErrorFail(t, err)
+		var found int
+		for _, flow := range flows {
+			if filter.MatchConntrackFlow(flow) {
+				found++
+			}
+		}
+		return found
+	}
+	if a, b := countZone(10), countZone(20); a != 1 || b != 1 {
+		t.Fatalf("Expected one flow per zone, zone 10:%d, zone 20:%d", a, b)
+	}
+
+	// Delete the flow of zone 10 only
+	filter := &ConntrackFilter{}
+	filter.AddZone(10)
+	deleted, err := h.ConntrackDeleteFilter(ConntrackTable, syscall.AF_INET, filter)
+	CheckErrorFail(t, err)
+	if deleted != 1 {
+		t.Fatalf("Error deleted a wrong number of flows:%d instead of 1", deleted)
+	}
+	if a, b := countZone(10), countZone(20); a != 0 || b != 1 {
+		t.Fatalf("Error during the erase zone 10:%d, zone 20:%d", a, b)
+	}
+
+	// Switch back to the original namespace
+	netns.Set(*origns)
+}
+
 // TestConntrackTableFlush test the conntrack table flushing
 // Creates some flows and then call the table flush
 func TestConntrackTableFlush(t *testing.T) {
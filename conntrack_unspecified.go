@@ -11,6 +11,9 @@ type InetFamily uint8
 // ConntrackFlow placeholder
 type ConntrackFlow struct{}
 
+// ConntrackExpectEntry placeholder
+type ConntrackExpectEntry struct{}
+
 // ConntrackFilter placeholder
 type ConntrackFilter struct{}
 
@@ -20,6 +23,19 @@ func ConntrackTableList(table ConntrackTableType, family InetFamily) ([]*Conntra
 	return nil, ErrNotImplemented
 }
 
+// ConntrackExpectList returns the expectations the conntrack helpers
+// installed for a specific family
+// conntrack -L expect [options]          List expectation table
+func ConntrackExpectList(family InetFamily) ([]*ConntrackExpectEntry, error) {
+	return nil, ErrNotImplemented
+}
+
+// ConntrackCreate injects a new flow into the conntrack table
+// conntrack -I [table] parameters         Create a conntrack or expectation
+func ConntrackCreate(flow *ConntrackFlow) error {
+	return ErrNotImplemented
+}
+
 // ConntrackTableFlush flushes all the flows of a specified table
 // conntrack -F [table]            Flush table
 // The flush operation applies to all the family types
@@ -27,6 +43,12 @@ func ConntrackTableFlush(table ConntrackTableType) error {
 	return ErrNotImplemented
 }
 
+// ConntrackTableListFiltered returns the flows of a table of a specific family matching the filter
+// conntrack -L [table] parameters         List conntrack or expectation table
+func ConntrackTableListFiltered(table ConntrackTableType, family InetFamily, filter *ConntrackFilter) ([]*ConntrackFlow, error) {
+	return nil, ErrNotImplemented
+}
+
 // ConntrackDeleteFilter deletes entries on the specified table on the base of the filter
 // conntrack -D [table] parameters         Delete conntrack or expectation
 func ConntrackDeleteFilter(table ConntrackTableType, family InetFamily, filter *ConntrackFilter) (uint, error) {
@@ -39,6 +61,12 @@ func (h *Handle) ConntrackTableList(table ConntrackTableType, family InetFamily)
 	return nil, ErrNotImplemented
 }
 
+// ConntrackCreate injects a new flow into the conntrack table using the netlink handle passed
+// conntrack -I [table] parameters         Create a conntrack or expectation
+func (h *Handle) ConntrackCreate(flow *ConntrackFlow) error {
+	return ErrNotImplemented
+}
+
 // ConntrackTableFlush flushes all the flows of a specified table using the netlink handle passed
 // conntrack -F [table]            Flush table
 // The flush operation applies to all the family types
@@ -46,6 +74,13 @@ func (h *Handle) ConntrackTableFlush(table ConntrackTableType) error {
 	return ErrNotImplemented
 }
 
+// ConntrackTableListFiltered returns the flows of a table of a specific family matching the filter
+// using the netlink handle passed
+// conntrack -L [table] parameters         List conntrack or expectation table
+func (h *Handle) ConntrackTableListFiltered(table ConntrackTableType, family InetFamily, filter *ConntrackFilter) ([]*ConntrackFlow, error) {
+	return nil, ErrNotImplemented
+}
+
 // ConntrackDeleteFilter deletes entries on the specified table on the base of the filter using the netlink handle passed
 // conntrack -D [table] parameters         Delete conntrack or expectation
 func (h *Handle) ConntrackDeleteFilter(table ConntrackTableType, family InetFamily, filter *ConntrackFilter) (uint, error) {
@@ -0,0 +1,24 @@
+package netlink
+
+// DrvInfo is the driver information of a network interface as reported
+// by ethtool, along with the sizes of its feature sets.
+type DrvInfo struct {
+	Driver      string
+	Version     string
+	FwVersion   string
+	BusInfo     string
+	EromVersion string
+	NPrivFlags  uint32
+	NStats      uint32
+	TestInfoLen uint32
+	EedumpLen   uint32
+	RegdumpLen  uint32
+}
+
+// EthtoolFeatureState is the state of a netdev feature. Features that
+// are not changeable are fixed by the driver.
+type EthtoolFeatureState struct {
+	Active     bool
+	Wanted     bool
+	Changeable bool
+}
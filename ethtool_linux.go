@@ -0,0 +1,297 @@
+package netlink
+
+import (
+	"bytes"
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/vishvananda/netlink/nl"
+)
+
+const (
+	siocEthtool     = 0x8946
+	ethtoolGDrvInfo = 0x00000003
+)
+
+// struct ethtool_drvinfo
+type ethtoolDrvInfo struct {
+	Cmd         uint32
+	Driver      [32]byte
+	Version     [32]byte
+	FwVersion   [32]byte
+	BusInfo     [32]byte
+	EromVersion [32]byte
+	Reserved2   [12]byte
+	NPrivFlags  uint32
+	NStats      uint32
+	TestInfoLen uint32
+	EedumpLen   uint32
+	RegdumpLen  uint32
+}
+
+type ifReqData struct {
+	Name [IFNAMSIZ]byte
+	Data unsafe.Pointer
+	pad  [SizeOfIfReq - IFNAMSIZ - unsafe.Sizeof(uintptr(0))]byte
+}
+
+// EthtoolDrvInfo gets the driver information of the interface called name
+// in the current network namespace through the ETHTOOL_GDRVINFO ioctl.
+// Equivalent to: `ethtool -i $name`
+func EthtoolDrvInfo(name string) (*DrvInfo, error) {
+	if len(name) >= IFNAMSIZ {
+		return nil, syscall.EINVAL
+	}
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+
+	info := ethtoolDrvInfo{Cmd: ethtoolGDrvInfo}
+	var req ifReqData
+	copy(req.Name[:], name)
+	req.Data = unsafe.Pointer(&info)
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), siocEthtool, uintptr(unsafe.Pointer(&req)))
+	if errno != 0 {
+		return nil, errno
+	}
+
+	return &DrvInfo{
+		Driver:      cString(info.Driver[:]),
+		Version:     cString(info.Version[:]),
+		FwVersion:   cString(info.FwVersion[:]),
+		BusInfo:     cString(info.BusInfo[:]),
+		EromVersion: cString(info.EromVersion[:]),
+		NPrivFlags:  info.NPrivFlags,
+		NStats:      info.NStats,
+		TestInfoLen: info.TestInfoLen,
+		EedumpLen:   info.EedumpLen,
+		RegdumpLen:  info.RegdumpLen,
+	}, nil
+}
+
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// EthtoolFeatures gets the state of every netdev feature of the interface
+// called name, keyed by the feature name.
+// Equivalent to: `ethtool -k $name`
+func EthtoolFeatures(name string) (map[string]EthtoolFeatureState, error) {
+	return pkgHandle.EthtoolFeatures(name)
+}
+
+// EthtoolFeatures gets the state of every netdev feature of the interface
+// called name, keyed by the feature name.
+// Equivalent to: `ethtool -k $name`
+func (h *Handle) EthtoolFeatures(name string) (map[string]EthtoolFeatureState, error) {
+	names, err := h.ethtoolFeatureNames(name)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := h.newEthtoolRequest(nl.ETHTOOL_MSG_FEATURES_GET, 0)
+	if err != nil {
+		return nil, err
+	}
+	// verbose bitsets do not fit in a single receive buffer
+	req.AddData(ethtoolHeader(nl.ETHTOOL_A_FEATURES_HEADER, name, nl.ETHTOOL_FLAG_COMPACT_BITSETS))
+	msgs, err := req.Execute(syscall.NETLINK_GENERIC, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) != 1 {
+		return nil, fmt.Errorf("invalid response for ETHTOOL_MSG_FEATURES_GET")
+	}
+	attrs, err := nl.ParseRouteAttr(msgs[0][nl.SizeofGenlmsg:])
+	if err != nil {
+		return nil, err
+	}
+	sets := map[int]map[string]bool{}
+	for _, attr := range attrs {
+		typ := int(attr.Attr.Type &^ syscall.NLA_F_NESTED)
+		switch typ {
+		case nl.ETHTOOL_A_FEATURES_HW, nl.ETHTOOL_A_FEATURES_WANTED,
+			nl.ETHTOOL_A_FEATURES_ACTIVE, nl.ETHTOOL_A_FEATURES_NOCHANGE:
+			if sets[typ], err = parseEthtoolBitset(attr.Value, names); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	features := make(map[string]EthtoolFeatureState, len(names))
+	for _, feature := range names {
+		if feature == "" {
+			continue
+		}
+		features[feature] = EthtoolFeatureState{
+			Active:     sets[nl.ETHTOOL_A_FEATURES_ACTIVE][feature],
+			Wanted:     sets[nl.ETHTOOL_A_FEATURES_WANTED][feature],
+			Changeable: sets[nl.ETHTOOL_A_FEATURES_HW][feature] && !sets[nl.ETHTOOL_A_FEATURES_NOCHANGE][feature],
+		}
+	}
+	return features, nil
+}
+
+// EthtoolSetFeature turns the netdev feature of the interface called name
+// on or off. It only changes the wanted state, the kernel may keep the
+// feature inactive if it depends on others.
+// Equivalent to: `ethtool -K $name $feature on|off`
+func EthtoolSetFeature(name, feature string, enabled bool) error {
+	return pkgHandle.EthtoolSetFeature(name, feature, enabled)
+}
+
+// EthtoolSetFeature turns the netdev feature of the interface called name
+// on or off. It only changes the wanted state, the kernel may keep the
+// feature inactive if it depends on others.
+// Equivalent to: `ethtool -K $name $feature on|off`
+func (h *Handle) EthtoolSetFeature(name, feature string, enabled bool) error {
+	features, err := h.EthtoolFeatures(name)
+	if err != nil {
+		return err
+	}
+	state, ok := features[feature]
+	if !ok {
+		return fmt.Errorf("unknown feature %q", feature)
+	}
+	if !state.Changeable {
+		return fmt.Errorf("feature %q of %s is fixed", feature, name)
+	}
+
+	req, err := h.newEthtoolRequest(nl.ETHTOOL_MSG_FEATURES_SET, syscall.NLM_F_ACK)
+	if err != nil {
+		return err
+	}
+	req.AddData(ethtoolHeader(nl.ETHTOOL_A_FEATURES_HEADER, name, nl.ETHTOOL_FLAG_OMIT_REPLY))
+	wanted := nl.NewRtAttr(nl.ETHTOOL_A_FEATURES_WANTED|syscall.NLA_F_NESTED, nil)
+	bits := wanted.AddRtAttr(nl.ETHTOOL_A_BITSET_BITS|syscall.NLA_F_NESTED, nil)
+	bit := bits.AddRtAttr(nl.ETHTOOL_A_BITSET_BITS_BIT|syscall.NLA_F_NESTED, nil)
+	bit.AddRtAttr(nl.ETHTOOL_A_BITSET_BIT_NAME, nl.ZeroTerminated(feature))
+	if enabled {
+		bit.AddRtAttr(nl.ETHTOOL_A_BITSET_BIT_VALUE, nil)
+	}
+	req.AddData(wanted)
+
+	_, err = req.Execute(syscall.NETLINK_GENERIC, 0)
+	return err
+}
+
+func (h *Handle) newEthtoolRequest(cmd uint8, flags int) (*nl.NetlinkRequest, error) {
+	f, err := h.GenlFamilyGet(nl.ETHTOOL_GENL_NAME)
+	if err != nil {
+		return nil, err
+	}
+	req := h.newNetlinkRequest(int(f.ID), flags)
+	req.AddData(&nl.Genlmsg{
+		Command: cmd,
+		Version: nl.ETHTOOL_GENL_VERSION,
+	})
+	return req, nil
+}
+
+func ethtoolHeader(attrType int, name string, flags uint32) *nl.RtAttr {
+	header := nl.NewRtAttr(attrType|syscall.NLA_F_NESTED, nil)
+	header.AddRtAttr(nl.ETHTOOL_A_HEADER_DEV_NAME, nl.ZeroTerminated(name))
+	if flags != 0 {
+		header.AddRtAttr(nl.ETHTOOL_A_HEADER_FLAGS, nl.Uint32Attr(flags))
+	}
+	return header
+}
+
+// ethtoolFeatureNames gets the names of all the netdev features known to
+// the kernel, including the ones the device does not support, indexed by
+// their bit.
+func (h *Handle) ethtoolFeatureNames(name string) ([]string, error) {
+	req, err := h.newEthtoolRequest(nl.ETHTOOL_MSG_STRSET_GET, 0)
+	if err != nil {
+		return nil, err
+	}
+	req.AddData(ethtoolHeader(nl.ETHTOOL_A_STRSET_HEADER, name, 0))
+	sets := nl.NewRtAttr(nl.ETHTOOL_A_STRSET_STRINGSETS|syscall.NLA_F_NESTED, nil)
+	set := sets.AddRtAttr(nl.ETHTOOL_A_STRINGSETS_STRINGSET|syscall.NLA_F_NESTED, nil)
+	set.AddRtAttr(nl.ETHTOOL_A_STRINGSET_ID, nl.Uint32Attr(nl.ETH_SS_FEATURES))
+	req.AddData(sets)
+
+	msgs, err := req.Execute(syscall.NETLINK_GENERIC, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) != 1 {
+		return nil, fmt.Errorf("invalid response for ETHTOOL_MSG_STRSET_GET")
+	}
+	var names []string
+	err = ethtoolWalkNested(msgs[0][nl.SizeofGenlmsg:], []int{
+		nl.ETHTOOL_A_STRSET_STRINGSETS,
+		nl.ETHTOOL_A_STRINGSETS_STRINGSET,
+		nl.ETHTOOL_A_STRINGSET_STRINGS,
+		nl.ETHTOOL_A_STRINGS_STRING,
+	}, func(attrs []syscall.NetlinkRouteAttr) {
+		index := -1
+		var value string
+		for _, attr := range attrs {
+			switch attr.Attr.Type {
+			case nl.ETHTOOL_A_STRING_INDEX:
+				index = int(native.Uint32(attr.Value[0:4]))
+			case nl.ETHTOOL_A_STRING_VALUE:
+				value = cString(attr.Value)
+			}
+		}
+		if index < 0 {
+			return
+		}
+		for len(names) <= index {
+			names = append(names, "")
+		}
+		names[index] = value
+	})
+	return names, err
+}
+
+// ethtoolWalkNested calls fn with the attributes of every nest found by
+// following the given attribute types from b.
+func ethtoolWalkNested(b []byte, path []int, fn func([]syscall.NetlinkRouteAttr)) error {
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return err
+	}
+	if len(path) == 0 {
+		fn(attrs)
+		return nil
+	}
+	for _, attr := range attrs {
+		if int(attr.Attr.Type&^syscall.NLA_F_NESTED) != path[0] {
+			continue
+		}
+		if err := ethtoolWalkNested(attr.Value, path[1:], fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseEthtoolBitset returns the names of the bits set in the value of a
+// compact bitset.
+func parseEthtoolBitset(b []byte, names []string) (map[string]bool, error) {
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return nil, err
+	}
+	set := map[string]bool{}
+	for _, attr := range attrs {
+		if attr.Attr.Type != nl.ETHTOOL_A_BITSET_VALUE {
+			continue
+		}
+		for i := 0; i < len(names) && i/32 < len(attr.Value)/4; i++ {
+			word := native.Uint32(attr.Value[i/32*4:])
+			if word&(1<<uint(i%32)) != 0 && names[i] != "" {
+				set[names[i]] = true
+			}
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,68 @@
+// +build linux
+
+package netlink
+
+import "testing"
+
+func TestEthtoolDrvInfo(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	if err := LinkAdd(&Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := EthtoolDrvInfo("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Driver != "veth" {
+		t.Fatalf("Driver of foo is %q, expected veth", info.Driver)
+	}
+	if info.NStats == 0 {
+		t.Fatal("Statistics count of foo not reported")
+	}
+
+	if _, err := EthtoolDrvInfo("nonexistent"); err == nil {
+		t.Fatal("Driver info found for a nonexistent interface")
+	}
+}
+
+func TestEthtoolFeatures(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	if err := LinkAdd(&Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}); err != nil {
+		t.Fatal(err)
+	}
+
+	features, err := EthtoolFeatures("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, ok := features["rx-checksum"]
+	if !ok {
+		t.Fatal("rx-checksum feature not listed")
+	}
+	if state, ok := features["rx-ntuple-filter"]; !ok || state.Changeable {
+		t.Fatalf("rx-ntuple-filter feature is %+v, expected fixed", state)
+	}
+	if !state.Changeable {
+		t.Skip("rx-checksum is fixed on veth")
+	}
+
+	if err := EthtoolSetFeature("foo", "rx-checksum", !state.Wanted); err != nil {
+		t.Fatal(err)
+	}
+	features, err = EthtoolFeatures("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if features["rx-checksum"].Wanted == state.Wanted {
+		t.Fatalf("rx-checksum is still %+v", features["rx-checksum"])
+	}
+
+	if err := EthtoolSetFeature("foo", "rx-ntuple-filter", true); err == nil {
+		t.Fatal("Fixed rx-ntuple-filter feature changed")
+	}
+}
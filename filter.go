@@ -18,8 +18,9 @@ type FilterAttrs struct {
 	LinkIndex int
 	Handle    uint32
 	Parent    uint32
-	Priority  uint16 // lower is higher priority
-	Protocol  uint16 // syscall.ETH_P_*
+	Priority  uint16  // lower is higher priority
+	Protocol  uint16  // syscall.ETH_P_*
+	Chain     *uint32 // nil means the default chain 0
 }
 
 func (q FilterAttrs) String() string {
@@ -39,8 +40,13 @@ const (
 	TC_ACT_REPEAT     TcAct = 6
 	TC_ACT_REDIRECT   TcAct = 7
 	TC_ACT_JUMP       TcAct = 0x10000000
+	TC_ACT_GOTO_CHAIN TcAct = 0x20000000
 )
 
+// extended verdicts, like TC_ACT_GOTO_CHAIN, keep their argument
+// in the low bits of the action
+const tcActExtValMask = 0x0fffffff
+
 func (a TcAct) String() string {
 	switch a {
 	case TC_ACT_UNSPEC:
@@ -123,6 +129,28 @@ func (action *GenericAction) Attrs() *ActionAttrs {
 	return &action.ActionAttrs
 }
 
+// GotoChainAction is a gact action which continues the classification
+// with the filters of another chain.
+type GotoChainAction struct {
+	GenericAction
+	ChainID uint32
+}
+
+func (action *GotoChainAction) Type() string {
+	return "goto_chain"
+}
+
+func NewGotoChainAction(chainID uint32) *GotoChainAction {
+	return &GotoChainAction{
+		GenericAction: GenericAction{
+			ActionAttrs: ActionAttrs{
+				Action: TC_ACT_GOTO_CHAIN | TcAct(chainID&tcActExtValMask),
+			},
+		},
+		ChainID: chainID,
+	}
+}
+
 type BpfAction struct {
 	ActionAttrs
 	Fd   int
@@ -184,6 +212,104 @@ func NewMirredAction(redirIndex int) *MirredAction {
 	}
 }
 
+// SkbEditAction edits the metadata of the packets, the fields left nil
+// are not changed. Mask restricts the bits of the mark that are set.
+type SkbEditAction struct {
+	ActionAttrs
+	QueueMapping *uint16
+	PType        *uint16
+	Priority     *uint32
+	Mark         *uint32
+	Mask         *uint32
+}
+
+func (action *SkbEditAction) Type() string {
+	return "skbedit"
+}
+
+func (action *SkbEditAction) Attrs() *ActionAttrs {
+	return &action.ActionAttrs
+}
+
+func NewSkbEditAction() *SkbEditAction {
+	return &SkbEditAction{
+		ActionAttrs: ActionAttrs{
+			Action: TC_ACT_PIPE,
+		},
+	}
+}
+
+// ConnmarkAction restores the mark of the packets from the conntrack
+// entry of their connection in Zone.
+type ConnmarkAction struct {
+	ActionAttrs
+	Zone uint16
+}
+
+func (action *ConnmarkAction) Type() string {
+	return "connmark"
+}
+
+func (action *ConnmarkAction) Attrs() *ActionAttrs {
+	return &action.ActionAttrs
+}
+
+func NewConnmarkAction() *ConnmarkAction {
+	return &ConnmarkAction{
+		ActionAttrs: ActionAttrs{
+			Action: TC_ACT_PIPE,
+		},
+	}
+}
+
+type VlanAct int32
+
+func (a VlanAct) String() string {
+	switch a {
+	case TCA_VLAN_ACT_POP:
+		return "pop"
+	case TCA_VLAN_ACT_PUSH:
+		return "push"
+	case TCA_VLAN_ACT_MODIFY:
+		return "modify"
+	}
+	return "unknown"
+}
+
+const (
+	TCA_VLAN_ACT_POP    VlanAct = 1
+	TCA_VLAN_ACT_PUSH   VlanAct = 2
+	TCA_VLAN_ACT_MODIFY VlanAct = 3
+)
+
+// VlanAction pops the vlan tag of the packets, or pushes or modifies it
+// with VlanId, Protocol and Priority which are unused by a pop.
+type VlanAction struct {
+	ActionAttrs
+	VlanAction VlanAct
+	VlanId     uint16
+	Protocol   uint16 // syscall.ETH_P_8021Q or ETH_P_8021AD, zero for the kernel default
+	Priority   uint8
+}
+
+func (action *VlanAction) Type() string {
+	return "vlan"
+}
+
+func (action *VlanAction) Attrs() *ActionAttrs {
+	return &action.ActionAttrs
+}
+
+func NewVlanPushAction(vlanId uint16) *VlanAction {
+	return &VlanAction{
+		ActionAttrs: ActionAttrs{
+			Action: TC_ACT_PIPE,
+		},
+		VlanAction: TCA_VLAN_ACT_PUSH,
+		VlanId:     vlanId,
+	}
+}
+
 // Constants used in TcU32Sel.Flags.
 const (
 	TC_U32_TERMINAL  = nl.TC_U32_TERMINAL
@@ -235,6 +361,21 @@ func (filter *U32) Type() string {
 	return "u32"
 }
 
+// MatchAll filters match every packet and run their actions on it
+type MatchAll struct {
+	FilterAttrs
+	ClassId uint32
+	Actions []Action
+}
+
+func (filter *MatchAll) Attrs() *FilterAttrs {
+	return &filter.FilterAttrs
+}
+
+func (filter *MatchAll) Type() string {
+	return "matchall"
+}
+
 type FilterFwAttrs struct {
 	ClassId   uint32
 	InDev     string
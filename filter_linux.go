@@ -100,6 +100,9 @@ func (h *Handle) FilterDel(filter Filter) error {
 		Info:    MakeHandle(base.Priority, nl.Swap16(base.Protocol)),
 	}
 	req.AddData(msg)
+	if base.Chain != nil {
+		req.AddData(nl.NewRtAttr(nl.TCA_CHAIN, nl.Uint32Attr(*base.Chain)))
+	}
 
 	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
 	return err
@@ -125,6 +128,9 @@ func (h *Handle) FilterAdd(filter Filter) error {
 		Info:    MakeHandle(base.Priority, nl.Swap16(base.Protocol)),
 	}
 	req.AddData(msg)
+	if base.Chain != nil {
+		req.AddData(nl.NewRtAttr(nl.TCA_CHAIN, nl.Uint32Attr(*base.Chain)))
+	}
 	req.AddData(nl.NewRtAttr(nl.TCA_KIND, nl.ZeroTerminated(filter.Type())))
 
 	options := nl.NewRtAttr(nl.TCA_OPTIONS, nil)
@@ -211,11 +217,34 @@ func (h *Handle) FilterAdd(filter Filter) error {
 			bpfFlags |= nl.TCA_BPF_FLAG_ACT_DIRECT
 		}
 		nl.NewRtAttrChild(options, nl.TCA_BPF_FLAGS, nl.Uint32Attr(bpfFlags))
+	} else if matchAll, ok := filter.(*MatchAll); ok {
+		if matchAll.ClassId != 0 {
+			nl.NewRtAttrChild(options, nl.TCA_MATCHALL_CLASSID, nl.Uint32Attr(matchAll.ClassId))
+		}
+		if len(matchAll.Actions) > 0 {
+			actionsAttr := nl.NewRtAttrChild(options, nl.TCA_MATCHALL_ACT, nil)
+			if err := EncodeActions(actionsAttr, matchAll.Actions); err != nil {
+				return err
+			}
+		}
 	}
 
 	req.AddData(options)
 	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
-	return err
+	return tcKindError(err, "filter", filter.Type(), filterKinds)
+}
+
+// FilterKinds returns the filter kinds the kernel supports, from the
+// cls_ modules that are loaded, built in or installed. The kinds of
+// modules built in can only be found when the modules of the running
+// kernel are installed.
+func FilterKinds() ([]string, error) {
+	kinds, _, err := filterKinds()
+	return kinds, err
+}
+
+func filterKinds() ([]string, bool, error) {
+	return tcModuleKinds("cls_")
 }
 
 // FilterList gets a list of filters in the system.
@@ -277,6 +306,8 @@ func (h *Handle) FilterList(link Link, parent uint32) ([]Filter, error) {
 					filter = &Fw{}
 				case "bpf":
 					filter = &BpfFilter{}
+				case "matchall":
+					filter = &MatchAll{}
 				default:
 					filter = &GenericFilter{FilterType: filterType}
 				}
@@ -301,9 +332,17 @@ func (h *Handle) FilterList(link Link, parent uint32) ([]Filter, error) {
 					if err != nil {
 						return nil, err
 					}
+				case "matchall":
+					detailed, err = parseMatchAllData(filter, data)
+					if err != nil {
+						return nil, err
+					}
 				default:
 					detailed = true
 				}
+			case nl.TCA_CHAIN:
+				chain := native.Uint32(attr.Value[0:4])
+				base.Chain = &chain
 			}
 		}
 		// only return the detailed version of the filter
@@ -360,6 +399,65 @@ func EncodeActions(attr *nl.RtAttr, actions []Action) error {
 			nl.NewRtAttrChild(aopts, nl.TCA_ACT_BPF_PARMS, gen.Serialize())
 			nl.NewRtAttrChild(aopts, nl.TCA_ACT_BPF_FD, nl.Uint32Attr(uint32(action.Fd)))
 			nl.NewRtAttrChild(aopts, nl.TCA_ACT_BPF_NAME, nl.ZeroTerminated(action.Name))
+		case *SkbEditAction:
+			table := nl.NewRtAttrChild(attr, tabIndex, nil)
+			tabIndex++
+			nl.NewRtAttrChild(table, nl.TCA_ACT_KIND, nl.ZeroTerminated("skbedit"))
+			aopts := nl.NewRtAttrChild(table, nl.TCA_ACT_OPTIONS, nil)
+			gen := nl.TcGen{}
+			toTcGen(action.Attrs(), &gen)
+			nl.NewRtAttrChild(aopts, nl.TCA_SKBEDIT_PARMS, gen.Serialize())
+			if action.QueueMapping != nil {
+				nl.NewRtAttrChild(aopts, nl.TCA_SKBEDIT_QUEUE_MAPPING, nl.Uint16Attr(*action.QueueMapping))
+			}
+			if action.PType != nil {
+				nl.NewRtAttrChild(aopts, nl.TCA_SKBEDIT_PTYPE, nl.Uint16Attr(*action.PType))
+			}
+			if action.Priority != nil {
+				nl.NewRtAttrChild(aopts, nl.TCA_SKBEDIT_PRIORITY, nl.Uint32Attr(*action.Priority))
+			}
+			if action.Mark != nil {
+				nl.NewRtAttrChild(aopts, nl.TCA_SKBEDIT_MARK, nl.Uint32Attr(*action.Mark))
+			}
+			if action.Mask != nil {
+				nl.NewRtAttrChild(aopts, nl.TCA_SKBEDIT_MASK, nl.Uint32Attr(*action.Mask))
+			}
+		case *ConnmarkAction:
+			table := nl.NewRtAttrChild(attr, tabIndex, nil)
+			tabIndex++
+			nl.NewRtAttrChild(table, nl.TCA_ACT_KIND, nl.ZeroTerminated("connmark"))
+			aopts := nl.NewRtAttrChild(table, nl.TCA_ACT_OPTIONS, nil)
+			connmark := nl.TcConnmark{
+				Zone: action.Zone,
+			}
+			toTcGen(action.Attrs(), &connmark.TcGen)
+			nl.NewRtAttrChild(aopts, nl.TCA_CONNMARK_PARMS, connmark.Serialize())
+		case *VlanAction:
+			table := nl.NewRtAttrChild(attr, tabIndex, nil)
+			tabIndex++
+			nl.NewRtAttrChild(table, nl.TCA_ACT_KIND, nl.ZeroTerminated("vlan"))
+			aopts := nl.NewRtAttrChild(table, nl.TCA_ACT_OPTIONS, nil)
+			vlan := nl.TcVlan{
+				VAction: int32(action.VlanAction),
+			}
+			toTcGen(action.Attrs(), &vlan.TcGen)
+			nl.NewRtAttrChild(aopts, nl.TCA_VLAN_PARMS, vlan.Serialize())
+			if action.VlanAction != TCA_VLAN_ACT_POP {
+				nl.NewRtAttrChild(aopts, nl.TCA_VLAN_PUSH_VLAN_ID, nl.Uint16Attr(action.VlanId))
+				if action.Protocol != 0 {
+					nl.NewRtAttrChild(aopts, nl.TCA_VLAN_PUSH_VLAN_PROTOCOL, htons(action.Protocol))
+				}
+				nl.NewRtAttrChild(aopts, nl.TCA_VLAN_PUSH_VLAN_PRIORITY, nl.Uint8Attr(action.Priority))
+			}
+		case *GotoChainAction:
+			table := nl.NewRtAttrChild(attr, tabIndex, nil)
+			tabIndex++
+			nl.NewRtAttrChild(table, nl.TCA_ACT_KIND, nl.ZeroTerminated("gact"))
+			aopts := nl.NewRtAttrChild(table, nl.TCA_ACT_OPTIONS, nil)
+			gen := nl.TcGen{}
+			toTcGen(action.Attrs(), &gen)
+			gen.Action = int32(TC_ACT_GOTO_CHAIN | TcAct(action.ChainID&tcActExtValMask))
+			nl.NewRtAttrChild(aopts, nl.TCA_GACT_PARMS, gen.Serialize())
 		case *GenericAction:
 			table := nl.NewRtAttrChild(attr, tabIndex, nil)
 			tabIndex++
@@ -395,6 +493,12 @@ func parseActions(tables []syscall.NetlinkRouteAttr) ([]Action, error) {
 					action = &BpfAction{}
 				case "gact":
 					action = &GenericAction{}
+				case "skbedit":
+					action = &SkbEditAction{}
+				case "connmark":
+					action = &ConnmarkAction{}
+				case "vlan":
+					action = &VlanAction{}
 				default:
 					break nextattr
 				}
@@ -424,11 +528,58 @@ func parseActions(tables []syscall.NetlinkRouteAttr) ([]Action, error) {
 						case nl.TCA_ACT_BPF_NAME:
 							action.(*BpfAction).Name = string(adatum.Value[:len(adatum.Value)-1])
 						}
+					case "skbedit":
+						switch adatum.Attr.Type {
+						case nl.TCA_SKBEDIT_PARMS:
+							gen := *nl.DeserializeTcGen(adatum.Value)
+							toAttrs(&gen, action.Attrs())
+						case nl.TCA_SKBEDIT_QUEUE_MAPPING:
+							queueMapping := native.Uint16(adatum.Value[0:2])
+							action.(*SkbEditAction).QueueMapping = &queueMapping
+						case nl.TCA_SKBEDIT_PTYPE:
+							ptype := native.Uint16(adatum.Value[0:2])
+							action.(*SkbEditAction).PType = &ptype
+						case nl.TCA_SKBEDIT_PRIORITY:
+							priority := native.Uint32(adatum.Value[0:4])
+							action.(*SkbEditAction).Priority = &priority
+						case nl.TCA_SKBEDIT_MARK:
+							mark := native.Uint32(adatum.Value[0:4])
+							action.(*SkbEditAction).Mark = &mark
+						case nl.TCA_SKBEDIT_MASK:
+							mask := native.Uint32(adatum.Value[0:4])
+							action.(*SkbEditAction).Mask = &mask
+						}
+					case "connmark":
+						switch adatum.Attr.Type {
+						case nl.TCA_CONNMARK_PARMS:
+							connmark := *nl.DeserializeTcConnmark(adatum.Value)
+							toAttrs(&connmark.TcGen, action.Attrs())
+							action.(*ConnmarkAction).Zone = connmark.Zone
+						}
+					case "vlan":
+						switch adatum.Attr.Type {
+						case nl.TCA_VLAN_PARMS:
+							vlan := *nl.DeserializeTcVlan(adatum.Value)
+							toAttrs(&vlan.TcGen, action.Attrs())
+							action.(*VlanAction).VlanAction = VlanAct(vlan.VAction)
+						case nl.TCA_VLAN_PUSH_VLAN_ID:
+							action.(*VlanAction).VlanId = native.Uint16(adatum.Value[0:2])
+						case nl.TCA_VLAN_PUSH_VLAN_PROTOCOL:
+							action.(*VlanAction).Protocol = ntohs(adatum.Value[0:2])
+						case nl.TCA_VLAN_PUSH_VLAN_PRIORITY:
+							action.(*VlanAction).Priority = adatum.Value[0]
+						}
 					case "gact":
 						switch adatum.Attr.Type {
 						case nl.TCA_GACT_PARMS:
 							gen := *nl.DeserializeTcGen(adatum.Value)
 							toAttrs(&gen, action.Attrs())
+							if act := action.Attrs().Action; act&^tcActExtValMask == TC_ACT_GOTO_CHAIN {
+								action = &GotoChainAction{
+									GenericAction: *action.(*GenericAction),
+									ChainID:       uint32(act & tcActExtValMask),
+								}
+							}
 						}
 					}
 				}
@@ -530,6 +681,28 @@ func parseBpfData(filter Filter, data []syscall.NetlinkRouteAttr) (bool, error)
 	return detailed, nil
 }
 
+func parseMatchAllData(filter Filter, data []syscall.NetlinkRouteAttr) (bool, error) {
+	native = nl.NativeEndian()
+	matchAll := filter.(*MatchAll)
+	detailed := true
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_MATCHALL_CLASSID:
+			matchAll.ClassId = native.Uint32(datum.Value[0:4])
+		case nl.TCA_MATCHALL_ACT:
+			tables, err := nl.ParseRouteAttr(datum.Value)
+			if err != nil {
+				return detailed, err
+			}
+			matchAll.Actions, err = parseActions(tables)
+			if err != nil {
+				return detailed, err
+			}
+		}
+	}
+	return detailed, nil
+}
+
 func AlignToAtm(size uint) uint {
 	var linksize, cells int
 	cells = int(size / nl.ATM_CELL_PAYLOAD)
@@ -102,6 +102,112 @@ func TestFilterAddDel(t *testing.T) {
 	}
 }
 
+func TestFilterChainAddDel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &Ingress{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(0xffff, 0),
+			Parent:    HANDLE_INGRESS,
+		},
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	chain0, chain1 := uint32(0), uint32(1)
+	entry := &U32{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    MakeHandle(0xffff, 0),
+			Priority:  1,
+			Protocol:  syscall.ETH_P_IP,
+			Chain:     &chain0,
+		},
+		Actions: []Action{NewGotoChainAction(chain1)},
+	}
+	// u32 hash tables are shared by all the chains of a qdisc and only
+	// told apart by priority, keep the priorities distinct.
+	drop := &U32{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    MakeHandle(0xffff, 0),
+			Priority:  2,
+			Protocol:  syscall.ETH_P_IP,
+			Chain:     &chain1,
+		},
+		Actions: []Action{&GenericAction{ActionAttrs{Action: TC_ACT_SHOT}}},
+	}
+	if err := FilterAdd(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := FilterAdd(drop); err != nil {
+		t.Fatal(err)
+	}
+
+	filters, err := FilterList(link, MakeHandle(0xffff, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 2 {
+		t.Fatalf("Expected 2 filters, got %d", len(filters))
+	}
+	for _, filter := range filters {
+		chain := filter.Attrs().Chain
+		if chain == nil {
+			t.Fatal("Filter chain was not decoded")
+		}
+		u32, ok := filter.(*U32)
+		if !ok {
+			t.Fatal("Filter is the wrong type")
+		}
+		if len(u32.Actions) != 1 {
+			t.Fatalf("Expected 1 action in chain %d, got %d", *chain, len(u32.Actions))
+		}
+		switch *chain {
+		case chain0:
+			goTo, ok := u32.Actions[0].(*GotoChainAction)
+			if !ok {
+				t.Fatal("Action in chain 0 is the wrong type")
+			}
+			if goTo.ChainID != chain1 {
+				t.Fatalf("Goto chain is %d, should be %d", goTo.ChainID, chain1)
+			}
+		case chain1:
+			if u32.Actions[0].Attrs().Action != TC_ACT_SHOT {
+				t.Fatal("Action in chain 1 is the wrong value")
+			}
+		default:
+			t.Fatalf("Unexpected filter chain %d", *chain)
+		}
+	}
+
+	if err := FilterDel(drop); err != nil {
+		t.Fatal(err)
+	}
+	if err := FilterDel(entry); err != nil {
+		t.Fatal(err)
+	}
+	filters, err = FilterList(link, MakeHandle(0xffff, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 0 {
+		t.Fatal("Failed to remove filters")
+	}
+}
+
 func TestAdvancedFilterAddDel(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -656,3 +762,176 @@ func TestFilterClsActBpfAddDel(t *testing.T) {
 		t.Fatal("Failed to remove qdisc")
 	}
 }
+
+func TestFilterMatchAllSkbEditConnmark(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &Ingress{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(0xffff, 0),
+			Parent:    HANDLE_INGRESS,
+		},
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	mark := uint32(0x10)
+	mask := uint32(0xff)
+	priority := uint32(MakeHandle(1, 2))
+	skbedit := NewSkbEditAction()
+	skbedit.Mark = &mark
+	skbedit.Mask = &mask
+	skbedit.Priority = &priority
+	connmark := NewConnmarkAction()
+	connmark.Zone = 3
+	filter := &MatchAll{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    MakeHandle(0xffff, 0),
+			Priority:  1,
+			Protocol:  syscall.ETH_P_ALL,
+		},
+		Actions: []Action{connmark, skbedit},
+	}
+	if err := FilterAdd(filter); err != nil {
+		t.Fatal(err)
+	}
+
+	filters, err := FilterList(link, MakeHandle(0xffff, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatal("Failed to add filter")
+	}
+	matchAll, ok := filters[0].(*MatchAll)
+	if !ok {
+		t.Fatal("Filter is the wrong type")
+	}
+	if len(matchAll.Actions) != 2 {
+		t.Fatalf("Filter has %d actions, expected 2", len(matchAll.Actions))
+	}
+	connmarkAction, ok := matchAll.Actions[0].(*ConnmarkAction)
+	if !ok {
+		t.Fatal("First action is the wrong type")
+	}
+	if connmarkAction.Zone != connmark.Zone {
+		t.Fatalf("Connmark zone is %d, expected %d", connmarkAction.Zone, connmark.Zone)
+	}
+	if connmarkAction.Attrs().Action != TC_ACT_PIPE {
+		t.Fatal("Connmark action isn't TC_ACT_PIPE")
+	}
+	skbeditAction, ok := matchAll.Actions[1].(*SkbEditAction)
+	if !ok {
+		t.Fatal("Second action is the wrong type")
+	}
+	if skbeditAction.Mark == nil || *skbeditAction.Mark != mark {
+		t.Fatal("Skbedit mark does not match")
+	}
+	if skbeditAction.Mask == nil || *skbeditAction.Mask != mask {
+		t.Fatal("Skbedit mask does not match")
+	}
+	if skbeditAction.Priority == nil || *skbeditAction.Priority != priority {
+		t.Fatal("Skbedit priority does not match")
+	}
+	if skbeditAction.QueueMapping != nil || skbeditAction.PType != nil {
+		t.Fatal("Skbedit has unset fields")
+	}
+
+	if err := FilterDel(filter); err != nil {
+		t.Fatal(err)
+	}
+	filters, err = FilterList(link, MakeHandle(0xffff, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 0 {
+		t.Fatal("Failed to remove filter")
+	}
+}
+
+func TestFilterMatchAllVlanPush(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &Ingress{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(0xffff, 0),
+			Parent:    HANDLE_INGRESS,
+		},
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	vlan := NewVlanPushAction(100)
+	vlan.Protocol = 0x88a8 // ETH_P_8021AD
+	vlan.Priority = 3
+	filter := &MatchAll{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    MakeHandle(0xffff, 0),
+			Priority:  1,
+			Protocol:  syscall.ETH_P_ALL,
+		},
+		Actions: []Action{vlan},
+	}
+	if err := FilterAdd(filter); err != nil {
+		t.Fatal(err)
+	}
+
+	filters, err := FilterList(link, MakeHandle(0xffff, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatal("Failed to add filter")
+	}
+	matchAll, ok := filters[0].(*MatchAll)
+	if !ok {
+		t.Fatal("Filter is the wrong type")
+	}
+	if len(matchAll.Actions) != 1 {
+		t.Fatalf("Filter has %d actions, expected 1", len(matchAll.Actions))
+	}
+	vlanAction, ok := matchAll.Actions[0].(*VlanAction)
+	if !ok {
+		t.Fatal("Action is the wrong type")
+	}
+	if vlanAction.VlanAction != TCA_VLAN_ACT_PUSH {
+		t.Fatalf("Vlan action is %s, expected push", vlanAction.VlanAction)
+	}
+	if vlanAction.VlanId != vlan.VlanId || vlanAction.Protocol != vlan.Protocol {
+		t.Fatalf("Vlan action pushes %d/0x%x, expected %d/0x%x",
+			vlanAction.VlanId, vlanAction.Protocol, vlan.VlanId, vlan.Protocol)
+	}
+	if vlanAction.Priority != vlan.Priority {
+		t.Fatalf("Vlan priority is %d, expected %d", vlanAction.Priority, vlan.Priority)
+	}
+
+	if err := FilterDel(filter); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -165,3 +165,94 @@ func (h *Handle) GenlFamilyGet(name string) (*GenlFamily, error) {
 func GenlFamilyGet(name string) (*GenlFamily, error) {
 	return pkgHandle.GenlFamilyGet(name)
 }
+
+// GenlMessage is a generic netlink message received from a multicast group.
+type GenlMessage struct {
+	Command uint8
+	Version uint8
+	Attrs   []syscall.NetlinkRouteAttr
+}
+
+// GenlSubscribe takes a chan down which the messages sent by the generic
+// netlink family familyName to its multicast group groupName will be sent.
+// Close the 'done' chan to stop subscription.
+func GenlSubscribe(familyName, groupName string, ch chan<- GenlMessage, done <-chan struct{}) error {
+	return pkgHandle.GenlSubscribe(familyName, groupName, ch, done)
+}
+
+// GenlSubscribe takes a chan down which the messages sent by the generic
+// netlink family familyName to its multicast group groupName will be sent.
+// Close the 'done' chan to stop subscription.
+func (h *Handle) GenlSubscribe(familyName, groupName string, ch chan<- GenlMessage, done <-chan struct{}) error {
+	family, err := h.GenlFamilyGet(familyName)
+	if err != nil {
+		return err
+	}
+	var group *GenlMulticastGroup
+	for i := range family.Groups {
+		if family.Groups[i].Name == groupName {
+			group = &family.Groups[i]
+			break
+		}
+	}
+	if group == nil {
+		return fmt.Errorf("family %s has no multicast group %s", familyName, groupName)
+	}
+	s, err := h.subscribe(done, syscall.NETLINK_GENERIC)
+	if err != nil {
+		return err
+	}
+	if err := s.JoinGroup(uint(group.ID)); err != nil {
+		s.Close()
+		return err
+	}
+	go func() {
+		defer close(ch)
+		for {
+			msgs, err := s.Receive()
+			if err != nil {
+				return
+			}
+			for _, m := range msgs {
+				if m.Header.Type != family.ID || len(m.Data) < nl.SizeofGenlmsg+int(family.HdrSize) {
+					continue
+				}
+				msg := nl.DeserializeGenlmsg(m.Data)
+				attrs, err := nl.ParseRouteAttr(m.Data[nl.SizeofGenlmsg+int(family.HdrSize):])
+				if err != nil {
+					continue
+				}
+				ch <- GenlMessage{Command: msg.Command, Version: msg.Version, Attrs: attrs}
+			}
+		}
+	}()
+	return nil
+}
+
+// GenlExecute sends the command cmd with the attributes attrs to the
+// generic netlink family familyName and returns the messages of the
+// response, each starting with its genlmsghdr. flags are added to
+// NLM_F_REQUEST, commands which do not reply need NLM_F_ACK.
+func GenlExecute(familyName string, cmd uint8, version uint8, flags int, attrs []*nl.RtAttr) ([][]byte, error) {
+	return pkgHandle.GenlExecute(familyName, cmd, version, flags, attrs)
+}
+
+// GenlExecute sends the command cmd with the attributes attrs to the
+// generic netlink family familyName and returns the messages of the
+// response, each starting with its genlmsghdr. flags are added to
+// NLM_F_REQUEST, commands which do not reply need NLM_F_ACK.
+func (h *Handle) GenlExecute(familyName string, cmd uint8, version uint8, flags int, attrs []*nl.RtAttr) ([][]byte, error) {
+	family, err := h.GenlFamilyGet(familyName)
+	if err != nil {
+		return nil, err
+	}
+	req := h.newNetlinkRequest(int(family.ID), flags)
+	req.AddData(&nl.Genlmsg{
+		Command: cmd,
+		Version: version,
+	})
+	for _, attr := range attrs {
+		req.AddData(attr)
+	}
+	return req.Execute(syscall.NETLINK_GENERIC, 0)
+}
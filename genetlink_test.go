@@ -0,0 +1,54 @@
+// +build linux
+
+package netlink
+
+import (
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+)
+
+func TestGenlSubscribe(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	if err := GenlSubscribe("nosuchfamily", "notify", make(chan GenlMessage), nil); err == nil {
+		t.Fatal("Subscribing to an unknown family should fail")
+	}
+	if err := GenlSubscribe("nlctrl", "nosuchgroup", make(chan GenlMessage), nil); err == nil {
+		t.Fatal("Subscribing to an unknown group should fail")
+	}
+
+	// nlctrl only notifies when families are registered, so the
+	// subscription cannot be fed from here
+	done := make(chan struct{})
+	defer close(done)
+	if err := GenlSubscribe("nlctrl", "notify", make(chan GenlMessage), done); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenlExecute(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	msgs, err := GenlExecute(nl.GENL_CTRL_NAME, nl.GENL_CTRL_CMD_GETFAMILY, nl.GENL_CTRL_VERSION, 0,
+		[]*nl.RtAttr{nl.NewRtAttr(nl.GENL_CTRL_ATTR_FAMILY_NAME, nl.ZeroTerminated(nl.GENL_CTRL_NAME))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("Got %d messages, expected 1", len(msgs))
+	}
+	attrs, err := nl.ParseRouteAttr(msgs[0][nl.SizeofGenlmsg:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	family := &GenlFamily{}
+	if err := family.parseAttributes(attrs); err != nil {
+		t.Fatal(err)
+	}
+	if family.Name != nl.GENL_CTRL_NAME || family.ID != nl.GENL_ID_CTRL {
+		t.Fatalf("Got family %s with id %d, expected %s with id %d", family.Name, family.ID, nl.GENL_CTRL_NAME, nl.GENL_ID_CTRL)
+	}
+}
@@ -8,6 +8,8 @@ type GenlMulticastGroup struct{}
 
 type GenlFamily struct{}
 
+type GenlMessage struct{}
+
 func (h *Handle) GenlFamilyList() ([]*GenlFamily, error) {
 	return nil, ErrNotImplemented
 }
@@ -23,3 +25,11 @@ func (h *Handle) GenlFamilyGet(name string) (*GenlFamily, error) {
 func GenlFamilyGet(name string) (*GenlFamily, error) {
 	return nil, ErrNotImplemented
 }
+
+func (h *Handle) GenlSubscribe(familyName, groupName string, ch chan<- GenlMessage, done <-chan struct{}) error {
+	return ErrNotImplemented
+}
+
+func GenlSubscribe(familyName, groupName string, ch chan<- GenlMessage, done <-chan struct{}) error {
+	return ErrNotImplemented
+}
@@ -2,6 +2,7 @@ package netlink
 
 import (
 	"fmt"
+	"sync"
 	"syscall"
 	"time"
 
@@ -16,9 +17,18 @@ var pkgHandle = &Handle{}
 // specific network namespace. All the requests on the
 // same netlink family share the same netlink socket,
 // which gets released when the handle is deleted.
+//
+// Subscriptions started through a handle use their own
+// sockets, opened in the handle's network namespace. They
+// stay active until their done channel is closed or the
+// handle is closed, whichever happens first.
 type Handle struct {
 	sockets      map[int]*nl.SocketHandle
 	lookupByDump bool
+	strictCheck  bool
+	ns           netns.NsHandle // namespace subscriptions are opened in
+	mu           sync.Mutex
+	closed       chan struct{} // closed by Close to stop subscriptions
 }
 
 // SupportsNetlinkFamily reports whether the passed netlink family is supported by this Handle
@@ -58,9 +68,49 @@ func (h *Handle) SetSocketTimeout(to time.Duration) error {
 	return nil
 }
 
-// NewHandle returns a netlink handle on the network namespace
+// SetStrictCheck enables or disables strict checking of the dump requests
+// sent on the handle's NETLINK_ROUTE socket (NETLINK_GET_STRICT_CHK).
+// With strict checking on, the kernel honors the filter attributes of
+// dump requests, so the filtered list functions only receive matching
+// entries. On kernels without support for the option the request is
+// ignored and filtering keeps being done in user space.
+func (h *Handle) SetStrictCheck(enable bool) error {
+	sh, ok := h.sockets[syscall.NETLINK_ROUTE]
+	if !ok {
+		return fmt.Errorf("handle has no NETLINK_ROUTE socket")
+	}
+	var v int
+	if enable {
+		v = 1
+	}
+	err := syscall.SetsockoptInt(sh.Socket.GetFd(), nl.SOL_NETLINK, nl.NETLINK_GET_STRICT_CHK, v)
+	if err != nil {
+		if err == syscall.ENOPROTOOPT {
+			h.strictCheck = false
+			return nil
+		}
+		return err
+	}
+	h.strictCheck = enable
+	return nil
+}
+
+// SetErrorMessageReporting enables or disables extended acks on the
+// handle's sockets. When enabled, failed requests return an
+// nl.ErrorMessage holding the kernel's explanation of the error, if any.
+func (h *Handle) SetErrorMessageReporting(enable bool) error {
+	for _, sh := range h.sockets {
+		if err := sh.Socket.SetExtAck(enable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewHandleAt returns a netlink handle on the network namespace
 // specified by ns. If ns=netns.None(), current network namespace
-// will be assumed
+// will be assumed. The sockets of the handle are created once inside
+// ns and reused by all its calls, which then never switch namespace.
 func NewHandleAt(ns netns.NsHandle, nlFamilies ...int) (*Handle, error) {
 	return newHandle(ns, netns.None(), nlFamilies...)
 }
@@ -72,7 +122,7 @@ func NewHandleAtFrom(newNs, curNs netns.NsHandle) (*Handle, error) {
 }
 
 func newHandle(newNs, curNs netns.NsHandle, nlFamilies ...int) (*Handle, error) {
-	h := &Handle{sockets: map[int]*nl.SocketHandle{}}
+	h := &Handle{sockets: map[int]*nl.SocketHandle{}, ns: netns.None(), closed: make(chan struct{})}
 	fams := nl.SupportedNlFamilies
 	if len(nlFamilies) != 0 {
 		fams = nlFamilies
@@ -84,6 +134,15 @@ func newHandle(newNs, curNs netns.NsHandle, nlFamilies ...int) (*Handle, error)
 		}
 		h.sockets[f] = &nl.SocketHandle{Socket: s}
 	}
+	if newNs.IsOpen() {
+		// keep our own reference, the caller may close theirs
+		fd, err := syscall.Dup(int(newNs))
+		if err != nil {
+			h.Delete()
+			return nil, err
+		}
+		h.ns = netns.NsHandle(fd)
+	}
 	return h, nil
 }
 
@@ -93,6 +152,75 @@ func (h *Handle) Delete() {
 		sh.Close()
 	}
 	h.sockets = nil
+	// the package handle has no namespace of its own
+	if h.closed != nil && h.ns.IsOpen() {
+		h.ns.Close()
+	}
+}
+
+// Close releases the resources allocated to this handle, like Delete,
+// and also stops all the subscriptions started through it. Their update
+// channels are closed once the subscription goroutines have exited, which
+// happens when a receive already blocked on the socket returns.
+func (h *Handle) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed != nil {
+		select {
+		case <-h.closed:
+		default:
+			close(h.closed)
+		}
+	}
+	h.Delete()
+}
+
+// subscribe opens a netlink socket bound to the given multicast groups
+// in the handle's network namespace. The socket is closed when either
+// done or the handle is closed.
+func (h *Handle) subscribe(done <-chan struct{}, protocol int, groups ...uint) (*nl.NetlinkSocket, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed == nil {
+		// package handle, subscribe in the current namespace
+		s, err := nl.Subscribe(protocol, groups...)
+		if err != nil {
+			return nil, err
+		}
+		if done != nil {
+			go func() {
+				<-done
+				s.Close()
+			}()
+		}
+		return s, nil
+	}
+	select {
+	case <-h.closed:
+		return nil, fmt.Errorf("subscribe called on a closed handle")
+	default:
+	}
+	s, err := nl.SubscribeAt(h.ns, netns.None(), protocol, groups...)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		select {
+		case <-done:
+		case <-h.closed:
+		}
+		s.Close()
+	}()
+	return s, nil
+}
+
+// NewNetlinkRequest returns a raw request with the message type msgType,
+// to be filled by the caller and executed with the netlink family it is
+// for, e.g. req.Execute(syscall.NETLINK_GENERIC, 0). It is sent on the
+// socket the handle has for that family, or on a socket of its own in
+// the current network namespace if the handle has none.
+func (h *Handle) NewNetlinkRequest(msgType, flags int) *nl.NetlinkRequest {
+	return h.newNetlinkRequest(msgType, flags)
 }
 
 func (h *Handle) newNetlinkRequest(proto, flags int) *nl.NetlinkRequest {
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -40,6 +41,29 @@ func TestHandleCreateDelete(t *testing.T) {
 	}
 }
 
+func TestHandleAtDeleteNs(t *testing.T) {
+	skipUnlessRoot(t)
+
+	ns, err := netns.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ns.Close()
+	h, err := NewHandleAt(ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.ns.IsOpen() {
+		t.Fatal("Handle has no namespace of its own")
+	}
+	h.Delete()
+	if h.ns.IsOpen() {
+		t.Fatalf("Handle namespace %s was not closed", h.ns)
+	}
+	// closing the handle afterwards must not close anything else
+	h.Close()
+}
+
 func TestHandleCreateNetns(t *testing.T) {
 	skipUnlessRoot(t)
 
@@ -132,6 +156,109 @@ func TestHandleTimeout(t *testing.T) {
 	}
 }
 
+func TestHandleCloseSubscriptions(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	h, err := NewHandle()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan LinkUpdate)
+	if err := h.LinkSubscribe(ch, nil); err != nil {
+		t.Fatal(err)
+	}
+	h.Close()
+
+	if err := h.LinkSubscribe(make(chan LinkUpdate), nil); err == nil {
+		t.Fatal("Subscribe on a closed handle should fail")
+	}
+
+	// A receive already in progress only returns on the next message,
+	// keep generating events until the subscription goroutine notices
+	// the close.
+	link := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(link); err != nil {
+		t.Fatal(err)
+	}
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(time.Minute)
+	up := false
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-ticker.C:
+			if up {
+				err = LinkSetDown(link)
+			} else {
+				err = LinkSetUp(link)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			up = !up
+		case <-timeout:
+			t.Fatal("Subscription goroutine did not exit after handle was closed")
+		}
+	}
+}
+
+func TestHandleErrorMessageReporting(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	h, err := NewHandle(syscall.NETLINK_ROUTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Delete()
+	if err := h.SetErrorMessageReporting(true); err != nil {
+		t.Skipf("Kernel does not support extended acks: %v", err)
+	}
+
+	lo, err := h.LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.LinkSetUp(lo); err != nil {
+		t.Fatal(err)
+	}
+
+	// the gateway is not reachable through lo
+	route := &Route{
+		LinkIndex: lo.Attrs().Index,
+		Dst:       &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+		Gw:        net.IPv4(192, 168, 99, 1),
+	}
+	err = h.RouteAdd(route)
+	if err == nil {
+		t.Fatal("Route with an unreachable gateway should fail")
+	}
+	msg, ok := err.(nl.ErrorMessage)
+	if !ok {
+		t.Fatalf("Expected an error message, got %v", err)
+	}
+	if msg.Message != "Nexthop has invalid gateway" {
+		t.Fatalf("Unexpected error message %q", msg.Message)
+	}
+	if msg.Errno != syscall.ENETUNREACH {
+		t.Fatalf("Unexpected error number %v", msg.Errno)
+	}
+
+	// errors stay bare numbers without extended acks
+	if err := h.SetErrorMessageReporting(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.RouteAdd(route); err != syscall.ENETUNREACH {
+		t.Fatalf("Expected ENETUNREACH, got %v", err)
+	}
+}
+
 func verifySockTimeVal(t *testing.T, fd int, tv syscall.Timeval) {
 	var (
 		tr syscall.Timeval
@@ -341,3 +468,146 @@ func TestHandleParallel3(t *testing.T) {
 func TestHandleParallel4(t *testing.T) {
 	runParallelTests(t, 4)
 }
+
+// BenchmarkHandleAtLinkList compares listing the links of another network
+// namespace by entering it for each call, by opening a handle on it for
+// each call, and through a single handle kept open on it.
+func BenchmarkHandleAtLinkList(b *testing.B) {
+	tearDown := setUpNetlinkTest(b)
+	defer tearDown()
+
+	baseNs, err := netns.Get()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer baseNs.Close()
+	newNs, err := netns.New()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer newNs.Close()
+	if err := netns.Set(baseNs); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("SetnsPerCall", func(b *testing.B) {
+		// sub-benchmarks run in their own goroutine, keep it on one
+		// thread and leave that thread in the namespace it came with
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		origNs, err := netns.Get()
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer origNs.Close()
+		defer netns.Set(origNs)
+
+		for i := 0; i < b.N; i++ {
+			if err := netns.Set(newNs); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := LinkList(); err != nil {
+				b.Fatal(err)
+			}
+			if err := netns.Set(origNs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("HandleAtPerCall", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			h, err := NewHandleAt(newNs, syscall.NETLINK_ROUTE)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := h.LinkList(); err != nil {
+				b.Fatal(err)
+			}
+			h.Delete()
+		}
+	})
+	b.Run("PersistentHandle", func(b *testing.B) {
+		h, err := NewHandleAt(newNs, syscall.NETLINK_ROUTE)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer h.Delete()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := h.LinkList(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestHandleRawGenericRequest(t *testing.T) {
+	skipUnlessRoot(t)
+	h, err := NewHandle(syscall.NETLINK_GENERIC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Delete()
+
+	// the package handle has no sockets, the request opens its own
+	for _, handle := range []*Handle{pkgHandle, h} {
+		req := handle.NewNetlinkRequest(nl.GENL_ID_CTRL, 0)
+		req.AddData(&nl.Genlmsg{
+			Command: nl.GENL_CTRL_CMD_GETFAMILY,
+			Version: nl.GENL_CTRL_VERSION,
+		})
+		req.AddData(nl.NewRtAttr(nl.GENL_CTRL_ATTR_FAMILY_NAME, nl.ZeroTerminated(nl.GENL_CTRL_NAME)))
+		msgs, err := req.Execute(syscall.NETLINK_GENERIC, nl.GENL_ID_CTRL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		families, err := parseFamilies(msgs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(families) != 1 || families[0].Name != nl.GENL_CTRL_NAME || families[0].ID != nl.GENL_ID_CTRL {
+			t.Fatalf("Unexpected families %v", families)
+		}
+	}
+}
+
+func TestHandleStrictCheckLists(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	lo, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(lo); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := NewHandle(syscall.NETLINK_ROUTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Delete()
+	if err := h.SetStrictCheck(true); err != nil {
+		t.Fatal(err)
+	}
+	if !h.strictCheck {
+		t.Skip("Kernel does not support NETLINK_GET_STRICT_CHK")
+	}
+
+	addrs, err := h.AddrList(lo, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 1 || !addrs[0].IP.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Fatalf("Expected only 127.0.0.1 on lo, got %v", addrs)
+	}
+	rules, err := h.RuleList(FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// local, main and default
+	if len(rules) != 3 {
+		t.Fatalf("Expected the 3 default rules, got %v", rules)
+	}
+}
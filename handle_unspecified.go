@@ -25,6 +25,16 @@ func NewHandleAtFrom(newNs, curNs netns.NsHandle) (*Handle, error) {
 
 func (h *Handle) Delete() {}
 
+func (h *Handle) Close() {}
+
+func (h *Handle) SetStrictCheck(enable bool) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) SetErrorMessageReporting(enable bool) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) SupportsNetlinkFamily(nlFamily int) bool {
 	return false
 }
@@ -41,6 +51,14 @@ func (h *Handle) SetPromiscOff(link Link) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkSetAllmulticastOn(link Link) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkSetAllmulticastOff(link Link) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) LinkSetUp(link Link) error {
 	return ErrNotImplemented
 }
@@ -57,6 +75,10 @@ func (h *Handle) LinkSetName(link Link, name string) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkSwapNames(a, b Link) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) LinkSetAlias(link Link, name string) error {
 	return ErrNotImplemented
 }
@@ -77,6 +99,10 @@ func (h *Handle) LinkSetVfTxRate(link Link, vf, rate int) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkSetVfConfig(link Link, vf int, cfg VfConfig) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) LinkSetMaster(link Link, master *Bridge) error {
 	return ErrNotImplemented
 }
@@ -89,6 +115,18 @@ func (h *Handle) LinkSetMasterByIndex(link Link, masterIndex int) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkGetVrfTable(link Link) (uint32, bool) {
+	return 0, false
+}
+
+func (h *Handle) LinkStatsSnapshot(link Link) (*LinkStatistics64, error) {
+	return nil, ErrNotImplemented
+}
+
+func (h *Handle) LinkStatsSince(link Link, snapshot *LinkStatistics64) (LinkStatistics64, error) {
+	return LinkStatistics64{}, ErrNotImplemented
+}
+
 func (h *Handle) LinkSetNsPid(link Link, nspid int) error {
 	return ErrNotImplemented
 }
@@ -101,6 +139,10 @@ func (h *Handle) LinkAdd(link Link) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkModify(desired Link) ([]string, error) {
+	return nil, ErrNotImplemented
+}
+
 func (h *Handle) LinkDel(link Link) error {
 	return ErrNotImplemented
 }
@@ -121,6 +163,10 @@ func (h *Handle) LinkList() ([]Link, error) {
 	return nil, ErrNotImplemented
 }
 
+func (h *Handle) LinkListWithOptions(options *LinkListOptions) ([]Link, error) {
+	return nil, ErrNotImplemented
+}
+
 func (h *Handle) LinkSetHairpin(link Link, mode bool) error {
 	return ErrNotImplemented
 }
@@ -145,10 +191,34 @@ func (h *Handle) LinkSetFlood(link Link, mode bool) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkSetMcastFlood(link Link, mode bool) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkSetBrMcastRouter(link Link, mode uint8) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) LinkSetTxQLen(link Link, qlen int) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkSetIP6AddrGenToken(link Link, token net.IP) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkSetIP6AddrGenMode(link Link, mode IP6AddrGenMode) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkSetGroup(link Link, group uint32) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkSetGroupState(group uint32, up bool) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) setProtinfoAttr(link Link, mode bool, attr int) error {
 	return ErrNotImplemented
 }
@@ -161,6 +231,10 @@ func (h *Handle) AddrDel(link Link, addr *Addr) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) AddrReplaceAtomic(link Link, remove, add *Addr) (removeErr, addErr error) {
+	return ErrNotImplemented, ErrNotImplemented
+}
+
 func (h *Handle) AddrList(link Link, family int) ([]Addr, error) {
 	return nil, ErrNotImplemented
 }
@@ -217,6 +291,10 @@ func (h *Handle) NeighList(linkIndex, family int) ([]Neigh, error) {
 	return nil, ErrNotImplemented
 }
 
+func (h *Handle) NeighGet(linkIndex int, ip net.IP) (*Neigh, error) {
+	return nil, ErrNotImplemented
+}
+
 func (h *Handle) NeighProxyList(linkIndex, family int) ([]Neigh, error) {
 	return nil, ErrNotImplemented
 }
@@ -1,8 +1,10 @@
 package netlink
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"time"
 )
 
 // Link represents a link device from netlink. Shared link attributes
@@ -18,25 +20,67 @@ type (
 	NsFd  int
 )
 
+// VfConfig bundles the settings of a VF applied by LinkSetVfConfig.
+// Only the fields that are set (non-zero or non-nil) are sent.
+type VfConfig struct {
+	Mac       net.HardwareAddr
+	Vlan      int
+	Qos       int
+	VlanProto int // syscall.ETH_P_8021Q or ETH_P_8021AD, zero for the kernel default
+	MinTxRate int // Mbps
+	MaxTxRate int // Mbps
+	Spoofchk  *bool
+	Trust     *bool
+	LinkState *uint32 // nl.IFLA_VF_LINK_STATE_*
+	RssQuery  *bool
+}
+
+// LinkListOptions contains a set of options to use with
+// LinkListWithOptions
+type LinkListOptions struct {
+	// ExtMask is a combination of nl.RTEXT_FILTER_* flags, sent to
+	// the kernel as IFLA_EXT_MASK, e.g. nl.RTEXT_FILTER_VF to include
+	// VF info or nl.RTEXT_FILTER_SKIP_STATS to leave out the VF and
+	// per address family statistics.
+	ExtMask uint32
+}
+
 // LinkAttrs represents data shared by most link types
 type LinkAttrs struct {
-	Index        int
-	MTU          int
-	TxQLen       int // Transmit Queue Length
-	Name         string
-	HardwareAddr net.HardwareAddr
-	Flags        net.Flags
-	RawFlags     uint32
-	ParentIndex  int         // index of the parent link device
-	MasterIndex  int         // must be the index of a bridge
-	Namespace    interface{} // nil | NsPid | NsFd
-	Alias        string
-	Statistics   *LinkStatistics
-	Promisc      int
-	Xdp          *LinkXdp
-	EncapType    string
-	Protinfo     *Protinfo
-	OperState    LinkOperState
+	Index            int
+	MTU              int
+	MinMTU           int // zero if not reported by the kernel
+	MaxMTU           int // zero if not reported by the kernel
+	TxQLen           int // Transmit Queue Length
+	Name             string
+	HardwareAddr     net.HardwareAddr
+	Flags            net.Flags
+	RawFlags         uint32
+	ParentIndex      int         // index of the parent link device
+	MasterIndex      int         // must be the index of a bridge
+	Namespace        interface{} // nil | NsPid | NsFd
+	Alias            string
+	Group            uint32 // zero is the default group
+	Statistics       *LinkStatistics
+	Promisc          int
+	Promiscuity      int    // number of promisc references held on the link
+	Allmulti         int    // number of allmulti references, zero if not reported
+	CarrierChanges   uint32 // zero if not reported by the kernel
+	CarrierUpCount   uint32 // zero if not reported by the kernel
+	CarrierDownCount uint32 // zero if not reported by the kernel
+	Xdp              *LinkXdp
+	EncapType        string
+	Protinfo         *Protinfo
+	OperState        LinkOperState
+	LinkMode         LinkMode
+	IP6AddrGenMode   IP6AddrGenMode
+	IP6AddrGenToken  net.IP    // interface identifier used for SLAAC
+	Threaded         bool      // only filled by LinkGetThreaded and LinkSetThreaded
+	PhysPortName     string    // empty if not reported, read only
+	PhysSwitchID     []byte    // nil if not reported, read only
+	ParentDevName    string    // e.g. the pci address, empty for virtual links, read only
+	ParentDevBusName string    // e.g. pci, empty for virtual links, read only
+	Slave            LinkSlave // nil if the link has no master, read only
 }
 
 // LinkOperState represents the values of the IFLA_OPERSTATE link
@@ -72,6 +116,54 @@ func (s LinkOperState) String() string {
 	}
 }
 
+// LinkMode represents the values of the IFLA_LINKMODE link attribute. In
+// dormant mode the link stays dormant until userspace, e.g. an 802.1X
+// supplicant, sets its operational state.
+type LinkMode uint8
+
+const (
+	LinkModeDefault LinkMode = iota
+	LinkModeDormant
+)
+
+func (m LinkMode) String() string {
+	switch m {
+	case LinkModeDefault:
+		return "default"
+	case LinkModeDormant:
+		return "dormant"
+	default:
+		return "unknown"
+	}
+}
+
+// IP6AddrGenMode represents the values of the IFLA_INET6_ADDR_GEN_MODE
+// attribute, which selects how IPv6 link-local and SLAAC addresses
+// are generated.
+type IP6AddrGenMode uint8
+
+const (
+	IP6AddrGenModeEUI64         IP6AddrGenMode = iota // Derived from the hardware address.
+	IP6AddrGenModeNone                                // No link-local address is generated.
+	IP6AddrGenModeStablePrivacy                       // RFC7217 stable addresses.
+	IP6AddrGenModeRandom                              // Stable addresses with a random secret.
+)
+
+func (m IP6AddrGenMode) String() string {
+	switch m {
+	case IP6AddrGenModeEUI64:
+		return "eui64"
+	case IP6AddrGenModeNone:
+		return "none"
+	case IP6AddrGenModeStablePrivacy:
+		return "stable_secret"
+	case IP6AddrGenModeRandom:
+		return "random"
+	default:
+		return "unknown"
+	}
+}
+
 // NewLinkAttrs returns LinkAttrs structure filled with default values
 func NewLinkAttrs() LinkAttrs {
 	return LinkAttrs{
@@ -167,11 +259,72 @@ type LinkStatistics64 struct {
 	TxCompressed      uint64
 }
 
+// LinkStatsDelta returns the per counter difference between two samples
+// of the same link. A counter smaller than in prev is taken to have
+// wrapped around and the unsigned difference is still the right one.
+func LinkStatsDelta(prev, curr *LinkStatistics64) LinkStatistics64 {
+	return LinkStatistics64{
+		RxPackets:         curr.RxPackets - prev.RxPackets,
+		TxPackets:         curr.TxPackets - prev.TxPackets,
+		RxBytes:           curr.RxBytes - prev.RxBytes,
+		TxBytes:           curr.TxBytes - prev.TxBytes,
+		RxErrors:          curr.RxErrors - prev.RxErrors,
+		TxErrors:          curr.TxErrors - prev.TxErrors,
+		RxDropped:         curr.RxDropped - prev.RxDropped,
+		TxDropped:         curr.TxDropped - prev.TxDropped,
+		Multicast:         curr.Multicast - prev.Multicast,
+		Collisions:        curr.Collisions - prev.Collisions,
+		RxLengthErrors:    curr.RxLengthErrors - prev.RxLengthErrors,
+		RxOverErrors:      curr.RxOverErrors - prev.RxOverErrors,
+		RxCrcErrors:       curr.RxCrcErrors - prev.RxCrcErrors,
+		RxFrameErrors:     curr.RxFrameErrors - prev.RxFrameErrors,
+		RxFifoErrors:      curr.RxFifoErrors - prev.RxFifoErrors,
+		RxMissedErrors:    curr.RxMissedErrors - prev.RxMissedErrors,
+		TxAbortedErrors:   curr.TxAbortedErrors - prev.TxAbortedErrors,
+		TxCarrierErrors:   curr.TxCarrierErrors - prev.TxCarrierErrors,
+		TxFifoErrors:      curr.TxFifoErrors - prev.TxFifoErrors,
+		TxHeartbeatErrors: curr.TxHeartbeatErrors - prev.TxHeartbeatErrors,
+		TxWindowErrors:    curr.TxWindowErrors - prev.TxWindowErrors,
+		RxCompressed:      curr.RxCompressed - prev.RxCompressed,
+		TxCompressed:      curr.TxCompressed - prev.TxCompressed,
+	}
+}
+
+// LinkRates are the per second rates of the main counters of a link.
+type LinkRates struct {
+	RxPackets float64
+	TxPackets float64
+	RxBytes   float64
+	TxBytes   float64
+}
+
+// LinkRate returns the rates of a delta computed by LinkStatsDelta over
+// the interval d between the two samples, all zero if d is not positive.
+func LinkRate(delta LinkStatistics64, d time.Duration) LinkRates {
+	if d <= 0 {
+		return LinkRates{}
+	}
+	secs := d.Seconds()
+	return LinkRates{
+		RxPackets: float64(delta.RxPackets) / secs,
+		TxPackets: float64(delta.TxPackets) / secs,
+		RxBytes:   float64(delta.RxBytes) / secs,
+		TxBytes:   float64(delta.TxBytes) / secs,
+	}
+}
+
+// LinkXdp is the XDP state of a link. ProgId is only reported when the
+// programs are attached in a single mode, the per mode ids tell which
+// mode actually attached.
 type LinkXdp struct {
-	Fd       int
-	Attached bool
-	Flags    uint32
-	ProgId   uint32
+	Fd         int
+	Attached   bool
+	AttachMode uint8 // nl.XDP_ATTACHED_* mode, read only
+	Flags      uint32
+	ProgId     uint32
+	DrvProgId  uint32 // read only
+	SkbProgId  uint32 // read only
+	HwProgId   uint32 // read only
 }
 
 // Device links cannot be created via netlink. These links
@@ -214,11 +367,46 @@ func (ifb *Ifb) Type() string {
 	return "ifb"
 }
 
+// Nlmon links capture netlink traffic, which can then be read with
+// tools such as tcpdump
+type Nlmon struct {
+	LinkAttrs
+}
+
+func (nlmon *Nlmon) Attrs() *LinkAttrs {
+	return &nlmon.LinkAttrs
+}
+
+func (nlmon *Nlmon) Type() string {
+	return "nlmon"
+}
+
+// VirtWifi links emulate a wireless device on top of an ethernet-like
+// parent, which must be set through ParentIndex
+type VirtWifi struct {
+	LinkAttrs
+}
+
+func (virtWifi *VirtWifi) Attrs() *LinkAttrs {
+	return &virtWifi.LinkAttrs
+}
+
+func (virtWifi *VirtWifi) Type() string {
+	return "virt_wifi"
+}
+
 // Bridge links are simple linux bridges
 type Bridge struct {
 	LinkAttrs
 	MulticastSnooping *bool
 	HelloTime         *uint32
+	ForwardDelay      *uint32 // in centiseconds
+	MaxAge            *uint32 // in centiseconds
+	AgeingTime        *uint32 // in centiseconds
+	StpState          *uint32
+	VlanFiltering     *bool
+	MulticastQuerier  *bool
+	MulticastRouter   *uint8 // one of BRIDGE_MCAST_ROUTER_*
 }
 
 func (bridge *Bridge) Attrs() *LinkAttrs {
@@ -229,6 +417,15 @@ func (bridge *Bridge) Type() string {
 	return "bridge"
 }
 
+// Multicast router modes of bridges and bridge ports, the temporary modes
+// are only valid on ports
+const (
+	BRIDGE_MCAST_ROUTER_DISABLED   = 0
+	BRIDGE_MCAST_ROUTER_TEMP_QUERY = 1 // learned from queries, the default
+	BRIDGE_MCAST_ROUTER_PERM       = 2
+	BRIDGE_MCAST_ROUTER_TEMP       = 3
+)
+
 // Vlan links have ParentIndex set in their Attrs()
 type Vlan struct {
 	LinkAttrs
@@ -258,6 +455,15 @@ const (
 type Macvlan struct {
 	LinkAttrs
 	Mode MacvlanMode
+	// BCQueueLen is the broadcast queue length requested by the link,
+	// the port of the parent uses the largest one of its macvlans which
+	// is reported in UsedBCQueueLen. BCCutoff is the number of macvlans
+	// on the port above which broadcasts are no longer queued, negative
+	// to always queue them. LinkAdd does not send them, they are changed
+	// with MacvlanSetBroadcast.
+	BCQueueLen     uint32
+	UsedBCQueueLen uint32
+	BCCutoff       int32
 }
 
 func (macvlan *Macvlan) Attrs() *LinkAttrs {
@@ -298,7 +504,9 @@ func (tuntap *Tuntap) Type() string {
 // Veth devices must specify PeerName on create
 type Veth struct {
 	LinkAttrs
-	PeerName string // veth on create only
+	PeerName         string           // veth on create only
+	PeerHardwareAddr net.HardwareAddr // veth on create only
+	PeerNamespace    interface{}      // veth on create only: nil | NsPid | NsFd
 }
 
 func (veth *Veth) Attrs() *LinkAttrs {
@@ -309,11 +517,49 @@ func (veth *Veth) Type() string {
 	return "veth"
 }
 
+type NetkitMode uint32
+
+const (
+	NETKIT_MODE_L2 NetkitMode = iota
+	NETKIT_MODE_L3
+)
+
+// NetkitPolicy is the default action for packets that no attached
+// BPF program handled.
+type NetkitPolicy int32
+
+const (
+	NETKIT_POLICY_FORWARD   NetkitPolicy = 0
+	NETKIT_POLICY_BLACKHOLE NetkitPolicy = 2
+)
+
+// Netkit devices must specify PeerName on create. Policy applies to this
+// device and PeerPolicy to its peer.
+type Netkit struct {
+	LinkAttrs
+	Mode       NetkitMode
+	Policy     NetkitPolicy
+	PeerPolicy NetkitPolicy
+	PeerName   string // netkit on create only
+	Primary    bool   // set on the device the pair was created from
+}
+
+func (netkit *Netkit) Attrs() *LinkAttrs {
+	return &netkit.LinkAttrs
+}
+
+func (netkit *Netkit) Type() string {
+	return "netkit"
+}
+
 // GenericLink links represent types that are not currently understood
-// by this netlink library.
+// by this netlink library. Data holds the raw IFLA_INFO_DATA attributes
+// keyed by attribute type; they are sent verbatim on create and filled
+// in on list.
 type GenericLink struct {
 	LinkAttrs
 	LinkType string
+	Data     map[uint16][]byte
 }
 
 func (generic *GenericLink) Attrs() *LinkAttrs {
@@ -669,6 +915,110 @@ func (bond *Bond) Type() string {
 	return "bond"
 }
 
+// LinkSlave is the state of a link as a port of its master, its concrete
+// type depends on the kind of the master.
+type LinkSlave interface {
+	SlaveType() string
+}
+
+// BondSlaveState represents the values of the IFLA_BOND_SLAVE_STATE
+// attribute.
+type BondSlaveState uint8
+
+const (
+	BondStateActive BondSlaveState = iota
+	BondStateBackup
+)
+
+func (s BondSlaveState) String() string {
+	switch s {
+	case BondStateActive:
+		return "ACTIVE"
+	case BondStateBackup:
+		return "BACKUP"
+	default:
+		return fmt.Sprintf("BondSlaveState(%d)", s)
+	}
+}
+
+// BondSlaveMiiStatus represents the values of the
+// IFLA_BOND_SLAVE_MII_STATUS attribute.
+type BondSlaveMiiStatus uint8
+
+const (
+	BondLinkUp BondSlaveMiiStatus = iota
+	BondLinkFail
+	BondLinkDown
+	BondLinkBack
+)
+
+func (s BondSlaveMiiStatus) String() string {
+	switch s {
+	case BondLinkUp:
+		return "UP"
+	case BondLinkFail:
+		return "GOING_DOWN"
+	case BondLinkDown:
+		return "DOWN"
+	case BondLinkBack:
+		return "GOING_BACK"
+	default:
+		return fmt.Sprintf("BondSlaveMiiStatus(%d)", s)
+	}
+}
+
+// BondSlave is the state of a port of a bond. AggregatorId is only
+// reported in 802.3ad mode.
+type BondSlave struct {
+	State            BondSlaveState
+	MiiStatus        BondSlaveMiiStatus
+	LinkFailureCount uint32
+	PermHardwareAddr net.HardwareAddr
+	QueueId          uint16
+	AggregatorId     uint16
+}
+
+func (b *BondSlave) SlaveType() string {
+	return "bond"
+}
+
+// BridgeSlave is the state of a port of a bridge, the same flags are
+// also kept in LinkAttrs.Protinfo.
+type BridgeSlave struct {
+	Protinfo
+}
+
+func (b *BridgeSlave) SlaveType() string {
+	return "bridge"
+}
+
+// TeamSlave is a port of a team. The team driver reports no port state
+// over rtnetlink, it is only configured through its genetlink family.
+type TeamSlave struct{}
+
+func (t *TeamSlave) SlaveType() string {
+	return "team"
+}
+
+// VrfSlave is a port of a vrf, Table is the routing table of the vrf.
+type VrfSlave struct {
+	Table uint32
+}
+
+func (v *VrfSlave) SlaveType() string {
+	return "vrf"
+}
+
+// GenericSlave is a port of a master whose slave data is not decoded by
+// this library.
+type GenericSlave struct {
+	MasterKind string
+}
+
+func (g *GenericSlave) SlaveType() string {
+	return g.MasterKind
+}
+
 // Gretap devices must specify LocalIP and RemoteIP on create
 type Gretap struct {
 	LinkAttrs
@@ -697,6 +1047,39 @@ func (gretap *Gretap) Type() string {
 	return "gretap"
 }
 
+// Erspan links mirror traffic to a remote collector in ERSPAN over GRE.
+// ErspanIndex is used by version 1, ErspanDir and ErspanHwId by version 2.
+type Erspan struct {
+	LinkAttrs
+	IKey        uint32
+	OKey        uint32
+	Local       net.IP
+	Remote      net.IP
+	IFlags      uint16
+	OFlags      uint16
+	Ttl         uint8
+	Tos         uint8
+	Link        uint32
+	ErspanVer   uint8
+	ErspanIndex uint32
+	ErspanDir   uint8
+	ErspanHwId  uint16
+}
+
+func (erspan *Erspan) Attrs() *LinkAttrs {
+	return &erspan.LinkAttrs
+}
+
+func (erspan *Erspan) Type() string {
+	return "erspan"
+}
+
+// ERSPAN version 2 directions
+const (
+	ERSPAN_DIR_INGRESS = 0
+	ERSPAN_DIR_EGRESS  = 1
+)
+
 type Iptun struct {
 	LinkAttrs
 	Ttl      uint8
@@ -715,6 +1098,53 @@ func (iptun *Iptun) Type() string {
 	return "ipip"
 }
 
+// Sittun links are IPv6-in-IPv4 tunnels. Setting SixRDPrefix enables IPv6
+// rapid deployment (6rd) on the tunnel, with SixRDRelayPrefix as the IPv4
+// prefix common to the border relays.
+type Sittun struct {
+	LinkAttrs
+	Ttl              uint8
+	Tos              uint8
+	PMtuDisc         uint8
+	Link             uint32
+	Local            net.IP
+	Remote           net.IP
+	SixRDPrefix      *net.IPNet
+	SixRDRelayPrefix *net.IPNet
+}
+
+func (sittun *Sittun) Attrs() *LinkAttrs {
+	return &sittun.LinkAttrs
+}
+
+func (sittun *Sittun) Type() string {
+	return "sit"
+}
+
+// Ip6tnl links are IPv4/IPv6-in-IPv6 tunnels. FlowInfo is in host byte
+// order and holds the traffic class and flow label of the outer header.
+// A zero EncapLimit keeps the kernel default, nl.IP6_TNL_F_IGN_ENCAP_LIMIT
+// in Flags disables the limit.
+type Ip6tnl struct {
+	LinkAttrs
+	Link       uint32
+	Local      net.IP
+	Remote     net.IP
+	Ttl        uint8
+	EncapLimit uint8
+	FlowInfo   uint32
+	Flags      uint32
+	Proto      uint8
+}
+
+func (ip6tnl *Ip6tnl) Attrs() *LinkAttrs {
+	return &ip6tnl.LinkAttrs
+}
+
+func (ip6tnl *Ip6tnl) Type() string {
+	return "ip6tnl"
+}
+
 type Vti struct {
 	LinkAttrs
 	IKey   uint32
@@ -783,6 +1213,88 @@ func (gtp *GTP) Type() string {
 	return "gtp"
 }
 
+// Hsr links provide HSR or PRP redundancy over two slave devices.
+// SupervisionAddr and SeqNr are read only. Version is create only as
+// the kernel does not report it back.
+type Hsr struct {
+	LinkAttrs
+	Slave1          uint32
+	Slave2          uint32
+	MulticastSpec   uint8
+	SupervisionAddr net.HardwareAddr
+	SeqNr           uint16
+	Version         uint8
+	Protocol        uint8
+}
+
+func (hsr *Hsr) Attrs() *LinkAttrs {
+	return &hsr.LinkAttrs
+}
+
+func (hsr *Hsr) Type() string {
+	return "hsr"
+}
+
+// Hsr protocols
+const (
+	HSR_PROTOCOL_HSR = 0
+	HSR_PROTOCOL_PRP = 1
+)
+
+// IPoIBMode is the transport mode of an Ipoib link
+type IPoIBMode uint16
+
+const (
+	IPOIB_MODE_DATAGRAM IPoIBMode = iota
+	IPOIB_MODE_CONNECTED
+)
+
+// Ipoib links are IP over Infiniband child interfaces of an IB parent,
+// Pkey is the partition key of the child, 0 for the one of the parent.
+type Ipoib struct {
+	LinkAttrs
+	Pkey   uint16
+	Mode   IPoIBMode
+	Umcast uint16 // allow user space multicast
+}
+
+func (ipoib *Ipoib) Attrs() *LinkAttrs {
+	return &ipoib.LinkAttrs
+}
+
+func (ipoib *Ipoib) Type() string {
+	return "ipoib"
+}
+
+// AmtMode is the role of an Amt link
+type AmtMode uint32
+
+const (
+	AMT_MODE_GATEWAY AmtMode = iota
+	AMT_MODE_RELAY
+)
+
+// Amt links are Automatic Multicast Tunneling gateways or relays over
+// the link ParentIndex. A gateway finds its relay through DiscoveryIP.
+type Amt struct {
+	LinkAttrs
+	Mode        AmtMode
+	RelayPort   uint16
+	GatewayPort uint16
+	LocalIP     net.IP
+	RemoteIP    net.IP
+	DiscoveryIP net.IP
+	MaxTunnels  uint32 // relays only
+}
+
+func (amt *Amt) Attrs() *LinkAttrs {
+	return &amt.LinkAttrs
+}
+
+func (amt *Amt) Type() string {
+	return "amt"
+}
+
 // iproute2 supported devices;
 // vlan | veth | vcan | dummy | ifb | macvlan | macvtap |
 // bridge | bond | ipoib | ip6tnl | ipip | sit | vxlan |
@@ -796,3 +1308,14 @@ func (gtp *GTP) Type() string {
 type LinkNotFoundError struct {
 	error
 }
+
+// LinkNameExistsError is returned by LinkSetName when another link
+// already has the name, so that callers can rename through a temporary
+// name as LinkSwapNames does. It wraps the EEXIST of the kernel.
+type LinkNameExistsError struct {
+	error
+}
+
+func (e LinkNameExistsError) Unwrap() error {
+	return errors.Unwrap(e.error)
+}
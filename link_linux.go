@@ -3,9 +3,14 @@ package netlink
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"syscall"
 	"unsafe"
 
@@ -144,6 +149,55 @@ func SetPromiscOff(link Link) error {
 	return pkgHandle.SetPromiscOff(link)
 }
 
+// LinkSetAllmulticastOn enables the reception of all multicast packets
+// on the link device.
+// Equivalent to: `ip link set $link allmulticast on`
+func LinkSetAllmulticastOn(link Link) error {
+	return pkgHandle.LinkSetAllmulticastOn(link)
+}
+
+// LinkSetAllmulticastOn enables the reception of all multicast packets
+// on the link device.
+// Equivalent to: `ip link set $link allmulticast on`
+func (h *Handle) LinkSetAllmulticastOn(link Link) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(syscall.RTM_SETLINK, syscall.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Change = syscall.IFF_ALLMULTI
+	msg.Flags = syscall.IFF_ALLMULTI
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+// LinkSetAllmulticastOff disables the reception of all multicast packets
+// on the link device.
+// Equivalent to: `ip link set $link allmulticast off`
+func LinkSetAllmulticastOff(link Link) error {
+	return pkgHandle.LinkSetAllmulticastOff(link)
+}
+
+// LinkSetAllmulticastOff disables the reception of all multicast packets
+// on the link device.
+// Equivalent to: `ip link set $link allmulticast off`
+func (h *Handle) LinkSetAllmulticastOff(link Link) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(syscall.RTM_SETLINK, syscall.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Change = syscall.IFF_ALLMULTI
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
 // LinkSetUp enables the link device.
 // Equivalent to: `ip link set $link up`
 func LinkSetUp(link Link) error {
@@ -238,9 +292,44 @@ func (h *Handle) LinkSetName(link Link, name string) error {
 	req.AddData(data)
 
 	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	if errors.Is(err, syscall.EEXIST) {
+		return LinkNameExistsError{fmt.Errorf("link %s already exists: %w", name, err)}
+	}
 	return err
 }
 
+// LinkSwapNames swaps the names of two link devices through a temporary
+// name, the links must be down. The names are restored when one of the
+// renames fails, and the attrs of the links are updated on success.
+func LinkSwapNames(a, b Link) error {
+	return pkgHandle.LinkSwapNames(a, b)
+}
+
+// LinkSwapNames swaps the names of two link devices through a temporary
+// name, the links must be down. The names are restored when one of the
+// renames fails, and the attrs of the links are updated on success.
+func (h *Handle) LinkSwapNames(a, b Link) error {
+	h.ensureIndex(a.Attrs())
+	h.ensureIndex(b.Attrs())
+	nameA, nameB := a.Attrs().Name, b.Attrs().Name
+	tmpName := fmt.Sprintf("nltmp%d", a.Attrs().Index)
+
+	if err := h.LinkSetName(a, tmpName); err != nil {
+		return err
+	}
+	if err := h.LinkSetName(b, nameA); err != nil {
+		h.LinkSetName(a, nameA)
+		return err
+	}
+	if err := h.LinkSetName(a, nameB); err != nil {
+		h.LinkSetName(b, nameB)
+		h.LinkSetName(a, nameA)
+		return err
+	}
+	a.Attrs().Name, b.Attrs().Name = nameB, nameA
+	return nil
+}
+
 // LinkSetAlias sets the alias of the link device.
 // Equivalent to: `ip link set dev $link alias $name`
 func LinkSetAlias(link Link, name string) error {
@@ -447,21 +536,123 @@ func (h *Handle) LinkSetVfTrust(link Link, vf int, state bool) error {
 	return err
 }
 
-// LinkSetMaster sets the master of the link device.
+// LinkSetVfConfig applies all the settings in cfg to a vf of the link
+// in a single request.
+// Equivalent to: `ip link set $link vf $vf mac $mac vlan $vlan ...`
+func LinkSetVfConfig(link Link, vf int, cfg VfConfig) error {
+	return pkgHandle.LinkSetVfConfig(link, vf, cfg)
+}
+
+// LinkSetVfConfig applies all the settings in cfg to a vf of the link
+// in a single request.
+// Equivalent to: `ip link set $link vf $vf mac $mac vlan $vlan ...`
+func (h *Handle) LinkSetVfConfig(link Link, vf int, cfg VfConfig) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(syscall.RTM_SETLINK, syscall.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	data := nl.NewRtAttr(nl.IFLA_VFINFO_LIST, nil)
+	info := nl.NewRtAttrChild(data, nl.IFLA_VF_INFO, nil)
+	addVfConfigAttrs(info, vf, cfg)
+	req.AddData(data)
+
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+func addVfConfigAttrs(info *nl.RtAttr, vf int, cfg VfConfig) {
+	boolSetting := func(b bool) uint32 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	if cfg.Mac != nil {
+		vfmsg := nl.VfMac{
+			Vf: uint32(vf),
+		}
+		copy(vfmsg.Mac[:], []byte(cfg.Mac))
+		nl.NewRtAttrChild(info, nl.IFLA_VF_MAC, vfmsg.Serialize())
+	}
+	if cfg.VlanProto != 0 {
+		// the protocol can only be set through the vlan list
+		vlans := nl.NewRtAttrChild(info, nl.IFLA_VF_VLAN_LIST, nil)
+		vfmsg := nl.VfVlanInfo{
+			VfVlan: nl.VfVlan{
+				Vf:   uint32(vf),
+				Vlan: uint32(cfg.Vlan),
+				Qos:  uint32(cfg.Qos),
+			},
+			VlanProto: nl.Swap16(uint16(cfg.VlanProto)),
+		}
+		nl.NewRtAttrChild(vlans, nl.IFLA_VF_VLAN_INFO, vfmsg.Serialize())
+	} else if cfg.Vlan != 0 || cfg.Qos != 0 {
+		vfmsg := nl.VfVlan{
+			Vf:   uint32(vf),
+			Vlan: uint32(cfg.Vlan),
+			Qos:  uint32(cfg.Qos),
+		}
+		nl.NewRtAttrChild(info, nl.IFLA_VF_VLAN, vfmsg.Serialize())
+	}
+	if cfg.MinTxRate != 0 || cfg.MaxTxRate != 0 {
+		vfmsg := nl.VfRate{
+			Vf:        uint32(vf),
+			MinTxRate: uint32(cfg.MinTxRate),
+			MaxTxRate: uint32(cfg.MaxTxRate),
+		}
+		nl.NewRtAttrChild(info, nl.IFLA_VF_RATE, vfmsg.Serialize())
+	}
+	if cfg.Spoofchk != nil {
+		vfmsg := nl.VfSpoofchk{
+			Vf:      uint32(vf),
+			Setting: boolSetting(*cfg.Spoofchk),
+		}
+		nl.NewRtAttrChild(info, nl.IFLA_VF_SPOOFCHK, vfmsg.Serialize())
+	}
+	if cfg.Trust != nil {
+		vfmsg := nl.VfTrust{
+			Vf:      uint32(vf),
+			Setting: boolSetting(*cfg.Trust),
+		}
+		nl.NewRtAttrChild(info, nl.IFLA_VF_TRUST, vfmsg.Serialize())
+	}
+	if cfg.LinkState != nil {
+		vfmsg := nl.VfLinkState{
+			Vf:        uint32(vf),
+			LinkState: *cfg.LinkState,
+		}
+		nl.NewRtAttrChild(info, nl.IFLA_VF_LINK_STATE, vfmsg.Serialize())
+	}
+	if cfg.RssQuery != nil {
+		vfmsg := nl.VfRssQueryEn{
+			Vf:      uint32(vf),
+			Setting: boolSetting(*cfg.RssQuery),
+		}
+		nl.NewRtAttrChild(info, nl.IFLA_VF_RSS_QUERY_EN, vfmsg.Serialize())
+	}
+}
+
+// LinkSetMaster sets the master of the link device. A nil master
+// detaches the link from its current master, like LinkSetNoMaster.
 // Equivalent to: `ip link set $link master $master`
 func LinkSetMaster(link Link, master *Bridge) error {
 	return pkgHandle.LinkSetMaster(link, master)
 }
 
-// LinkSetMaster sets the master of the link device.
+// LinkSetMaster sets the master of the link device. A nil master
+// detaches the link from its current master, like LinkSetNoMaster.
 // Equivalent to: `ip link set $link master $master`
 func (h *Handle) LinkSetMaster(link Link, master *Bridge) error {
-	index := 0
-	if master != nil {
-		masterBase := master.Attrs()
-		h.ensureIndex(masterBase)
-		index = masterBase.Index
+	if master == nil {
+		return h.LinkSetNoMaster(link)
 	}
+	masterBase := master.Attrs()
+	h.ensureIndex(masterBase)
+	index := masterBase.Index
 	if index <= 0 {
 		return fmt.Errorf("Device does not exist")
 	}
@@ -480,6 +671,37 @@ func (h *Handle) LinkSetNoMaster(link Link) error {
 	return h.LinkSetMasterByIndex(link, 0)
 }
 
+// LinkGetVrfTable returns the routing table of the vrf the link is
+// enslaved to, false if its master is not a vrf. The table is taken from
+// the slave info of the link when it was listed, the master is only
+// looked up on kernels that do not report it.
+func LinkGetVrfTable(link Link) (uint32, bool) {
+	return pkgHandle.LinkGetVrfTable(link)
+}
+
+// LinkGetVrfTable returns the routing table of the vrf the link is
+// enslaved to, false if its master is not a vrf. The table is taken from
+// the slave info of the link when it was listed, the master is only
+// looked up on kernels that do not report it.
+func (h *Handle) LinkGetVrfTable(link Link) (uint32, bool) {
+	base := link.Attrs()
+	if slave, ok := base.Slave.(*VrfSlave); ok {
+		return slave.Table, true
+	}
+	if base.MasterIndex == 0 {
+		return 0, false
+	}
+	master, err := h.LinkByIndex(base.MasterIndex)
+	if err != nil {
+		return 0, false
+	}
+	vrf, ok := master.(*Vrf)
+	if !ok {
+		return 0, false
+	}
+	return vrf.Table, true
+}
+
 // LinkSetMasterByIndex sets the master of the link device.
 // Equivalent to: `ip link set $link master $master`
 func LinkSetMasterByIndex(link Link, masterIndex int) error {
@@ -507,6 +729,151 @@ func (h *Handle) LinkSetMasterByIndex(link Link, masterIndex int) error {
 	return err
 }
 
+// LinkModify brings the link found by index, or by name if the index is
+// not set, to the state of desired in a single request carrying only the
+// attributes that differ, and returns their names as in `ip link set`.
+// The MTU, TxQLen, HardwareAddr, MasterIndex and Alias of desired are
+// compared when set, as are the options of a Bridge; the flags can only
+// bring the link up, use LinkSetDown and LinkSetNoMaster to bring it down
+// or detach it.
+func LinkModify(desired Link) ([]string, error) {
+	return pkgHandle.LinkModify(desired)
+}
+
+// LinkModify brings the link found by index, or by name if the index is
+// not set, to the state of desired in a single request carrying only the
+// attributes that differ, and returns their names as in `ip link set`.
+// The MTU, TxQLen, HardwareAddr, MasterIndex and Alias of desired are
+// compared when set, as are the options of a Bridge; the flags can only
+// bring the link up, use LinkSetDown and LinkSetNoMaster to bring it down
+// or detach it.
+func (h *Handle) LinkModify(desired Link) ([]string, error) {
+	want := desired.Attrs()
+	var (
+		current Link
+		err     error
+	)
+	if want.Index != 0 {
+		current, err = h.LinkByIndex(want.Index)
+	} else {
+		current, err = h.LinkByName(want.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	have := current.Attrs()
+
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Index = int32(have.Index)
+	var (
+		changed []string
+		attrs   []*nl.RtAttr
+	)
+	if want.Flags&net.FlagUp != 0 && have.Flags&net.FlagUp == 0 {
+		msg.Change = syscall.IFF_UP
+		msg.Flags = syscall.IFF_UP
+		changed = append(changed, "up")
+	}
+	if want.MTU > 0 && want.MTU != have.MTU {
+		attrs = append(attrs, nl.NewRtAttr(syscall.IFLA_MTU, nl.Uint32Attr(uint32(want.MTU))))
+		changed = append(changed, "mtu")
+	}
+	if want.TxQLen > 0 && want.TxQLen != have.TxQLen {
+		attrs = append(attrs, nl.NewRtAttr(syscall.IFLA_TXQLEN, nl.Uint32Attr(uint32(want.TxQLen))))
+		changed = append(changed, "txqueuelen")
+	}
+	if want.HardwareAddr != nil && !bytes.Equal(want.HardwareAddr, have.HardwareAddr) {
+		attrs = append(attrs, nl.NewRtAttr(syscall.IFLA_ADDRESS, []byte(want.HardwareAddr)))
+		changed = append(changed, "address")
+	}
+	if want.MasterIndex > 0 && want.MasterIndex != have.MasterIndex {
+		attrs = append(attrs, nl.NewRtAttr(syscall.IFLA_MASTER, nl.Uint32Attr(uint32(want.MasterIndex))))
+		changed = append(changed, "master")
+	}
+	if want.Alias != "" && want.Alias != have.Alias {
+		attrs = append(attrs, nl.NewRtAttr(syscall.IFLA_IFALIAS, []byte(want.Alias)))
+		changed = append(changed, "alias")
+	}
+	// RTM_SETLINK ignores the info data, which is only changed by an
+	// RTM_NEWLINK of the existing link
+	proto := syscall.RTM_SETLINK
+	if bridge, ok := desired.(*Bridge); ok {
+		if delta, names := bridgeDelta(bridge, current); len(names) != 0 {
+			linkInfo := nl.NewRtAttr(syscall.IFLA_LINKINFO, nil)
+			nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_KIND, nl.NonZeroTerminated(bridge.Type()))
+			addBridgeAttrs(delta, linkInfo)
+			attrs = append(attrs, linkInfo)
+			changed = append(changed, names...)
+			proto = syscall.RTM_NEWLINK
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	req := h.newNetlinkRequest(proto, syscall.NLM_F_ACK)
+	req.AddData(msg)
+	for _, attr := range attrs {
+		req.AddData(attr)
+	}
+	if _, err := req.Execute(syscall.NETLINK_ROUTE, 0); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// bridgeDelta returns the options of want that differ from the ones of
+// current, and their names as in `ip link set type bridge`.
+func bridgeDelta(want *Bridge, current Link) (*Bridge, []string) {
+	have, ok := current.(*Bridge)
+	if !ok {
+		have = &Bridge{}
+	}
+	var (
+		delta Bridge
+		names []string
+	)
+	diffBool := func(w, h *bool) bool { return w != nil && (h == nil || *w != *h) }
+	diffUint32 := func(w, h *uint32) bool { return w != nil && (h == nil || *w != *h) }
+	if diffBool(want.MulticastSnooping, have.MulticastSnooping) {
+		delta.MulticastSnooping = want.MulticastSnooping
+		names = append(names, "mcast_snooping")
+	}
+	if diffUint32(want.HelloTime, have.HelloTime) {
+		delta.HelloTime = want.HelloTime
+		names = append(names, "hello_time")
+	}
+	if diffUint32(want.ForwardDelay, have.ForwardDelay) {
+		delta.ForwardDelay = want.ForwardDelay
+		names = append(names, "forward_delay")
+	}
+	if diffUint32(want.MaxAge, have.MaxAge) {
+		delta.MaxAge = want.MaxAge
+		names = append(names, "max_age")
+	}
+	if diffUint32(want.AgeingTime, have.AgeingTime) {
+		delta.AgeingTime = want.AgeingTime
+		names = append(names, "ageing_time")
+	}
+	if diffUint32(want.StpState, have.StpState) {
+		delta.StpState = want.StpState
+		names = append(names, "stp_state")
+	}
+	if diffBool(want.VlanFiltering, have.VlanFiltering) {
+		delta.VlanFiltering = want.VlanFiltering
+		names = append(names, "vlan_filtering")
+	}
+	if diffBool(want.MulticastQuerier, have.MulticastQuerier) {
+		delta.MulticastQuerier = want.MulticastQuerier
+		names = append(names, "mcast_querier")
+	}
+	if want.MulticastRouter != nil && (have.MulticastRouter == nil || *want.MulticastRouter != *have.MulticastRouter) {
+		delta.MulticastRouter = want.MulticastRouter
+		names = append(names, "mcast_router")
+	}
+	return &delta, names
+}
+
 // LinkSetNsPid puts the device into a new network namespace. The
 // pid must be a pid of a running process.
 // Equivalent to: `ip link set $link netns $pid`
@@ -858,8 +1225,8 @@ func (h *Handle) linkModify(link Link, flags int) error {
 		native.PutUint32(b, uint32(base.ParentIndex))
 		data := nl.NewRtAttr(syscall.IFLA_LINK, b)
 		req.AddData(data)
-	} else if link.Type() == "ipvlan" {
-		return fmt.Errorf("Can't create ipvlan link without ParentIndex")
+	} else if link.Type() == "ipvlan" || link.Type() == "ipoib" {
+		return fmt.Errorf("Can't create %s link without ParentIndex", link.Type())
 	}
 
 	nameData := nl.NewRtAttr(syscall.IFLA_IFNAME, nl.ZeroTerminated(base.Name))
@@ -875,6 +1242,11 @@ func (h *Handle) linkModify(link Link, flags int) error {
 		req.AddData(qlen)
 	}
 
+	if base.Group > 0 {
+		group := nl.NewRtAttr(nl.IFLA_GROUP, nl.Uint32Attr(base.Group))
+		req.AddData(group)
+	}
+
 	if base.HardwareAddr != nil {
 		hwaddr := nl.NewRtAttr(syscall.IFLA_ADDRESS, []byte(base.HardwareAddr))
 		req.AddData(hwaddr)
@@ -917,6 +1289,15 @@ func (h *Handle) linkModify(link Link, flags int) error {
 		if base.MTU > 0 {
 			nl.NewRtAttrChild(peer, syscall.IFLA_MTU, nl.Uint32Attr(uint32(base.MTU)))
 		}
+		if veth.PeerHardwareAddr != nil {
+			nl.NewRtAttrChild(peer, syscall.IFLA_ADDRESS, []byte(veth.PeerHardwareAddr))
+		}
+		switch ns := veth.PeerNamespace.(type) {
+		case NsPid:
+			nl.NewRtAttrChild(peer, syscall.IFLA_NET_NS_PID, nl.Uint32Attr(uint32(ns)))
+		case NsFd:
+			nl.NewRtAttrChild(peer, nl.IFLA_NET_NS_FD, nl.Uint32Attr(uint32(ns)))
+		}
 
 	} else if vxlan, ok := link.(*Vxlan); ok {
 		addVxlanAttrs(vxlan, linkInfo)
@@ -937,8 +1318,14 @@ func (h *Handle) linkModify(link Link, flags int) error {
 		}
 	} else if gretap, ok := link.(*Gretap); ok {
 		addGretapAttrs(gretap, linkInfo)
+	} else if erspan, ok := link.(*Erspan); ok {
+		addErspanAttrs(erspan, linkInfo)
 	} else if iptun, ok := link.(*Iptun); ok {
 		addIptunAttrs(iptun, linkInfo)
+	} else if sittun, ok := link.(*Sittun); ok {
+		addSittunAttrs(sittun, linkInfo)
+	} else if ip6tnl, ok := link.(*Ip6tnl); ok {
+		addIp6tnlAttrs(ip6tnl, linkInfo)
 	} else if gretun, ok := link.(*Gretun); ok {
 		addGretunAttrs(gretun, linkInfo)
 	} else if vti, ok := link.(*Vti); ok {
@@ -949,6 +1336,16 @@ func (h *Handle) linkModify(link Link, flags int) error {
 		addBridgeAttrs(bridge, linkInfo)
 	} else if gtp, ok := link.(*GTP); ok {
 		addGTPAttrs(gtp, linkInfo)
+	} else if hsr, ok := link.(*Hsr); ok {
+		addHsrAttrs(hsr, linkInfo)
+	} else if ipoib, ok := link.(*Ipoib); ok {
+		addIpoibAttrs(ipoib, linkInfo)
+	} else if amt, ok := link.(*Amt); ok {
+		addAmtAttrs(amt, linkInfo)
+	} else if netkit, ok := link.(*Netkit); ok {
+		addNetkitAttrs(netkit, linkInfo)
+	} else if generic, ok := link.(*GenericLink); ok {
+		addGenericAttrs(generic, linkInfo)
 	}
 
 	req.AddData(linkInfo)
@@ -1041,7 +1438,7 @@ func (h *Handle) LinkByName(name string) (Link, error) {
 	req.AddData(nameData)
 
 	link, err := execGetLink(req)
-	if err == syscall.EINVAL {
+	if errors.Is(err, syscall.EINVAL) {
 		// older kernels don't support looking up via IFLA_IFNAME
 		// so fall back to dumping all links
 		h.lookupByDump = true
@@ -1073,7 +1470,7 @@ func (h *Handle) LinkByAlias(alias string) (Link, error) {
 	req.AddData(nameData)
 
 	link, err := execGetLink(req)
-	if err == syscall.EINVAL {
+	if errors.Is(err, syscall.EINVAL) {
 		// older kernels don't support looking up via IFLA_IFALIAS
 		// so fall back to dumping all links
 		h.lookupByDump = true
@@ -1102,10 +1499,8 @@ func (h *Handle) LinkByIndex(index int) (Link, error) {
 func execGetLink(req *nl.NetlinkRequest) (Link, error) {
 	msgs, err := req.Execute(syscall.NETLINK_ROUTE, 0)
 	if err != nil {
-		if errno, ok := err.(syscall.Errno); ok {
-			if errno == syscall.ENODEV {
-				return nil, LinkNotFoundError{fmt.Errorf("Link not found")}
-			}
+		if errors.Is(err, syscall.ENODEV) {
+			return nil, LinkNotFoundError{fmt.Errorf("Link not found")}
 		}
 		return nil, err
 	}
@@ -1137,10 +1532,11 @@ func LinkDeserialize(hdr *syscall.NlMsghdr, m []byte) (Link, error) {
 		base.Promisc = 1
 	}
 	var (
-		link     Link
-		stats32  []byte
-		stats64  []byte
-		linkType string
+		link      Link
+		stats32   []byte
+		stats64   []byte
+		linkType  string
+		slaveKind string
 	)
 	for _, attr := range attrs {
 		switch attr.Attr.Type {
@@ -1158,6 +1554,12 @@ func LinkDeserialize(hdr *syscall.NlMsghdr, m []byte) (Link, error) {
 						link = &Dummy{}
 					case "ifb":
 						link = &Ifb{}
+					case "nlmon":
+						link = &Nlmon{}
+					case "virt_wifi":
+						link = &VirtWifi{}
+					case "netkit":
+						link = &Netkit{}
 					case "bridge":
 						link = &Bridge{}
 					case "vlan":
@@ -1176,8 +1578,14 @@ func LinkDeserialize(hdr *syscall.NlMsghdr, m []byte) (Link, error) {
 						link = &Macvtap{}
 					case "gretap":
 						link = &Gretap{}
+					case "erspan":
+						link = &Erspan{}
 					case "ipip":
 						link = &Iptun{}
+					case "sit":
+						link = &Sittun{}
+					case "ip6tnl":
+						link = &Ip6tnl{}
 					case "gre":
 						link = &Gretun{}
 					case "vti":
@@ -1186,6 +1594,12 @@ func LinkDeserialize(hdr *syscall.NlMsghdr, m []byte) (Link, error) {
 						link = &Vrf{}
 					case "gtp":
 						link = &GTP{}
+					case "hsr":
+						link = &Hsr{}
+					case "ipoib":
+						link = &Ipoib{}
+					case "amt":
+						link = &Amt{}
 					default:
 						link = &GenericLink{LinkType: linkType}
 					}
@@ -1209,8 +1623,14 @@ func LinkDeserialize(hdr *syscall.NlMsghdr, m []byte) (Link, error) {
 						parseMacvtapData(link, data)
 					case "gretap":
 						parseGretapData(link, data)
+					case "erspan":
+						parseErspanData(link, data)
 					case "ipip":
 						parseIptunData(link, data)
+					case "sit":
+						parseSittunData(link, data)
+					case "ip6tnl":
+						parseIp6tnlData(link, data)
 					case "gre":
 						parseGretunData(link, data)
 					case "vti":
@@ -1221,9 +1641,37 @@ func LinkDeserialize(hdr *syscall.NlMsghdr, m []byte) (Link, error) {
 						parseBridgeData(link, data)
 					case "gtp":
 						parseGTPData(link, data)
+					case "hsr":
+						parseHsrData(link, data)
+					case "ipoib":
+						parseIpoibData(link, data)
+					case "amt":
+						parseAmtData(link, data, &base)
+					case "netkit":
+						parseNetkitData(link, data)
+					default:
+						if _, ok := link.(*GenericLink); ok {
+							parseGenericData(link, data)
+						}
+					}
+				case nl.IFLA_INFO_SLAVE_KIND:
+					slaveKind = string(info.Value[:len(info.Value)-1])
+				case nl.IFLA_INFO_SLAVE_DATA:
+					data, err := nl.ParseRouteAttr(info.Value)
+					if err != nil {
+						return nil, err
+					}
+					base.Slave = parseLinkSlave(slaveKind, data)
+					if bs, ok := base.Slave.(*BridgeSlave); ok {
+						pi := bs.Protinfo
+						base.Protinfo = &pi
 					}
 				}
 			}
+			// masters without slave ops such as team only report the kind
+			if slaveKind != "" && base.Slave == nil {
+				base.Slave = parseLinkSlave(slaveKind, nil)
+			}
 		case syscall.IFLA_ADDRESS:
 			var nonzero bool
 			for _, b := range attr.Value {
@@ -1236,14 +1684,49 @@ func LinkDeserialize(hdr *syscall.NlMsghdr, m []byte) (Link, error) {
 			}
 		case syscall.IFLA_IFNAME:
 			base.Name = string(attr.Value[:len(attr.Value)-1])
+		case nl.IFLA_AF_SPEC:
+			if msg.Family == syscall.AF_BRIDGE {
+				// bridge messages carry vlan info here instead
+				continue
+			}
+			afs, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return nil, err
+			}
+			for _, af := range afs {
+				if af.Attr.Type != syscall.AF_INET6 {
+					continue
+				}
+				inet6, err := nl.ParseRouteAttr(af.Value)
+				if err != nil {
+					return nil, err
+				}
+				parseInet6AfSpec(&base, inet6)
+			}
 		case syscall.IFLA_MTU:
 			base.MTU = int(native.Uint32(attr.Value[0:4]))
+		case nl.IFLA_MIN_MTU:
+			base.MinMTU = int(native.Uint32(attr.Value[0:4]))
+		case nl.IFLA_MAX_MTU:
+			base.MaxMTU = int(native.Uint32(attr.Value[0:4]))
+		case nl.IFLA_PROMISCUITY:
+			base.Promiscuity = int(native.Uint32(attr.Value[0:4]))
+		case nl.IFLA_ALLMULTI:
+			base.Allmulti = int(native.Uint32(attr.Value[0:4]))
+		case nl.IFLA_CARRIER_CHANGES:
+			base.CarrierChanges = native.Uint32(attr.Value[0:4])
+		case nl.IFLA_CARRIER_UP_COUNT:
+			base.CarrierUpCount = native.Uint32(attr.Value[0:4])
+		case nl.IFLA_CARRIER_DOWN_COUNT:
+			base.CarrierDownCount = native.Uint32(attr.Value[0:4])
 		case syscall.IFLA_LINK:
 			base.ParentIndex = int(native.Uint32(attr.Value[0:4]))
 		case syscall.IFLA_MASTER:
 			base.MasterIndex = int(native.Uint32(attr.Value[0:4]))
 		case syscall.IFLA_TXQLEN:
 			base.TxQLen = int(native.Uint32(attr.Value[0:4]))
+		case nl.IFLA_GROUP:
+			base.Group = native.Uint32(attr.Value[0:4])
 		case syscall.IFLA_IFALIAS:
 			base.Alias = string(attr.Value[:len(attr.Value)-1])
 		case syscall.IFLA_STATS:
@@ -1267,6 +1750,16 @@ func LinkDeserialize(hdr *syscall.NlMsghdr, m []byte) (Link, error) {
 			}
 		case syscall.IFLA_OPERSTATE:
 			base.OperState = LinkOperState(uint8(attr.Value[0]))
+		case syscall.IFLA_LINKMODE:
+			base.LinkMode = LinkMode(attr.Value[0])
+		case nl.IFLA_PHYS_PORT_NAME:
+			base.PhysPortName = string(bytes.TrimRight(attr.Value, "\x00"))
+		case nl.IFLA_PHYS_SWITCH_ID:
+			base.PhysSwitchID = append([]byte{}, attr.Value...)
+		case nl.IFLA_PARENT_DEV_NAME:
+			base.ParentDevName = string(bytes.TrimRight(attr.Value, "\x00"))
+		case nl.IFLA_PARENT_DEV_BUS_NAME:
+			base.ParentDevBusName = string(bytes.TrimRight(attr.Value, "\x00"))
 		}
 	}
 
@@ -1294,6 +1787,18 @@ func LinkList() ([]Link, error) {
 // LinkList gets a list of link devices.
 // Equivalent to: `ip link show`
 func (h *Handle) LinkList() ([]Link, error) {
+	return h.LinkListWithOptions(nil)
+}
+
+// LinkListWithOptions gets a list of link devices, letting the caller
+// select the optional data included in the dump.
+func LinkListWithOptions(options *LinkListOptions) ([]Link, error) {
+	return pkgHandle.LinkListWithOptions(options)
+}
+
+// LinkListWithOptions gets a list of link devices, letting the caller
+// select the optional data included in the dump.
+func (h *Handle) LinkListWithOptions(options *LinkListOptions) ([]Link, error) {
 	// NOTE(vish): This duplicates functionality in net/iface_linux.go, but we need
 	//             to get the message ourselves to parse link type.
 	req := h.newNetlinkRequest(syscall.RTM_GETLINK, syscall.NLM_F_DUMP)
@@ -1301,6 +1806,10 @@ func (h *Handle) LinkList() ([]Link, error) {
 	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
 	req.AddData(msg)
 
+	if options != nil && options.ExtMask != 0 {
+		req.AddData(nl.NewRtAttr(nl.IFLA_EXT_MASK, nl.Uint32Attr(options.ExtMask)))
+	}
+
 	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWLINK)
 	if err != nil {
 		return nil, err
@@ -1348,6 +1857,23 @@ func linkSubscribe(newNs, curNs netns.NsHandle, ch chan<- LinkUpdate, done <-cha
 			s.Close()
 		}()
 	}
+	receiveLinkUpdates(s, ch)
+	return nil
+}
+
+// LinkSubscribe works like the package level LinkSubscribe, but subscribes
+// in the handle's network namespace. The subscription is also stopped
+// when the handle is closed.
+func (h *Handle) LinkSubscribe(ch chan<- LinkUpdate, done <-chan struct{}) error {
+	s, err := h.subscribe(done, syscall.NETLINK_ROUTE, syscall.RTNLGRP_LINK)
+	if err != nil {
+		return err
+	}
+	receiveLinkUpdates(s, ch)
+	return nil
+}
+
+func receiveLinkUpdates(s *nl.NetlinkSocket, ch chan<- LinkUpdate) {
 	go func() {
 		defer close(ch)
 		for {
@@ -1365,8 +1891,6 @@ func linkSubscribe(newNs, curNs netns.NsHandle, ch chan<- LinkUpdate, done <-cha
 			}
 		}
 	}()
-
-	return nil
 }
 
 func LinkSetHairpin(link Link, mode bool) error {
@@ -1417,6 +1941,14 @@ func (h *Handle) LinkSetFlood(link Link, mode bool) error {
 	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_UNICAST_FLOOD)
 }
 
+func LinkSetMcastFlood(link Link, mode bool) error {
+	return pkgHandle.LinkSetMcastFlood(link, mode)
+}
+
+func (h *Handle) LinkSetMcastFlood(link Link, mode bool) error {
+	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_MCAST_FLOOD)
+}
+
 func LinkSetBrProxyArp(link Link, mode bool) error {
 	return pkgHandle.LinkSetBrProxyArp(link, mode)
 }
@@ -1433,7 +1965,39 @@ func (h *Handle) LinkSetBrProxyArpWiFi(link Link, mode bool) error {
 	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_PROXYARP_WIFI)
 }
 
+// LinkSetBrMcastRouter sets the multicast router mode of a bridge port to
+// one of the BRIDGE_MCAST_ROUTER_* modes.
+// Equivalent to: `bridge link set dev $link mcast_router $mode`
+func LinkSetBrMcastRouter(link Link, mode uint8) error {
+	return pkgHandle.LinkSetBrMcastRouter(link, mode)
+}
+
+// LinkSetBrMcastRouter sets the multicast router mode of a bridge port to
+// one of the BRIDGE_MCAST_ROUTER_* modes.
+// Equivalent to: `bridge link set dev $link mcast_router $mode`
+func (h *Handle) LinkSetBrMcastRouter(link Link, mode uint8) error {
+	return h.setProtinfoValue(link, []byte{mode}, nl.IFLA_BRPORT_MULTICAST_ROUTER)
+}
+
+// LinkSetBrVlanTunnel enables or disables the mapping of vlans to tunnel
+// ids on a bridge port, see BridgeVlanTunnelAdd.
+// Equivalent to: `bridge link set dev $link vlan_tunnel on|off`
+func LinkSetBrVlanTunnel(link Link, mode bool) error {
+	return pkgHandle.LinkSetBrVlanTunnel(link, mode)
+}
+
+// LinkSetBrVlanTunnel enables or disables the mapping of vlans to tunnel
+// ids on a bridge port, see BridgeVlanTunnelAdd.
+// Equivalent to: `bridge link set dev $link vlan_tunnel on|off`
+func (h *Handle) LinkSetBrVlanTunnel(link Link, mode bool) error {
+	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_VLAN_TUNNEL)
+}
+
 func (h *Handle) setProtinfoAttr(link Link, mode bool, attr int) error {
+	return h.setProtinfoValue(link, boolToByte(mode), attr)
+}
+
+func (h *Handle) setProtinfoValue(link Link, value []byte, attr int) error {
 	base := link.Attrs()
 	h.ensureIndex(base)
 	req := h.newNetlinkRequest(syscall.RTM_SETLINK, syscall.NLM_F_ACK)
@@ -1443,7 +2007,7 @@ func (h *Handle) setProtinfoAttr(link Link, mode bool, attr int) error {
 	req.AddData(msg)
 
 	br := nl.NewRtAttr(syscall.IFLA_PROTINFO|syscall.NLA_F_NESTED, nil)
-	nl.NewRtAttrChild(br, attr, boolToByte(mode))
+	nl.NewRtAttrChild(br, attr, value)
 	req.AddData(br)
 	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
 	if err != nil {
@@ -1452,6 +2016,173 @@ func (h *Handle) setProtinfoAttr(link Link, mode bool, attr int) error {
 	return nil
 }
 
+// LinkSetIP6AddrGenToken sets the interface identifier the kernel uses
+// to form SLAAC addresses for the link.
+// Equivalent to: `ip token set $token dev $link`
+func LinkSetIP6AddrGenToken(link Link, token net.IP) error {
+	return pkgHandle.LinkSetIP6AddrGenToken(link, token)
+}
+
+// LinkSetIP6AddrGenToken sets the interface identifier the kernel uses
+// to form SLAAC addresses for the link.
+// Equivalent to: `ip token set $token dev $link`
+func (h *Handle) LinkSetIP6AddrGenToken(link Link, token net.IP) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(syscall.RTM_SETLINK, syscall.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	afSpec := nl.NewRtAttr(nl.IFLA_AF_SPEC, nil)
+	inet6 := nl.NewRtAttrChild(afSpec, syscall.AF_INET6, nil)
+	nl.NewRtAttrChild(inet6, nl.IFLA_INET6_TOKEN, []byte(token.To16()))
+	req.AddData(afSpec)
+
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+// LinkSetIP6AddrGenMode sets how IPv6 addresses are generated for the link.
+// Equivalent to: `ip link set $link addrgenmode $mode`
+func LinkSetIP6AddrGenMode(link Link, mode IP6AddrGenMode) error {
+	return pkgHandle.LinkSetIP6AddrGenMode(link, mode)
+}
+
+// LinkSetIP6AddrGenMode sets how IPv6 addresses are generated for the link.
+// Equivalent to: `ip link set $link addrgenmode $mode`
+func (h *Handle) LinkSetIP6AddrGenMode(link Link, mode IP6AddrGenMode) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(syscall.RTM_SETLINK, syscall.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	afSpec := nl.NewRtAttr(nl.IFLA_AF_SPEC, nil)
+	inet6 := nl.NewRtAttrChild(afSpec, syscall.AF_INET6, nil)
+	nl.NewRtAttrChild(inet6, nl.IFLA_INET6_ADDR_GEN_MODE, nl.Uint8Attr(uint8(mode)))
+	req.AddData(afSpec)
+
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+// LinkSetLinkMode sets the link mode of the link.
+// Equivalent to: `ip link set $link mode $mode`
+func LinkSetLinkMode(link Link, mode LinkMode) error {
+	return pkgHandle.LinkSetLinkMode(link, mode)
+}
+
+// LinkSetLinkMode sets the link mode of the link.
+// Equivalent to: `ip link set $link mode $mode`
+func (h *Handle) LinkSetLinkMode(link Link, mode LinkMode) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(syscall.RTM_SETLINK, syscall.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+	req.AddData(nl.NewRtAttr(syscall.IFLA_LINKMODE, nl.Uint8Attr(uint8(mode))))
+
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+// rtnetlink has no attribute for threaded NAPI, so it is handled through
+// sysfs, which reflects the network namespace it was mounted in.
+func linkThreadedPath(link Link) string {
+	return filepath.Join("/sys/class/net", link.Attrs().Name, "threaded")
+}
+
+// LinkGetThreaded reads whether the link runs its NAPI poll in kernel
+// threads and stores the result in the link attributes. The link is
+// found by name in /sys/class/net, which shows the network namespace
+// sysfs was mounted in rather than the one of a Handle, so there is no
+// Handle variant.
+// Equivalent to: `cat /sys/class/net/$link/threaded`
+func LinkGetThreaded(link Link) (bool, error) {
+	data, err := ioutil.ReadFile(linkThreadedPath(link))
+	if err != nil {
+		return false, err
+	}
+	threaded := strings.TrimSpace(string(data)) != "0"
+	link.Attrs().Threaded = threaded
+	return threaded, nil
+}
+
+// LinkSetThreaded enables or disables threaded NAPI polling on the link.
+// Like LinkGetThreaded it goes through the sysfs of the calling process.
+// The kernel returns EOPNOTSUPP for links without NAPI instances.
+// Equivalent to: `echo $enable > /sys/class/net/$link/threaded`
+func LinkSetThreaded(link Link, enable bool) error {
+	val := "0"
+	if enable {
+		val = "1"
+	}
+	if err := ioutil.WriteFile(linkThreadedPath(link), []byte(val), 0644); err != nil {
+		return err
+	}
+	link.Attrs().Threaded = enable
+	return nil
+}
+
+// LinkStatsSnapshot returns the current statistics of the link, to be
+// passed to LinkStatsSince later. Most drivers cannot clear their
+// counters, a snapshot stands in for the clear.
+func LinkStatsSnapshot(link Link) (*LinkStatistics64, error) {
+	return pkgHandle.LinkStatsSnapshot(link)
+}
+
+// LinkStatsSnapshot returns the current statistics of the link, to be
+// passed to LinkStatsSince later. Most drivers cannot clear their
+// counters, a snapshot stands in for the clear.
+func (h *Handle) LinkStatsSnapshot(link Link) (*LinkStatistics64, error) {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	current, err := h.LinkByIndex(base.Index)
+	if err != nil {
+		return nil, err
+	}
+	stats := current.Attrs().Statistics
+	if stats == nil {
+		return nil, fmt.Errorf("link %s reports no statistics", base.Name)
+	}
+	return (*LinkStatistics64)(stats), nil
+}
+
+// LinkStatsSince returns how much the counters of the link went up since
+// the snapshot taken by LinkStatsSnapshot.
+func LinkStatsSince(link Link, snapshot *LinkStatistics64) (LinkStatistics64, error) {
+	return pkgHandle.LinkStatsSince(link, snapshot)
+}
+
+// LinkStatsSince returns how much the counters of the link went up since
+// the snapshot taken by LinkStatsSnapshot.
+func (h *Handle) LinkStatsSince(link Link, snapshot *LinkStatistics64) (LinkStatistics64, error) {
+	current, err := h.LinkStatsSnapshot(link)
+	if err != nil {
+		return LinkStatistics64{}, err
+	}
+	return LinkStatsDelta(snapshot, current), nil
+}
+
+func parseInet6AfSpec(base *LinkAttrs, data []syscall.NetlinkRouteAttr) {
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.IFLA_INET6_TOKEN:
+			if token := net.IP(datum.Value); !token.Equal(net.IPv6zero) {
+				base.IP6AddrGenToken = token
+			}
+		case nl.IFLA_INET6_ADDR_GEN_MODE:
+			base.IP6AddrGenMode = IP6AddrGenMode(datum.Value[0])
+		}
+	}
+}
+
 // LinkSetTxQLen sets the transaction queue length for the link.
 // Equivalent to: `ip link set $link txqlen $qlen`
 func LinkSetTxQLen(link Link, qlen int) error {
@@ -1479,6 +2210,117 @@ func (h *Handle) LinkSetTxQLen(link Link, qlen int) error {
 	return err
 }
 
+// LinkSetGroup sets the interface group of the link.
+// Equivalent to: `ip link set $link group $group`
+func LinkSetGroup(link Link, group uint32) error {
+	return pkgHandle.LinkSetGroup(link, group)
+}
+
+// LinkSetGroup sets the interface group of the link.
+// Equivalent to: `ip link set $link group $group`
+func (h *Handle) LinkSetGroup(link Link, group uint32) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(syscall.RTM_SETLINK, syscall.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	data := nl.NewRtAttr(nl.IFLA_GROUP, nl.Uint32Attr(group))
+	req.AddData(data)
+
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+// MacvlanSetBroadcast sets the broadcast queue length requested by the
+// macvlan link and the broadcast cutoff of its port in one request.
+// Equivalent to: `ip link set $link type macvlan bcqueuelen $queueLen bc_cutoff $cutoff`
+func MacvlanSetBroadcast(link Link, queueLen uint32, cutoff int32) error {
+	return pkgHandle.MacvlanSetBroadcast(link, queueLen, cutoff)
+}
+
+// MacvlanSetBroadcast sets the broadcast queue length requested by the
+// macvlan link and the broadcast cutoff of its port in one request.
+// Equivalent to: `ip link set $link type macvlan bcqueuelen $queueLen bc_cutoff $cutoff`
+func (h *Handle) MacvlanSetBroadcast(link Link, queueLen uint32, cutoff int32) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	// RTM_SETLINK ignores the info data, changes to it go through
+	// RTM_NEWLINK on the existing link
+	req := h.newNetlinkRequest(syscall.RTM_NEWLINK, syscall.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	linkInfo := nl.NewRtAttr(syscall.IFLA_LINKINFO, nil)
+	nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_KIND, nl.NonZeroTerminated(link.Type()))
+	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
+	nl.NewRtAttrChild(data, nl.IFLA_MACVLAN_BC_QUEUE_LEN, nl.Uint32Attr(queueLen))
+	nl.NewRtAttrChild(data, nl.IFLA_MACVLAN_BC_CUTOFF, nl.Uint32Attr(uint32(cutoff)))
+	req.AddData(linkInfo)
+
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+// MacvlanGetBroadcast returns the broadcast queue length used by the port
+// of the macvlan link and its broadcast cutoff.
+func MacvlanGetBroadcast(link Link) (uint32, int32, error) {
+	return pkgHandle.MacvlanGetBroadcast(link)
+}
+
+// MacvlanGetBroadcast returns the broadcast queue length used by the port
+// of the macvlan link and its broadcast cutoff.
+func (h *Handle) MacvlanGetBroadcast(link Link) (uint32, int32, error) {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	l, err := h.LinkByIndex(base.Index)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch macv := l.(type) {
+	case *Macvlan:
+		return macv.UsedBCQueueLen, macv.BCCutoff, nil
+	case *Macvtap:
+		return macv.UsedBCQueueLen, macv.BCCutoff, nil
+	}
+	return 0, 0, fmt.Errorf("link %s is not a macvlan", base.Name)
+}
+
+// LinkSetGroupState brings all the links of the interface group up or
+// down in one request. The kernel only applies a request to a whole
+// group as RTM_NEWLINK without an index or a name, RTM_SETLINK needs
+// a single link.
+// Equivalent to: `ip link set group $group up|down`
+func LinkSetGroupState(group uint32, up bool) error {
+	return pkgHandle.LinkSetGroupState(group, up)
+}
+
+// LinkSetGroupState brings all the links of the interface group up or
+// down in one request. The kernel only applies a request to a whole
+// group as RTM_NEWLINK without an index or a name, RTM_SETLINK needs
+// a single link.
+// Equivalent to: `ip link set group $group up|down`
+func (h *Handle) LinkSetGroupState(group uint32, up bool) error {
+	req := h.newNetlinkRequest(syscall.RTM_NEWLINK, syscall.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Change = syscall.IFF_UP
+	if up {
+		msg.Flags = syscall.IFF_UP
+	}
+	req.AddData(msg)
+
+	data := nl.NewRtAttr(nl.IFLA_GROUP, nl.Uint32Attr(group))
+	req.AddData(data)
+
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
 func parseVlanData(link Link, data []syscall.NetlinkRouteAttr) {
 	vlan := link.(*Vlan)
 	for _, datum := range data {
@@ -1543,6 +2385,48 @@ func parseVxlanData(link Link, data []syscall.NetlinkRouteAttr) {
 	}
 }
 
+func parseLinkSlave(kind string, data []syscall.NetlinkRouteAttr) LinkSlave {
+	switch kind {
+	case "bond":
+		return parseBondSlaveData(data)
+	case "bridge":
+		return &BridgeSlave{Protinfo: *parseProtinfo(data)}
+	case "team":
+		return &TeamSlave{}
+	case "vrf":
+		slave := &VrfSlave{}
+		for _, datum := range data {
+			if datum.Attr.Type == nl.IFLA_VRF_PORT_TABLE {
+				slave.Table = native.Uint32(datum.Value[0:4])
+			}
+		}
+		return slave
+	default:
+		return &GenericSlave{MasterKind: kind}
+	}
+}
+
+func parseBondSlaveData(data []syscall.NetlinkRouteAttr) *BondSlave {
+	slave := &BondSlave{}
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.IFLA_BOND_SLAVE_STATE:
+			slave.State = BondSlaveState(datum.Value[0])
+		case nl.IFLA_BOND_SLAVE_MII_STATUS:
+			slave.MiiStatus = BondSlaveMiiStatus(datum.Value[0])
+		case nl.IFLA_BOND_SLAVE_LINK_FAILURE_COUNT:
+			slave.LinkFailureCount = native.Uint32(datum.Value[0:4])
+		case nl.IFLA_BOND_SLAVE_PERM_HWADDR:
+			slave.PermHardwareAddr = net.HardwareAddr(datum.Value)
+		case nl.IFLA_BOND_SLAVE_QUEUE_ID:
+			slave.QueueId = native.Uint16(datum.Value[0:2])
+		case nl.IFLA_BOND_SLAVE_AD_AGGREGATOR_ID:
+			slave.AggregatorId = native.Uint16(datum.Value[0:2])
+		}
+	}
+	return slave
+}
+
 func parseBondData(link Link, data []syscall.NetlinkRouteAttr) {
 	bond := link.(*Bond)
 	for i := range data {
@@ -1623,7 +2507,8 @@ func parseMacvtapData(link Link, data []syscall.NetlinkRouteAttr) {
 func parseMacvlanData(link Link, data []syscall.NetlinkRouteAttr) {
 	macv := link.(*Macvlan)
 	for _, datum := range data {
-		if datum.Attr.Type == nl.IFLA_MACVLAN_MODE {
+		switch datum.Attr.Type {
+		case nl.IFLA_MACVLAN_MODE:
 			switch native.Uint32(datum.Value[0:4]) {
 			case nl.MACVLAN_MODE_PRIVATE:
 				macv.Mode = MACVLAN_MODE_PRIVATE
@@ -1636,7 +2521,12 @@ func parseMacvlanData(link Link, data []syscall.NetlinkRouteAttr) {
 			case nl.MACVLAN_MODE_SOURCE:
 				macv.Mode = MACVLAN_MODE_SOURCE
 			}
-			return
+		case nl.IFLA_MACVLAN_BC_QUEUE_LEN:
+			macv.BCQueueLen = native.Uint32(datum.Value[0:4])
+		case nl.IFLA_MACVLAN_BC_QUEUE_LEN_USED:
+			macv.UsedBCQueueLen = native.Uint32(datum.Value[0:4])
+		case nl.IFLA_MACVLAN_BC_CUTOFF:
+			macv.BCCutoff = int32(native.Uint32(datum.Value[0:4]))
 		}
 	}
 }
@@ -1743,6 +2633,84 @@ func parseGretapData(link Link, data []syscall.NetlinkRouteAttr) {
 	}
 }
 
+func addErspanAttrs(erspan *Erspan, linkInfo *nl.RtAttr) {
+	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
+
+	ip := erspan.Local.To4()
+	if ip != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_GRE_LOCAL, []byte(ip))
+	}
+	ip = erspan.Remote.To4()
+	if ip != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_GRE_REMOTE, []byte(ip))
+	}
+
+	if erspan.IKey != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_GRE_IKEY, htonl(erspan.IKey))
+		erspan.IFlags |= uint16(nl.GRE_KEY)
+	}
+
+	if erspan.OKey != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_GRE_OKEY, htonl(erspan.OKey))
+		erspan.OFlags |= uint16(nl.GRE_KEY)
+	}
+
+	nl.NewRtAttrChild(data, nl.IFLA_GRE_IFLAGS, htons(erspan.IFlags))
+	nl.NewRtAttrChild(data, nl.IFLA_GRE_OFLAGS, htons(erspan.OFlags))
+
+	if erspan.Link != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_GRE_LINK, nl.Uint32Attr(erspan.Link))
+	}
+
+	nl.NewRtAttrChild(data, nl.IFLA_GRE_TTL, nl.Uint8Attr(erspan.Ttl))
+	nl.NewRtAttrChild(data, nl.IFLA_GRE_TOS, nl.Uint8Attr(erspan.Tos))
+
+	if erspan.ErspanVer != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_GRE_ERSPAN_VER, nl.Uint8Attr(erspan.ErspanVer))
+	}
+	switch erspan.ErspanVer {
+	case 1:
+		nl.NewRtAttrChild(data, nl.IFLA_GRE_ERSPAN_INDEX, nl.Uint32Attr(erspan.ErspanIndex))
+	case 2:
+		nl.NewRtAttrChild(data, nl.IFLA_GRE_ERSPAN_DIR, nl.Uint8Attr(erspan.ErspanDir))
+		nl.NewRtAttrChild(data, nl.IFLA_GRE_ERSPAN_HWID, nl.Uint16Attr(erspan.ErspanHwId))
+	}
+}
+
+func parseErspanData(link Link, data []syscall.NetlinkRouteAttr) {
+	erspan := link.(*Erspan)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.IFLA_GRE_OKEY:
+			erspan.OKey = ntohl(datum.Value[0:4])
+		case nl.IFLA_GRE_IKEY:
+			erspan.IKey = ntohl(datum.Value[0:4])
+		case nl.IFLA_GRE_LOCAL:
+			erspan.Local = net.IP(datum.Value[0:4])
+		case nl.IFLA_GRE_REMOTE:
+			erspan.Remote = net.IP(datum.Value[0:4])
+		case nl.IFLA_GRE_IFLAGS:
+			erspan.IFlags = ntohs(datum.Value[0:2])
+		case nl.IFLA_GRE_OFLAGS:
+			erspan.OFlags = ntohs(datum.Value[0:2])
+		case nl.IFLA_GRE_LINK:
+			erspan.Link = native.Uint32(datum.Value[0:4])
+		case nl.IFLA_GRE_TTL:
+			erspan.Ttl = uint8(datum.Value[0])
+		case nl.IFLA_GRE_TOS:
+			erspan.Tos = uint8(datum.Value[0])
+		case nl.IFLA_GRE_ERSPAN_VER:
+			erspan.ErspanVer = uint8(datum.Value[0])
+		case nl.IFLA_GRE_ERSPAN_INDEX:
+			erspan.ErspanIndex = native.Uint32(datum.Value[0:4])
+		case nl.IFLA_GRE_ERSPAN_DIR:
+			erspan.ErspanDir = uint8(datum.Value[0])
+		case nl.IFLA_GRE_ERSPAN_HWID:
+			erspan.ErspanHwId = native.Uint16(datum.Value[0:2])
+		}
+	}
+}
+
 func addGretunAttrs(gre *Gretun, linkInfo *nl.RtAttr) {
 	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
 
@@ -1814,12 +2782,9 @@ func parseLinkStats64(data []byte) *LinkStatistics {
 
 func addXdpAttrs(xdp *LinkXdp, req *nl.NetlinkRequest) {
 	attrs := nl.NewRtAttr(nl.IFLA_XDP|syscall.NLA_F_NESTED, nil)
-	b := make([]byte, 4)
-	native.PutUint32(b, uint32(xdp.Fd))
-	nl.NewRtAttrChild(attrs, nl.IFLA_XDP_FD, b)
+	nl.NewRtAttrChild(attrs, nl.IFLA_XDP_FD, nl.Uint32Attr(uint32(xdp.Fd)))
 	if xdp.Flags != 0 {
-		native.PutUint32(b, xdp.Flags)
-		nl.NewRtAttrChild(attrs, nl.IFLA_XDP_FLAGS, b)
+		nl.NewRtAttrChild(attrs, nl.IFLA_XDP_FLAGS, nl.Uint32Attr(xdp.Flags))
 	}
 	req.AddData(attrs)
 }
@@ -1835,11 +2800,18 @@ func parseLinkXdp(data []byte) (*LinkXdp, error) {
 		case nl.IFLA_XDP_FD:
 			xdp.Fd = int(native.Uint32(attr.Value[0:4]))
 		case nl.IFLA_XDP_ATTACHED:
-			xdp.Attached = attr.Value[0] != 0
+			xdp.AttachMode = attr.Value[0]
+			xdp.Attached = attr.Value[0] != nl.XDP_ATTACHED_NONE
 		case nl.IFLA_XDP_FLAGS:
 			xdp.Flags = native.Uint32(attr.Value[0:4])
 		case nl.IFLA_XDP_PROG_ID:
 			xdp.ProgId = native.Uint32(attr.Value[0:4])
+		case nl.IFLA_XDP_DRV_PROG_ID:
+			xdp.DrvProgId = native.Uint32(attr.Value[0:4])
+		case nl.IFLA_XDP_SKB_PROG_ID:
+			xdp.SkbProgId = native.Uint32(attr.Value[0:4])
+		case nl.IFLA_XDP_HW_PROG_ID:
+			xdp.HwProgId = native.Uint32(attr.Value[0:4])
 		}
 	}
 	return xdp, nil
@@ -1884,6 +2856,128 @@ func parseIptunData(link Link, data []syscall.NetlinkRouteAttr) {
 	}
 }
 
+func addSittunAttrs(sittun *Sittun, linkInfo *nl.RtAttr) {
+	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
+
+	ip := sittun.Local.To4()
+	if ip != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_IPTUN_LOCAL, []byte(ip))
+	}
+
+	ip = sittun.Remote.To4()
+	if ip != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_IPTUN_REMOTE, []byte(ip))
+	}
+
+	if sittun.Link != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_IPTUN_LINK, nl.Uint32Attr(sittun.Link))
+	}
+	nl.NewRtAttrChild(data, nl.IFLA_IPTUN_PMTUDISC, nl.Uint8Attr(sittun.PMtuDisc))
+	nl.NewRtAttrChild(data, nl.IFLA_IPTUN_TTL, nl.Uint8Attr(sittun.Ttl))
+	nl.NewRtAttrChild(data, nl.IFLA_IPTUN_TOS, nl.Uint8Attr(sittun.Tos))
+
+	if sittun.SixRDPrefix != nil {
+		ones, _ := sittun.SixRDPrefix.Mask.Size()
+		nl.NewRtAttrChild(data, nl.IFLA_IPTUN_6RD_PREFIX, []byte(sittun.SixRDPrefix.IP.To16()))
+		nl.NewRtAttrChild(data, nl.IFLA_IPTUN_6RD_PREFIXLEN, nl.Uint16Attr(uint16(ones)))
+	}
+	if sittun.SixRDRelayPrefix != nil {
+		ones, _ := sittun.SixRDRelayPrefix.Mask.Size()
+		nl.NewRtAttrChild(data, nl.IFLA_IPTUN_6RD_RELAY_PREFIX, []byte(sittun.SixRDRelayPrefix.IP.To4()))
+		nl.NewRtAttrChild(data, nl.IFLA_IPTUN_6RD_RELAY_PREFIXLEN, nl.Uint16Attr(uint16(ones)))
+	}
+}
+
+func parseSittunData(link Link, data []syscall.NetlinkRouteAttr) {
+	sittun := link.(*Sittun)
+	var (
+		prefix, relayPrefix       net.IP
+		prefixLen, relayPrefixLen int
+	)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.IFLA_IPTUN_LOCAL:
+			sittun.Local = net.IP(datum.Value[0:4])
+		case nl.IFLA_IPTUN_REMOTE:
+			sittun.Remote = net.IP(datum.Value[0:4])
+		case nl.IFLA_IPTUN_LINK:
+			sittun.Link = native.Uint32(datum.Value[0:4])
+		case nl.IFLA_IPTUN_TTL:
+			sittun.Ttl = uint8(datum.Value[0])
+		case nl.IFLA_IPTUN_TOS:
+			sittun.Tos = uint8(datum.Value[0])
+		case nl.IFLA_IPTUN_PMTUDISC:
+			sittun.PMtuDisc = uint8(datum.Value[0])
+		case nl.IFLA_IPTUN_6RD_PREFIX:
+			prefix = net.IP(datum.Value[0:16])
+		case nl.IFLA_IPTUN_6RD_PREFIXLEN:
+			prefixLen = int(native.Uint16(datum.Value[0:2]))
+		case nl.IFLA_IPTUN_6RD_RELAY_PREFIX:
+			relayPrefix = net.IP(datum.Value[0:4])
+		case nl.IFLA_IPTUN_6RD_RELAY_PREFIXLEN:
+			relayPrefixLen = int(native.Uint16(datum.Value[0:2]))
+		}
+	}
+	// the kernel always reports the 6rd parameters, a zero prefix
+	// length meaning that 6rd is not in use
+	if prefix != nil && prefixLen != 0 {
+		sittun.SixRDPrefix = &net.IPNet{IP: prefix, Mask: net.CIDRMask(prefixLen, 128)}
+	}
+	if relayPrefix != nil && relayPrefixLen != 0 {
+		sittun.SixRDRelayPrefix = &net.IPNet{IP: relayPrefix, Mask: net.CIDRMask(relayPrefixLen, 32)}
+	}
+}
+
+func addIp6tnlAttrs(ip6tnl *Ip6tnl, linkInfo *nl.RtAttr) {
+	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
+
+	if ip6tnl.Link != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_IPTUN_LINK, nl.Uint32Attr(ip6tnl.Link))
+	}
+
+	ip := ip6tnl.Local.To16()
+	if ip != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_IPTUN_LOCAL, []byte(ip))
+	}
+
+	ip = ip6tnl.Remote.To16()
+	if ip != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_IPTUN_REMOTE, []byte(ip))
+	}
+
+	nl.NewRtAttrChild(data, nl.IFLA_IPTUN_TTL, nl.Uint8Attr(ip6tnl.Ttl))
+	if ip6tnl.EncapLimit != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_IPTUN_ENCAP_LIMIT, nl.Uint8Attr(ip6tnl.EncapLimit))
+	}
+	nl.NewRtAttrChild(data, nl.IFLA_IPTUN_FLOWINFO, htonl(ip6tnl.FlowInfo))
+	nl.NewRtAttrChild(data, nl.IFLA_IPTUN_FLAGS, nl.Uint32Attr(ip6tnl.Flags))
+	nl.NewRtAttrChild(data, nl.IFLA_IPTUN_PROTO, nl.Uint8Attr(ip6tnl.Proto))
+}
+
+func parseIp6tnlData(link Link, data []syscall.NetlinkRouteAttr) {
+	ip6tnl := link.(*Ip6tnl)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.IFLA_IPTUN_LINK:
+			ip6tnl.Link = native.Uint32(datum.Value[0:4])
+		case nl.IFLA_IPTUN_LOCAL:
+			ip6tnl.Local = net.IP(datum.Value[0:16])
+		case nl.IFLA_IPTUN_REMOTE:
+			ip6tnl.Remote = net.IP(datum.Value[0:16])
+		case nl.IFLA_IPTUN_TTL:
+			ip6tnl.Ttl = uint8(datum.Value[0])
+		case nl.IFLA_IPTUN_ENCAP_LIMIT:
+			ip6tnl.EncapLimit = uint8(datum.Value[0])
+		case nl.IFLA_IPTUN_FLOWINFO:
+			ip6tnl.FlowInfo = ntohl(datum.Value[0:4])
+		case nl.IFLA_IPTUN_FLAGS:
+			ip6tnl.Flags = native.Uint32(datum.Value[0:4])
+		case nl.IFLA_IPTUN_PROTO:
+			ip6tnl.Proto = uint8(datum.Value[0])
+		}
+	}
+}
+
 func addVtiAttrs(vti *Vti, linkInfo *nl.RtAttr) {
 	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
 
@@ -1946,6 +3040,27 @@ func addBridgeAttrs(bridge *Bridge, linkInfo *nl.RtAttr) {
 	if bridge.HelloTime != nil {
 		nl.NewRtAttrChild(data, nl.IFLA_BR_HELLO_TIME, nl.Uint32Attr(*bridge.HelloTime))
 	}
+	if bridge.ForwardDelay != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_BR_FORWARD_DELAY, nl.Uint32Attr(*bridge.ForwardDelay))
+	}
+	if bridge.MaxAge != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_BR_MAX_AGE, nl.Uint32Attr(*bridge.MaxAge))
+	}
+	if bridge.AgeingTime != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_BR_AGEING_TIME, nl.Uint32Attr(*bridge.AgeingTime))
+	}
+	if bridge.StpState != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_BR_STP_STATE, nl.Uint32Attr(*bridge.StpState))
+	}
+	if bridge.VlanFiltering != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_BR_VLAN_FILTERING, boolToByte(*bridge.VlanFiltering))
+	}
+	if bridge.MulticastQuerier != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_BR_MCAST_QUERIER, boolToByte(*bridge.MulticastQuerier))
+	}
+	if bridge.MulticastRouter != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_BR_MCAST_ROUTER, []byte{*bridge.MulticastRouter})
+	}
 }
 
 func parseBridgeData(bridge Link, data []syscall.NetlinkRouteAttr) {
@@ -1958,10 +3073,83 @@ func parseBridgeData(bridge Link, data []syscall.NetlinkRouteAttr) {
 		case nl.IFLA_BR_MCAST_SNOOPING:
 			mcastSnooping := datum.Value[0] == 1
 			br.MulticastSnooping = &mcastSnooping
+		case nl.IFLA_BR_FORWARD_DELAY:
+			forwardDelay := native.Uint32(datum.Value[0:4])
+			br.ForwardDelay = &forwardDelay
+		case nl.IFLA_BR_MAX_AGE:
+			maxAge := native.Uint32(datum.Value[0:4])
+			br.MaxAge = &maxAge
+		case nl.IFLA_BR_AGEING_TIME:
+			ageingTime := native.Uint32(datum.Value[0:4])
+			br.AgeingTime = &ageingTime
+		case nl.IFLA_BR_STP_STATE:
+			stpState := native.Uint32(datum.Value[0:4])
+			br.StpState = &stpState
+		case nl.IFLA_BR_VLAN_FILTERING:
+			vlanFiltering := datum.Value[0] == 1
+			br.VlanFiltering = &vlanFiltering
+		case nl.IFLA_BR_MCAST_QUERIER:
+			mcastQuerier := datum.Value[0] == 1
+			br.MulticastQuerier = &mcastQuerier
+		case nl.IFLA_BR_MCAST_ROUTER:
+			mcastRouter := datum.Value[0]
+			br.MulticastRouter = &mcastRouter
+		}
+	}
+}
+
+func addNetkitAttrs(netkit *Netkit, linkInfo *nl.RtAttr) {
+	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
+	nl.NewRtAttrChild(data, nl.IFLA_NETKIT_MODE, nl.Uint32Attr(uint32(netkit.Mode)))
+	nl.NewRtAttrChild(data, nl.IFLA_NETKIT_POLICY, nl.Uint32Attr(uint32(netkit.Policy)))
+	nl.NewRtAttrChild(data, nl.IFLA_NETKIT_PEER_POLICY, nl.Uint32Attr(uint32(netkit.PeerPolicy)))
+	if netkit.PeerName != "" {
+		peer := nl.NewRtAttrChild(data, nl.IFLA_NETKIT_PEER_INFO, nil)
+		nl.NewIfInfomsgChild(peer, syscall.AF_UNSPEC)
+		nl.NewRtAttrChild(peer, syscall.IFLA_IFNAME, nl.ZeroTerminated(netkit.PeerName))
+	}
+}
+
+func parseNetkitData(link Link, data []syscall.NetlinkRouteAttr) {
+	netkit := link.(*Netkit)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.IFLA_NETKIT_PRIMARY:
+			netkit.Primary = datum.Value[0] != 0
+		case nl.IFLA_NETKIT_MODE:
+			netkit.Mode = NetkitMode(native.Uint32(datum.Value[0:4]))
+		case nl.IFLA_NETKIT_POLICY:
+			netkit.Policy = NetkitPolicy(native.Uint32(datum.Value[0:4]))
+		case nl.IFLA_NETKIT_PEER_POLICY:
+			netkit.PeerPolicy = NetkitPolicy(native.Uint32(datum.Value[0:4]))
 		}
 	}
 }
 
+func addGenericAttrs(generic *GenericLink, linkInfo *nl.RtAttr) {
+	if len(generic.Data) == 0 {
+		return
+	}
+	types := make([]int, 0, len(generic.Data))
+	for t := range generic.Data {
+		types = append(types, int(t))
+	}
+	sort.Ints(types)
+
+	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
+	for _, t := range types {
+		nl.NewRtAttrChild(data, t, generic.Data[uint16(t)])
+	}
+}
+
+func parseGenericData(link Link, data []syscall.NetlinkRouteAttr) {
+	generic := link.(*GenericLink)
+	generic.Data = make(map[uint16][]byte, len(data))
+	for _, datum := range data {
+		generic.Data[datum.Attr.Type] = datum.Value
+	}
+}
+
 func addGTPAttrs(gtp *GTP, linkInfo *nl.RtAttr) {
 	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
 	nl.NewRtAttrChild(data, nl.IFLA_GTP_FD0, nl.Uint32Attr(uint32(gtp.FD0)))
@@ -1987,3 +3175,111 @@ func parseGTPData(link Link, data []syscall.NetlinkRouteAttr) {
 		}
 	}
 }
+
+func addHsrAttrs(hsr *Hsr, linkInfo *nl.RtAttr) {
+	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
+	nl.NewRtAttrChild(data, nl.IFLA_HSR_SLAVE1, nl.Uint32Attr(hsr.Slave1))
+	nl.NewRtAttrChild(data, nl.IFLA_HSR_SLAVE2, nl.Uint32Attr(hsr.Slave2))
+	if hsr.MulticastSpec != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_HSR_MULTICAST_SPEC, nl.Uint8Attr(hsr.MulticastSpec))
+	}
+	// the kernel refuses a version for PRP
+	if hsr.Protocol == HSR_PROTOCOL_HSR {
+		nl.NewRtAttrChild(data, nl.IFLA_HSR_VERSION, nl.Uint8Attr(hsr.Version))
+	} else {
+		nl.NewRtAttrChild(data, nl.IFLA_HSR_PROTOCOL, nl.Uint8Attr(hsr.Protocol))
+	}
+}
+
+func parseHsrData(link Link, data []syscall.NetlinkRouteAttr) {
+	hsr := link.(*Hsr)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.IFLA_HSR_SLAVE1:
+			hsr.Slave1 = native.Uint32(datum.Value[0:4])
+		case nl.IFLA_HSR_SLAVE2:
+			hsr.Slave2 = native.Uint32(datum.Value[0:4])
+		case nl.IFLA_HSR_SUPERVISION_ADDR:
+			hsr.SupervisionAddr = net.HardwareAddr(datum.Value[0:6])
+		case nl.IFLA_HSR_SEQ_NR:
+			hsr.SeqNr = native.Uint16(datum.Value[0:2])
+		case nl.IFLA_HSR_PROTOCOL:
+			hsr.Protocol = uint8(datum.Value[0])
+		}
+	}
+}
+
+func addIpoibAttrs(ipoib *Ipoib, linkInfo *nl.RtAttr) {
+	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
+	if ipoib.Pkey != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_IPOIB_PKEY, nl.Uint16Attr(ipoib.Pkey))
+	}
+	nl.NewRtAttrChild(data, nl.IFLA_IPOIB_MODE, nl.Uint16Attr(uint16(ipoib.Mode)))
+	nl.NewRtAttrChild(data, nl.IFLA_IPOIB_UMCAST, nl.Uint16Attr(ipoib.Umcast))
+}
+
+func addAmtAttrs(amt *Amt, linkInfo *nl.RtAttr) {
+	data := nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_DATA, nil)
+	nl.NewRtAttrChild(data, nl.IFLA_AMT_MODE, nl.Uint32Attr(uint32(amt.Mode)))
+	if amt.ParentIndex != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_AMT_LINK, nl.Uint32Attr(uint32(amt.ParentIndex)))
+	}
+	if amt.RelayPort != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_AMT_RELAY_PORT, htons(amt.RelayPort))
+	}
+	if amt.GatewayPort != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_AMT_GATEWAY_PORT, htons(amt.GatewayPort))
+	}
+	if ip := amt.LocalIP.To4(); ip != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_AMT_LOCAL_IP, []byte(ip))
+	}
+	if ip := amt.RemoteIP.To4(); ip != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_AMT_REMOTE_IP, []byte(ip))
+	}
+	if ip := amt.DiscoveryIP.To4(); ip != nil {
+		nl.NewRtAttrChild(data, nl.IFLA_AMT_DISCOVERY_IP, []byte(ip))
+	}
+	if amt.MaxTunnels != 0 {
+		nl.NewRtAttrChild(data, nl.IFLA_AMT_MAX_TUNNELS, nl.Uint32Attr(amt.MaxTunnels))
+	}
+}
+
+// the link of the amt is reported in IFLA_AMT_LINK only, base is the
+// LinkAttrs the amt ends up with
+func parseAmtData(link Link, data []syscall.NetlinkRouteAttr, base *LinkAttrs) {
+	amt := link.(*Amt)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.IFLA_AMT_MODE:
+			amt.Mode = AmtMode(native.Uint32(datum.Value[0:4]))
+		case nl.IFLA_AMT_LINK:
+			base.ParentIndex = int(native.Uint32(datum.Value[0:4]))
+		case nl.IFLA_AMT_RELAY_PORT:
+			amt.RelayPort = ntohs(datum.Value[0:2])
+		case nl.IFLA_AMT_GATEWAY_PORT:
+			amt.GatewayPort = ntohs(datum.Value[0:2])
+		case nl.IFLA_AMT_LOCAL_IP:
+			amt.LocalIP = net.IP(datum.Value[0:4])
+		case nl.IFLA_AMT_REMOTE_IP:
+			amt.RemoteIP = net.IP(datum.Value[0:4])
+		case nl.IFLA_AMT_DISCOVERY_IP:
+			amt.DiscoveryIP = net.IP(datum.Value[0:4])
+		case nl.IFLA_AMT_MAX_TUNNELS:
+			amt.MaxTunnels = native.Uint32(datum.Value[0:4])
+		}
+	}
+}
+
+func parseIpoibData(link Link, data []syscall.NetlinkRouteAttr) {
+	ipoib := link.(*Ipoib)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.IFLA_IPOIB_PKEY:
+			ipoib.Pkey = native.Uint16(datum.Value[0:2])
+		case nl.IFLA_IPOIB_MODE:
+			ipoib.Mode = IPoIBMode(native.Uint16(datum.Value[0:2]))
+		case nl.IFLA_IPOIB_UMCAST:
+			ipoib.Umcast = native.Uint16(datum.Value[0:2])
+		}
+	}
+}
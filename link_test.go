@@ -4,8 +4,15 @@ package netlink
 
 import (
 	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
 	"net"
 	"os"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -274,6 +281,154 @@ func TestLinkAddDelIfb(t *testing.T) {
 	testLinkAddDel(t, &Ifb{LinkAttrs{Name: "foo"}})
 }
 
+func TestLinkSetUpIfb(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	for _, name := range []string{"foo", "bar"} {
+		if err := LinkAdd(&Ifb{LinkAttrs{Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+		link, err := LinkByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := link.(*Ifb); !ok {
+			t.Fatalf("Link %s is %T, expected *Ifb", name, link)
+		}
+		if err := LinkSetUp(link); err != nil {
+			t.Fatal(err)
+		}
+		link, err = LinkByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link.Attrs().Flags&net.FlagUp == 0 {
+			t.Fatalf("Link %s is not up", name)
+		}
+	}
+}
+
+func TestLinkAddDelNlmon(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	nlmon := &Nlmon{LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(nlmon); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(nlmon); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := link.(*Nlmon); !ok {
+		t.Fatalf("Link is %T, expected *Nlmon", link)
+	}
+	if link.Attrs().Flags&net.FlagUp == 0 {
+		t.Fatal("Link not brought up")
+	}
+
+	if err := LinkDel(link); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLinkAddNetkit(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	netkit := &Netkit{
+		LinkAttrs:  LinkAttrs{Name: "foo"},
+		Mode:       NETKIT_MODE_L3,
+		Policy:     NETKIT_POLICY_FORWARD,
+		PeerPolicy: NETKIT_POLICY_BLACKHOLE,
+		PeerName:   "bar",
+	}
+	if err := LinkAdd(netkit); err != nil {
+		if err == syscall.EOPNOTSUPP {
+			t.Skip("Kernel does not support netkit")
+		}
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary, ok := link.(*Netkit)
+	if !ok {
+		t.Fatalf("Link is %T, expected *Netkit", link)
+	}
+	if !primary.Primary {
+		t.Fatal("foo should be the primary device")
+	}
+	if primary.Mode != NETKIT_MODE_L3 {
+		t.Fatalf("Mode is %d, expected %d", primary.Mode, NETKIT_MODE_L3)
+	}
+	if primary.Policy != NETKIT_POLICY_FORWARD {
+		t.Fatalf("Policy is %d, expected %d", primary.Policy, NETKIT_POLICY_FORWARD)
+	}
+	if primary.PeerPolicy != NETKIT_POLICY_BLACKHOLE {
+		t.Fatalf("PeerPolicy is %d, expected %d", primary.PeerPolicy, NETKIT_POLICY_BLACKHOLE)
+	}
+
+	link, err = LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer, ok := link.(*Netkit)
+	if !ok {
+		t.Fatalf("Peer is %T, expected *Netkit", link)
+	}
+	if peer.Primary {
+		t.Fatal("bar should not be the primary device")
+	}
+
+	if err := LinkDel(primary); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLinkAddDelVirtWifi(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	parent := &Dummy{LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(parent); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(parent); err != nil {
+		t.Fatal(err)
+	}
+
+	virtWifi := &VirtWifi{LinkAttrs{Name: "bar", ParentIndex: parent.Attrs().Index}}
+	if err := LinkAdd(virtWifi); err != nil {
+		if err == syscall.EOPNOTSUPP {
+			t.Skip("Kernel does not support virt_wifi links")
+		}
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := link.(*VirtWifi); !ok {
+		t.Fatalf("Link is %T, expected *VirtWifi", link)
+	}
+	if link.Attrs().ParentIndex != parent.Attrs().Index {
+		t.Fatalf("Parent index is %d, should be %d", link.Attrs().ParentIndex, parent.Attrs().Index)
+	}
+
+	if err := LinkDel(link); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestLinkAddDelBridge(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -373,6 +528,42 @@ func TestLinkAddDelMacvlan(t *testing.T) {
 	}
 }
 
+func TestMacvlanSetBroadcast(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	parent := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "baz"}
+	if err := LinkAdd(parent); err != nil {
+		t.Fatal(err)
+	}
+	macvlan := &Macvlan{
+		LinkAttrs: LinkAttrs{Name: "bar", ParentIndex: parent.Attrs().Index},
+		Mode:      MACVLAN_MODE_BRIDGE,
+	}
+	if err := LinkAdd(macvlan); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MacvlanSetBroadcast(macvlan, 5000, 8); err != nil {
+		t.Fatal(err)
+	}
+	queueLen, cutoff, err := MacvlanGetBroadcast(macvlan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queueLen != 5000 || cutoff != 8 {
+		t.Fatalf("Got queue len %d and cutoff %d, expected 5000 and 8", queueLen, cutoff)
+	}
+
+	link, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := link.(*Macvlan); result.BCQueueLen != 5000 || result.Mode != MACVLAN_MODE_BRIDGE {
+		t.Fatalf("Got requested queue len %d and mode %d", result.BCQueueLen, result.Mode)
+	}
+}
+
 func TestLinkAddDelMacvtap(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -621,6 +812,167 @@ func TestLinkSetUnsetResetMaster(t *testing.T) {
 	}
 }
 
+func TestLinkSlaveInfo(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bond := NewLinkBond(LinkAttrs{Name: "foo"})
+	if err := LinkAdd(bond); err != nil {
+		t.Fatal(err)
+	}
+	bridge := &Bridge{LinkAttrs: LinkAttrs{Name: "bar"}}
+	if err := LinkAdd(bridge); err != nil {
+		t.Fatal(err)
+	}
+	bondSlave := &Dummy{LinkAttrs{Name: "baz"}}
+	if err := LinkAdd(bondSlave); err != nil {
+		t.Fatal(err)
+	}
+	bridgeSlave := &Dummy{LinkAttrs{Name: "qux"}}
+	if err := LinkAdd(bridgeSlave); err != nil {
+		t.Fatal(err)
+	}
+	bondLink, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetMasterByIndex(bondSlave, bondLink.Attrs().Index); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetMaster(bridgeSlave, bridge); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, ok := link.Attrs().Slave.(*BondSlave)
+	if !ok {
+		t.Fatalf("expected a bond slave, got %#v", link.Attrs().Slave)
+	}
+	if bs.SlaveType() != "bond" {
+		t.Fatalf("unexpected slave type %s", bs.SlaveType())
+	}
+	if bs.PermHardwareAddr.String() != link.Attrs().HardwareAddr.String() {
+		t.Fatalf("expected permanent address %s, got %s", link.Attrs().HardwareAddr, bs.PermHardwareAddr)
+	}
+
+	link, err = LinkByName("qux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	brs, ok := link.Attrs().Slave.(*BridgeSlave)
+	if !ok {
+		t.Fatalf("expected a bridge slave, got %#v", link.Attrs().Slave)
+	}
+	if !brs.Learning || !brs.Flood {
+		t.Fatalf("expected the default port flags, got %s", brs.String())
+	}
+	if link.Attrs().Protinfo == nil || *link.Attrs().Protinfo != brs.Protinfo {
+		t.Fatalf("expected Protinfo to match the bridge slave, got %v", link.Attrs().Protinfo)
+	}
+
+	if err := LinkSetNoMaster(bridgeSlave); err != nil {
+		t.Fatal(err)
+	}
+	link, err = LinkByName("qux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().Slave != nil {
+		t.Fatalf("expected no slave info, got %#v", link.Attrs().Slave)
+	}
+}
+
+func TestLinkGetVrfTable(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	vrf := &Vrf{LinkAttrs: LinkAttrs{Name: "foo"}, Table: 10}
+	if err := LinkAdd(vrf); err != nil {
+		t.Fatal(err)
+	}
+	slave := &Dummy{LinkAttrs{Name: "bar"}}
+	if err := LinkAdd(slave); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := LinkGetVrfTable(slave); ok {
+		t.Fatal("expected no vrf table for a link without master")
+	}
+
+	vrfLink, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetMasterByIndex(slave, vrfLink.Attrs().Index); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table, ok := LinkGetVrfTable(link); !ok || table != 10 {
+		t.Fatalf("expected vrf table 10, got %d %v", table, ok)
+	}
+
+	// without the slave info the master is looked up
+	attrs := *link.Attrs()
+	attrs.Slave = nil
+	if table, ok := LinkGetVrfTable(&Dummy{attrs}); !ok || table != 10 {
+		t.Fatalf("expected vrf table 10 from the master, got %d %v", table, ok)
+	}
+}
+
+func TestLinkSetMasterNil(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	master := &Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(master); err != nil {
+		t.Fatal(err)
+	}
+
+	slave := &Dummy{LinkAttrs{Name: "baz"}}
+	if err := LinkAdd(slave); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LinkSetMaster(slave, master); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LinkSetMaster(slave, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	links, err := LinkList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, link := range links {
+		if link.Attrs().Name != "baz" {
+			continue
+		}
+		found = true
+		if link.Attrs().MasterIndex != 0 {
+			t.Fatalf("Master not detached properly, MasterIndex is %d", link.Attrs().MasterIndex)
+		}
+	}
+	if !found {
+		t.Fatal("Slave link not found in LinkList")
+	}
+
+	if err := LinkDel(slave); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LinkDel(master); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestLinkSetNs(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -637,7 +989,7 @@ func TestLinkSetNs(t *testing.T) {
 	}
 	defer newns.Close()
 
-	link := &Veth{LinkAttrs{Name: "foo"}, "bar"}
+	link := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
 	if err := LinkAdd(link); err != nil {
 		t.Fatal(err)
 	}
@@ -683,43 +1035,100 @@ func TestLinkSetNs(t *testing.T) {
 
 }
 
-func TestLinkAddDelVxlan(t *testing.T) {
+func TestLinkAddVethPeerNamespace(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
 
-	parent := &Dummy{
-		LinkAttrs{Name: "foo"},
+	basens, err := netns.Get()
+	if err != nil {
+		t.Fatal("Failed to get basens")
 	}
-	if err := LinkAdd(parent); err != nil {
-		t.Fatal(err)
+	defer basens.Close()
+
+	newns, err := netns.New()
+	if err != nil {
+		t.Fatal("Failed to create newns")
 	}
+	defer newns.Close()
 
-	vxlan := Vxlan{
-		LinkAttrs: LinkAttrs{
-			Name: "bar",
-		},
-		VxlanId:      10,
-		VtepDevIndex: parent.Index,
-		Learning:     true,
-		L2miss:       true,
-		L3miss:       true,
+	if err := netns.Set(basens); err != nil {
+		t.Fatal("Failed to set basens")
 	}
 
-	testLinkAddDel(t, &vxlan)
-	if err := LinkDel(parent); err != nil {
+	peerMAC, _ := net.ParseMAC("02:42:ac:11:00:02")
+	veth := &Veth{
+		LinkAttrs:        LinkAttrs{Name: "foo"},
+		PeerName:         "bar",
+		PeerHardwareAddr: peerMAC,
+		PeerNamespace:    NsFd(newns),
+	}
+	if err := LinkAdd(veth); err != nil {
 		t.Fatal(err)
 	}
-}
 
-func TestLinkAddDelVxlanGbp(t *testing.T) {
-	if os.Getenv("TRAVIS_BUILD_DIR") != "" {
-		t.Skipf("Kernel in travis is too old for this test")
+	if _, err := LinkByName("bar"); err == nil {
+		t.Fatal("Peer bar was created in basens")
 	}
 
-	tearDown := setUpNetlinkTest(t)
-	defer tearDown()
-
-	parent := &Dummy{
+	nh, err := NewHandleAt(newns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nh.Delete()
+
+	peer, err := nh.LinkByName("bar")
+	if err != nil {
+		t.Fatal("Peer bar is not in newns")
+	}
+	if peer.Attrs().HardwareAddr.String() != peerMAC.String() {
+		t.Fatalf("Peer MAC is %s, expected %s", peer.Attrs().HardwareAddr, peerMAC)
+	}
+
+	if err := LinkDel(veth); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := nh.LinkByName("bar"); err == nil {
+		t.Fatal("Other half of veth pair not deleted")
+	}
+}
+
+func TestLinkAddDelVxlan(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	parent := &Dummy{
+		LinkAttrs{Name: "foo"},
+	}
+	if err := LinkAdd(parent); err != nil {
+		t.Fatal(err)
+	}
+
+	vxlan := Vxlan{
+		LinkAttrs: LinkAttrs{
+			Name: "bar",
+		},
+		VxlanId:      10,
+		VtepDevIndex: parent.Index,
+		Learning:     true,
+		L2miss:       true,
+		L3miss:       true,
+	}
+
+	testLinkAddDel(t, &vxlan)
+	if err := LinkDel(parent); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLinkAddDelVxlanGbp(t *testing.T) {
+	if os.Getenv("TRAVIS_BUILD_DIR") != "" {
+		t.Skipf("Kernel in travis is too old for this test")
+	}
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	parent := &Dummy{
 		LinkAttrs{Name: "foo"},
 	}
 	if err := LinkAdd(parent); err != nil {
@@ -944,6 +1353,55 @@ func TestLinkSet(t *testing.T) {
 	}
 }
 
+func TestLinkSwapNames(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	if err := LinkAdd(&Dummy{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkAdd(&Dummy{LinkAttrs{Name: "bar"}}); err != nil {
+		t.Fatal(err)
+	}
+	foo, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bar, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = LinkSetName(foo, "bar")
+	if _, ok := err.(LinkNameExistsError); !ok {
+		t.Fatalf("Renaming to a used name returned %v, expected a LinkNameExistsError", err)
+	}
+	if !errors.Is(err, syscall.EEXIST) {
+		t.Fatalf("Renaming to a used name returned %v, expected it to wrap EEXIST", err)
+	}
+
+	if err := LinkSwapNames(foo, bar); err != nil {
+		t.Fatal(err)
+	}
+	if foo.Attrs().Name != "bar" || bar.Attrs().Name != "foo" {
+		t.Fatalf("Link attrs not updated: %s and %s", foo.Attrs().Name, bar.Attrs().Name)
+	}
+	link, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().Index != foo.Attrs().Index {
+		t.Fatalf("bar has index %d, expected %d", link.Attrs().Index, foo.Attrs().Index)
+	}
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().Index != bar.Attrs().Index {
+		t.Fatalf("foo has index %d, expected %d", link.Attrs().Index, bar.Attrs().Index)
+	}
+}
+
 func TestLinkSetARP(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -1012,7 +1470,7 @@ func TestLinkSubscribe(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	link := &Veth{LinkAttrs{Name: "foo", TxQLen: testTxQLen, MTU: 1400}, "bar"}
+	link := &Veth{LinkAttrs: LinkAttrs{Name: "foo", TxQLen: testTxQLen, MTU: 1400}, PeerName: "bar"}
 	if err := LinkAdd(link); err != nil {
 		t.Fatal(err)
 	}
@@ -1062,7 +1520,7 @@ func TestLinkSubscribeAt(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	link := &Veth{LinkAttrs{Name: "test", TxQLen: testTxQLen, MTU: 1400}, "bar"}
+	link := &Veth{LinkAttrs: LinkAttrs{Name: "test", TxQLen: testTxQLen, MTU: 1400}, PeerName: "bar"}
 	if err := nh.LinkAdd(link); err != nil {
 		t.Fatal(err)
 	}
@@ -1136,176 +1594,1041 @@ func TestLinkStats(t *testing.T) {
 	}
 }
 
-func TestLinkXdp(t *testing.T) {
-	links, err := LinkList()
+func TestLinkStatsSince(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	lo, err := LinkByName("lo")
 	if err != nil {
 		t.Fatal(err)
 	}
-	var testXdpLink Link
-	for _, link := range links {
-		if link.Attrs().Xdp != nil && !link.Attrs().Xdp.Attached {
-			testXdpLink = link
-			break
-		}
+	if err := LinkSetUp(lo); err != nil {
+		t.Fatal(err)
 	}
-	if testXdpLink == nil {
-		t.Skipf("No link supporting XDP found")
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
 	}
-	fd, err := loadSimpleBpf(BPF_PROG_TYPE_XDP, 2 /*XDP_PASS*/)
+	defer conn.Close()
+
+	snapshot, err := LinkStatsSnapshot(lo)
 	if err != nil {
-		t.Skipf("Loading bpf program failed: %s", err)
+		t.Fatal(err)
 	}
-	if err := LinkSetXdpFd(testXdpLink, fd); err != nil {
+	// the namespace is new so nothing else goes over lo
+	const count = 5
+	for i := 0; i < count; i++ {
+		if _, err := conn.WriteToUDP([]byte("ping"), conn.LocalAddr().(*net.UDPAddr)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	delta, err := LinkStatsSince(lo, snapshot)
+	if err != nil {
 		t.Fatal(err)
 	}
-	if err := LinkSetXdpFdWithFlags(testXdpLink, fd, nl.XDP_FLAGS_UPDATE_IF_NOEXIST); err != syscall.EBUSY {
+	if delta.TxPackets != count || delta.RxPackets != count {
+		t.Fatalf("Expected %d packets since the snapshot, got %d sent and %d received", count, delta.TxPackets, delta.RxPackets)
+	}
+	if delta.RxErrors != 0 || delta.TxErrors != 0 {
+		t.Fatalf("Unexpected errors since the snapshot: %+v", delta)
+	}
+}
+
+func TestLinkVfConfigAttrs(t *testing.T) {
+	spoofchk, trust, rss := true, false, true
+	linkState := uint32(nl.IFLA_VF_LINK_STATE_DISABLE)
+	cfg := VfConfig{
+		Mac:       net.HardwareAddr{0x02, 0, 0, 0, 0, 0x01},
+		Vlan:      100,
+		Qos:       3,
+		VlanProto: syscall.ETH_P_8021Q,
+		MinTxRate: 10,
+		MaxTxRate: 100,
+		Spoofchk:  &spoofchk,
+		Trust:     &trust,
+		LinkState: &linkState,
+		RssQuery:  &rss,
+	}
+	info := nl.NewRtAttr(nl.IFLA_VF_INFO, nil)
+	addVfConfigAttrs(info, 2, cfg)
+
+	attrs, err := nl.ParseRouteAttr(info.Serialize()[syscall.SizeofRtAttr:])
+	if err != nil {
 		t.Fatal(err)
 	}
-	if err := LinkSetXdpFd(testXdpLink, -1); err != nil {
+	found := map[uint16][]byte{}
+	for _, attr := range attrs {
+		found[attr.Attr.Type] = attr.Value
+	}
+	for _, typ := range []uint16{nl.IFLA_VF_MAC, nl.IFLA_VF_VLAN_LIST, nl.IFLA_VF_RATE, nl.IFLA_VF_SPOOFCHK,
+		nl.IFLA_VF_TRUST, nl.IFLA_VF_LINK_STATE, nl.IFLA_VF_RSS_QUERY_EN} {
+		if _, ok := found[typ]; !ok {
+			t.Fatalf("Attribute %d missing from vf config", typ)
+		}
+	}
+	if len(attrs) != 7 {
+		t.Fatalf("Expected 7 attributes, got %d", len(attrs))
+	}
+	if rate := nl.DeserializeVfRate(found[nl.IFLA_VF_RATE]); rate.Vf != 2 || rate.MinTxRate != 10 || rate.MaxTxRate != 100 {
+		t.Fatalf("Rate attribute is wrong: %+v", *rate)
+	}
+	if trust := nl.DeserializeVfTrust(found[nl.IFLA_VF_TRUST]); trust.Setting != 0 {
+		t.Fatal("Trust should be disabled")
+	}
+	vlans, err := nl.ParseRouteAttr(found[nl.IFLA_VF_VLAN_LIST])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vlans) != 1 || vlans[0].Attr.Type != nl.IFLA_VF_VLAN_INFO {
+		t.Fatal("Vlan list is wrong")
+	}
+	if vlan := nl.DeserializeVfVlanInfo(vlans[0].Value); vlan.Vlan != 100 || vlan.Qos != 3 || nl.Swap16(vlan.VlanProto) != syscall.ETH_P_8021Q {
+		t.Fatalf("Vlan info is wrong: %+v", *vlan)
+	}
+
+	// unset fields are left out
+	info = nl.NewRtAttr(nl.IFLA_VF_INFO, nil)
+	addVfConfigAttrs(info, 2, VfConfig{Trust: &trust})
+	attrs, err = nl.ParseRouteAttr(info.Serialize()[syscall.SizeofRtAttr:])
+	if err != nil {
 		t.Fatal(err)
 	}
+	if len(attrs) != 1 || attrs[0].Attr.Type != nl.IFLA_VF_TRUST {
+		t.Fatal("Only the trust attribute should be encoded")
+	}
 }
 
-func TestLinkAddDelIptun(t *testing.T) {
+func TestLinkListWithOptions(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
 
-	testLinkAddDel(t, &Iptun{
-		LinkAttrs: LinkAttrs{Name: "iptunfoo"},
-		PMtuDisc:  1,
-		Local:     net.IPv4(127, 0, 0, 1),
-		Remote:    net.IPv4(127, 0, 0, 1)})
+	for _, mask := range []uint32{0, nl.RTEXT_FILTER_VF, nl.RTEXT_FILTER_SKIP_STATS} {
+		links, err := LinkListWithOptions(&LinkListOptions{ExtMask: mask})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(links) != 1 || links[0].Attrs().Name != "lo" {
+			t.Fatalf("Expected only loopback in dump with mask %#x, got %v", mask, links)
+		}
+	}
 }
 
-func TestLinkAddDelVti(t *testing.T) {
-	tearDown := setUpNetlinkTest(t)
+func benchmarkLinkList(b *testing.B, options *LinkListOptions) {
+	tearDown := setUpNetlinkTest(b)
 	defer tearDown()
 
-	testLinkAddDel(t, &Vti{
-		LinkAttrs: LinkAttrs{Name: "vtifoo"},
-		IKey:      0x101,
-		OKey:      0x101,
-		Local:     net.IPv4(127, 0, 0, 1),
-		Remote:    net.IPv4(127, 0, 0, 1)})
-}
+	for i := 0; i < 64; i++ {
+		veth := &Veth{LinkAttrs: LinkAttrs{Name: fmt.Sprintf("foo%d", i)}, PeerName: fmt.Sprintf("bar%d", i)}
+		if err := LinkAdd(veth); err != nil {
+			b.Fatal(err)
+		}
+	}
 
-func TestBridgeCreationWithMulticastSnooping(t *testing.T) {
-	if os.Getenv("TRAVIS_BUILD_DIR") != "" {
-		t.Skipf("Travis CI worker Linux kernel version (3.13) is too old for this test")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := LinkListWithOptions(options); err != nil {
+			b.Fatal(err)
+		}
 	}
+}
+
+func BenchmarkLinkListFull(b *testing.B) {
+	benchmarkLinkList(b, &LinkListOptions{ExtMask: nl.RTEXT_FILTER_VF})
+}
+
+func BenchmarkLinkListSkipStats(b *testing.B) {
+	benchmarkLinkList(b, &LinkListOptions{ExtMask: nl.RTEXT_FILTER_SKIP_STATS})
+}
 
+func TestLinkMTURange(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
 
-	bridgeWithDefaultMcastSnoopName := "foo"
-	bridgeWithDefaultMcastSnoop := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeWithDefaultMcastSnoopName}}
-	if err := LinkAdd(bridgeWithDefaultMcastSnoop); err != nil {
+	if err := LinkAdd(&Dummy{LinkAttrs{Name: "foo"}}); err != nil {
 		t.Fatal(err)
 	}
-	expectMcastSnooping(t, bridgeWithDefaultMcastSnoopName, true)
-	if err := LinkDel(bridgeWithDefaultMcastSnoop); err != nil {
+
+	link, err := LinkByName("foo")
+	if err != nil {
 		t.Fatal(err)
 	}
+	base := link.Attrs()
+	if base.MinMTU == 0 && base.MaxMTU == 0 {
+		t.Skip("Kernel does not report IFLA_MIN_MTU and IFLA_MAX_MTU")
+	}
+	if base.MinMTU > base.MTU || base.MTU > base.MaxMTU {
+		t.Fatalf("MTU %d not in range [%d, %d]", base.MTU, base.MinMTU, base.MaxMTU)
+	}
+}
 
-	mcastSnoop := true
-	bridgeWithMcastSnoopOnName := "bar"
-	bridgeWithMcastSnoopOn := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeWithMcastSnoopOnName}, MulticastSnooping: &mcastSnoop}
-	if err := LinkAdd(bridgeWithMcastSnoopOn); err != nil {
+func TestLinkSetLinkModeDormant(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
 		t.Fatal(err)
 	}
-	expectMcastSnooping(t, bridgeWithMcastSnoopOnName, true)
-	if err := LinkDel(bridgeWithMcastSnoopOn); err != nil {
+	peer, err := LinkByName("bar")
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	mcastSnoop = false
-	bridgeWithMcastSnoopOffName := "foobar"
-	bridgeWithMcastSnoopOff := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeWithMcastSnoopOffName}, MulticastSnooping: &mcastSnoop}
-	if err := LinkAdd(bridgeWithMcastSnoopOff); err != nil {
+	// the operstate is set asynchronously by linkwatch
+	ch := make(chan LinkUpdate)
+	done := make(chan struct{})
+	defer close(done)
+	if err := LinkSubscribe(ch, done); err != nil {
 		t.Fatal(err)
 	}
-	expectMcastSnooping(t, bridgeWithMcastSnoopOffName, false)
-	if err := LinkDel(bridgeWithMcastSnoopOff); err != nil {
+	if err := LinkSetLinkMode(veth, LinkModeDormant); err != nil {
 		t.Fatal(err)
 	}
-}
-
-func TestBridgeSetMcastSnoop(t *testing.T) {
-	if os.Getenv("TRAVIS_BUILD_DIR") != "" {
-		t.Skipf("Travis CI worker Linux kernel version (3.13) is too old for this test")
-	}
-
-	tearDown := setUpNetlinkTest(t)
-	defer tearDown()
-
-	bridgeName := "foo"
-	bridge := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeName}}
-	if err := LinkAdd(bridge); err != nil {
+	if err := LinkSetUp(veth); err != nil {
 		t.Fatal(err)
 	}
-	expectMcastSnooping(t, bridgeName, true)
-
-	if err := BridgeSetMcastSnoop(bridge, false); err != nil {
+	if err := LinkSetUp(peer); err != nil {
 		t.Fatal(err)
 	}
-	expectMcastSnooping(t, bridgeName, false)
+	timeout := time.After(time.Minute)
+	for dormant := false; !dormant; {
+		select {
+		case update := <-ch:
+			dormant = update.Link.Attrs().Name == "foo" && update.Link.Attrs().OperState == OperDormant
+		case <-timeout:
+			t.Fatal("Dormant update not received as expected")
+		}
+	}
 
-	if err := BridgeSetMcastSnoop(bridge, true); err != nil {
+	link, err := LinkByName("foo")
+	if err != nil {
 		t.Fatal(err)
 	}
-	expectMcastSnooping(t, bridgeName, true)
+	if link.Attrs().LinkMode != LinkModeDormant {
+		t.Fatalf("Link mode is %s, should be %s", link.Attrs().LinkMode, LinkModeDormant)
+	}
+	if link.Attrs().OperState != OperDormant {
+		t.Fatalf("Oper state is %s, should be %s", link.Attrs().OperState, LinkOperState(OperDormant))
+	}
 
-	if err := LinkDel(bridge); err != nil {
+	if err := LinkSetLinkMode(veth, LinkModeDefault); err != nil {
 		t.Fatal(err)
 	}
-}
-
-func expectMcastSnooping(t *testing.T, linkName string, expected bool) {
-	bridge, err := LinkByName(linkName)
+	link, err = LinkByName("foo")
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	if actual := *bridge.(*Bridge).MulticastSnooping; actual != expected {
-		t.Fatalf("expected %t got %t", expected, actual)
+	if link.Attrs().LinkMode != LinkModeDefault {
+		t.Fatalf("Link mode is %s, should be %s", link.Attrs().LinkMode, LinkModeDefault)
 	}
 }
 
-func TestBridgeCreationWithHelloTime(t *testing.T) {
-	if os.Getenv("TRAVIS_BUILD_DIR") != "" {
-		t.Skipf("Travis CI worker Linux kernel version (3.13) is too old for this test")
-	}
-
+func TestLinkModify(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
 
-	bridgeWithSpecifiedHelloTimeName := "foo"
-	helloTime := uint32(300)
-	bridgeWithSpecifiedHelloTime := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeWithSpecifiedHelloTimeName}, HelloTime: &helloTime}
-	if err := LinkAdd(bridgeWithSpecifiedHelloTime); err != nil {
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo", MTU: 1400}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
 		t.Fatal(err)
 	}
-
-	retrievedBridge, err := LinkByName(bridgeWithSpecifiedHelloTimeName)
-	if err != nil {
+	if err := LinkSetUp(veth); err != nil {
 		t.Fatal(err)
 	}
-
-	actualHelloTime := *retrievedBridge.(*Bridge).HelloTime
-	if actualHelloTime != helloTime {
-		t.Fatalf("expected %d got %d", helloTime, actualHelloTime)
-	}
-	if err := LinkDel(bridgeWithSpecifiedHelloTime); err != nil {
+	link, err := LinkByName("foo")
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	bridgeWithDefaultHelloTimeName := "bar"
-	bridgeWithDefaultHelloTime := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeWithDefaultHelloTimeName}}
-	if err := LinkAdd(bridgeWithDefaultHelloTime); err != nil {
+	desired := *link.Attrs()
+	changed, err := LinkModify(&Device{desired})
+	if err != nil {
 		t.Fatal(err)
 	}
+	if len(changed) != 0 {
+		t.Fatalf("Unchanged link modified: %v", changed)
+	}
 
-	retrievedBridge, err = LinkByName(bridgeWithDefaultHelloTimeName)
+	desired.MTU = 1300
+	changed, err = LinkModify(&Device{desired})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != "mtu" {
+		t.Fatalf("Changed %v, expected only mtu", changed)
+	}
+
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().MTU != 1300 {
+		t.Fatalf("MTU is %d, expected 1300", link.Attrs().MTU)
+	}
+	if link.Attrs().Flags&net.FlagUp == 0 {
+		t.Fatal("Link foo is not up anymore")
+	}
+
+	desired.Alias = "baz"
+	changed, err = LinkModify(&Device{desired})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != "alias" {
+		t.Fatalf("Changed %v, expected only alias", changed)
+	}
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().Alias != "baz" || link.Attrs().MTU != 1300 {
+		t.Fatalf("Alias is %q and MTU %d, expected baz and 1300", link.Attrs().Alias, link.Attrs().MTU)
+	}
+}
+
+func TestLinkSetThreaded(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// sysfs has to show the namespace of the test
+	if err := remountSysfs(); err != nil {
+		t.Fatal(err)
+	}
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	// veth only has NAPI instances with GRO on and both ends up
+	if err := EthtoolSetFeature("foo", "rx-gro", true); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"foo", "bar"} {
+		link, err := LinkByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := LinkSetUp(link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := LinkSetThreaded(veth, true); err != nil {
+		if errors.Is(err, syscall.EOPNOTSUPP) {
+			t.Skipf("Threaded NAPI not supported on veth: %v", err)
+		}
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	threaded, err := LinkGetThreaded(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !threaded || !link.Attrs().Threaded {
+		t.Fatal("Threaded NAPI not enabled on foo")
+	}
+
+	if err := LinkSetThreaded(veth, false); err != nil {
+		t.Fatal(err)
+	}
+	if threaded, err = LinkGetThreaded(link); err != nil {
+		t.Fatal(err)
+	}
+	if threaded {
+		t.Fatal("Threaded NAPI still enabled on foo")
+	}
+}
+func TestLinkCarrierChanges(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	peer, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(veth); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := link.Attrs().CarrierChanges
+
+	// the carrier of a veth follows the state of its peer
+	if err := LinkSetUp(peer); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetDown(peer); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().CarrierChanges < changes+2 {
+		t.Fatalf("Carrier changes is %d, should be at least %d", link.Attrs().CarrierChanges, changes+2)
+	}
+	if link.Attrs().CarrierUpCount == 0 || link.Attrs().CarrierDownCount == 0 {
+		t.Fatalf("Carrier up/down counts are %d/%d, should not be zero", link.Attrs().CarrierUpCount, link.Attrs().CarrierDownCount)
+	}
+}
+
+func TestLinkPromiscuityAllmulti(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	bridge := &Bridge{LinkAttrs: LinkAttrs{Name: "baz"}}
+	if err := LinkAdd(bridge); err != nil {
+		t.Fatal(err)
+	}
+
+	// one reference from the user, one from the bridge port
+	if err := SetPromiscOn(veth); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetMaster(veth, bridge); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().Promiscuity != 2 {
+		t.Fatalf("Promiscuity is %d, should be 2", link.Attrs().Promiscuity)
+	}
+
+	if err := SetPromiscOff(veth); err != nil {
+		t.Fatal(err)
+	}
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().Promiscuity != 1 {
+		t.Fatalf("Promiscuity is %d, should be 1", link.Attrs().Promiscuity)
+	}
+
+	if err := LinkSetAllmulticastOn(veth); err != nil {
+		t.Fatal(err)
+	}
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().RawFlags&syscall.IFF_ALLMULTI == 0 {
+		t.Fatal("Allmulticast not enabled")
+	}
+	if err := LinkSetAllmulticastOff(veth); err != nil {
+		t.Fatal(err)
+	}
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().RawFlags&syscall.IFF_ALLMULTI != 0 {
+		t.Fatal("Allmulticast not disabled")
+	}
+}
+
+func TestLinkSetIP6AddrGen(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// the kernel refuses tokens on NOARP links such as dummy
+	if err := LinkAdd(&Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	token := net.ParseIP("::1234:5678")
+	if err := LinkSetIP6AddrGenToken(link, token); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetIP6AddrGenMode(link, IP6AddrGenModeNone); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !link.Attrs().IP6AddrGenToken.Equal(token) {
+		t.Fatalf("Token is %s, should be %s", link.Attrs().IP6AddrGenToken, token)
+	}
+	if link.Attrs().IP6AddrGenMode != IP6AddrGenModeNone {
+		t.Fatalf("Address generation mode is %s, should be %s", link.Attrs().IP6AddrGenMode, IP6AddrGenModeNone)
+	}
+}
+
+func TestLinkAddGenericData(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// dummy has no info-data policy, so the kernel accepts and ignores
+	// any attributes passed along with it.
+	link := &GenericLink{
+		LinkAttrs: LinkAttrs{Name: "foo"},
+		LinkType:  "dummy",
+		Data:      map[uint16][]byte{1: nl.Uint32Attr(42)},
+	}
+	if err := LinkAdd(link); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LinkByName("foo"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLinkAddGenericDataList(t *testing.T) {
+	tearDown := setUpNetlinkTestWithKModule(t, "geneve")
+	defer tearDown()
+
+	// geneve is not understood by the library, so it is listed as a
+	// GenericLink with the info-data the kernel reports for it
+	id, remote := nl.Uint32Attr(42), []byte(net.IPv4(192, 0, 2, 1).To4())
+	link := &GenericLink{
+		LinkAttrs: LinkAttrs{Name: "foo"},
+		LinkType:  "geneve",
+		Data: map[uint16][]byte{
+			1: id,     // IFLA_GENEVE_ID
+			2: remote, // IFLA_GENEVE_REMOTE
+		},
+	}
+	if err := LinkAdd(link); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generic, ok := result.(*GenericLink)
+	if !ok {
+		t.Fatalf("expected *GenericLink, got %T", result)
+	}
+	if generic.LinkType != "geneve" {
+		t.Fatalf("LinkType is %q, expected geneve", generic.LinkType)
+	}
+	if !bytes.Equal(generic.Data[1], id) || !bytes.Equal(generic.Data[2], remote) {
+		t.Fatalf("Got id %v remote %v, expected %v and %v", generic.Data[1], generic.Data[2], id, remote)
+	}
+}
+
+func TestLinkXdp(t *testing.T) {
+	links, err := LinkList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var testXdpLink Link
+	for _, link := range links {
+		if link.Attrs().Xdp != nil && !link.Attrs().Xdp.Attached {
+			testXdpLink = link
+			break
+		}
+	}
+	if testXdpLink == nil {
+		t.Skipf("No link supporting XDP found")
+	}
+	fd, err := loadSimpleBpf(BPF_PROG_TYPE_XDP, 2 /*XDP_PASS*/)
+	if err != nil {
+		t.Skipf("Loading bpf program failed: %s", err)
+	}
+	if err := LinkSetXdpFd(testXdpLink, fd); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetXdpFdWithFlags(testXdpLink, fd, nl.XDP_FLAGS_UPDATE_IF_NOEXIST); err != syscall.EBUSY {
+		t.Fatal(err)
+	}
+	if err := LinkSetXdpFd(testXdpLink, -1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLinkXdpSkbMode(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	if err := LinkAdd(&Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := loadSimpleBpf(BPF_PROG_TYPE_XDP, 2 /*XDP_PASS*/)
+	if err != nil {
+		t.Skipf("Loading bpf program failed: %s", err)
+	}
+	defer syscall.Close(fd)
+	if err := LinkSetXdpFdWithFlags(link, fd, nl.XDP_FLAGS_SKB_MODE); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	xdp := link.Attrs().Xdp
+	if xdp == nil || !xdp.Attached || xdp.AttachMode != nl.XDP_ATTACHED_SKB {
+		t.Fatalf("Program not attached in generic mode: %+v", xdp)
+	}
+	if xdp.SkbProgId == 0 || xdp.ProgId != xdp.SkbProgId {
+		t.Fatalf("Generic prog id %d should be set and match %d", xdp.SkbProgId, xdp.ProgId)
+	}
+	if xdp.DrvProgId != 0 || xdp.HwProgId != 0 {
+		t.Fatalf("Driver and hardware prog ids should be zero: %+v", xdp)
+	}
+
+	if err := LinkSetXdpFdWithFlags(link, -1, nl.XDP_FLAGS_SKB_MODE); err != nil {
+		t.Fatal(err)
+	}
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xdp := link.Attrs().Xdp; xdp == nil || xdp.Attached || xdp.SkbProgId != 0 {
+		t.Fatalf("Program still attached: %+v", xdp)
+	}
+}
+
+func TestLinkAddDelIptun(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	testLinkAddDel(t, &Iptun{
+		LinkAttrs: LinkAttrs{Name: "iptunfoo"},
+		PMtuDisc:  1,
+		Local:     net.IPv4(127, 0, 0, 1),
+		Remote:    net.IPv4(127, 0, 0, 1)})
+}
+
+func TestLinkAddErspan(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	erspan := &Erspan{
+		LinkAttrs:  LinkAttrs{Name: "erspanfoo"},
+		IKey:       0x10,
+		OKey:       0x10,
+		Local:      net.IPv4(127, 0, 0, 1),
+		Remote:     net.IPv4(127, 0, 0, 2),
+		Ttl:        64,
+		ErspanVer:  2,
+		ErspanDir:  ERSPAN_DIR_EGRESS,
+		ErspanHwId: 7,
+	}
+	if err := LinkAdd(erspan); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("erspanfoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := link.(*Erspan)
+	if !ok {
+		t.Fatal("Result of create is not a erspan")
+	}
+	if result.ErspanVer != erspan.ErspanVer || result.ErspanDir != erspan.ErspanDir || result.ErspanHwId != erspan.ErspanHwId {
+		t.Fatalf("ERSPAN parameters are ver %d dir %d hwid %d, expected ver %d dir %d hwid %d",
+			result.ErspanVer, result.ErspanDir, result.ErspanHwId, erspan.ErspanVer, erspan.ErspanDir, erspan.ErspanHwId)
+	}
+	if result.OKey != erspan.OKey {
+		t.Fatalf("Session key is %d, expected %d", result.OKey, erspan.OKey)
+	}
+}
+
+func TestLinkAddHsr(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	slave1 := &Dummy{LinkAttrs{Name: "hsrslave1"}}
+	if err := LinkAdd(slave1); err != nil {
+		t.Fatal(err)
+	}
+	slave2 := &Dummy{LinkAttrs{Name: "hsrslave2"}}
+	if err := LinkAdd(slave2); err != nil {
+		t.Fatal(err)
+	}
+	ensureIndex(slave1.Attrs())
+	ensureIndex(slave2.Attrs())
+
+	hsr := &Hsr{
+		LinkAttrs: LinkAttrs{Name: "hsrfoo"},
+		Slave1:    uint32(slave1.Index),
+		Slave2:    uint32(slave2.Index),
+		Version:   1,
+	}
+	if err := LinkAdd(hsr); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("hsrfoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := link.(*Hsr)
+	if !ok {
+		t.Fatal("Result of create is not a hsr")
+	}
+	if result.Slave1 != hsr.Slave1 || result.Slave2 != hsr.Slave2 {
+		t.Fatalf("Slaves are %d and %d, expected %d and %d", result.Slave1, result.Slave2, hsr.Slave1, hsr.Slave2)
+	}
+	if result.Protocol != HSR_PROTOCOL_HSR {
+		t.Fatalf("Protocol is %d, expected %d", result.Protocol, HSR_PROTOCOL_HSR)
+	}
+	if len(result.SupervisionAddr) != 6 {
+		t.Fatalf("Supervision address %s not decoded", result.SupervisionAddr)
+	}
+
+	if err := LinkDel(hsr); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLinkAddDelSittun(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	testLinkAddDel(t, &Sittun{
+		LinkAttrs: LinkAttrs{Name: "sittunfoo"},
+		PMtuDisc:  1,
+		Local:     net.IPv4(127, 0, 0, 1),
+		Remote:    net.IPv4(127, 0, 0, 1)})
+}
+
+func TestLinkAddSittun6rd(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	_, prefix, _ := net.ParseCIDR("2001:db8::/32")
+	_, relayPrefix, _ := net.ParseCIDR("10.0.0.0/8")
+	sittun := &Sittun{
+		LinkAttrs:        LinkAttrs{Name: "sittunfoo"},
+		Local:            net.IPv4(10, 1, 2, 3),
+		Ttl:              64,
+		SixRDPrefix:      prefix,
+		SixRDRelayPrefix: relayPrefix,
+	}
+	if err := LinkAdd(sittun); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("sittunfoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := link.(*Sittun)
+	if !ok {
+		t.Fatal("Result of create is not a sittun")
+	}
+	if result.SixRDPrefix == nil || result.SixRDPrefix.String() != prefix.String() {
+		t.Fatalf("6rd prefix is %v, expected %v", result.SixRDPrefix, prefix)
+	}
+	if result.SixRDRelayPrefix == nil || result.SixRDRelayPrefix.String() != relayPrefix.String() {
+		t.Fatalf("6rd relay prefix is %v, expected %v", result.SixRDRelayPrefix, relayPrefix)
+	}
+}
+
+func TestLinkAddIp6tnlEncapLimit(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	ip6tnl := &Ip6tnl{
+		LinkAttrs:  LinkAttrs{Name: "ip6tnlfoo"},
+		Local:      net.ParseIP("2001:db8::1"),
+		Remote:     net.ParseIP("2001:db8::2"),
+		Ttl:        64,
+		EncapLimit: 2,
+		FlowInfo:   0x12345,
+		Proto:      syscall.IPPROTO_IPV6,
+	}
+	if err := LinkAdd(ip6tnl); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("ip6tnlfoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := link.(*Ip6tnl)
+	if !ok {
+		t.Fatal("Result of create is not a ip6tnl")
+	}
+	if result.EncapLimit != ip6tnl.EncapLimit {
+		t.Fatalf("Encap limit is %d, expected %d", result.EncapLimit, ip6tnl.EncapLimit)
+	}
+	if result.FlowInfo != ip6tnl.FlowInfo {
+		t.Fatalf("Flow info is %#x, expected %#x", result.FlowInfo, ip6tnl.FlowInfo)
+	}
+	if !result.Local.Equal(ip6tnl.Local) || !result.Remote.Equal(ip6tnl.Remote) {
+		t.Fatalf("Tunnel endpoints are %s-%s, expected %s-%s", result.Local, result.Remote, ip6tnl.Local, ip6tnl.Remote)
+	}
+
+	// without an encap limit the kernel default applies
+	if err := LinkAdd(&Ip6tnl{
+		LinkAttrs: LinkAttrs{Name: "ip6tnlbar"},
+		Local:     net.ParseIP("2001:db8::3"),
+		Remote:    net.ParseIP("2001:db8::4"),
+		Proto:     syscall.IPPROTO_IPV6,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	link, err = LinkByName("ip6tnlbar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit := link.(*Ip6tnl).EncapLimit; limit != 4 {
+		t.Fatalf("Encap limit is %d, expected the default 4", limit)
+	}
+}
+
+func TestLinkAddDelVti(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	testLinkAddDel(t, &Vti{
+		LinkAttrs: LinkAttrs{Name: "vtifoo"},
+		IKey:      0x101,
+		OKey:      0x101,
+		Local:     net.IPv4(127, 0, 0, 1),
+		Remote:    net.IPv4(127, 0, 0, 1)})
+}
+
+func TestBridgeCreationWithMulticastSnooping(t *testing.T) {
+	if os.Getenv("TRAVIS_BUILD_DIR") != "" {
+		t.Skipf("Travis CI worker Linux kernel version (3.13) is too old for this test")
+	}
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bridgeWithDefaultMcastSnoopName := "foo"
+	bridgeWithDefaultMcastSnoop := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeWithDefaultMcastSnoopName}}
+	if err := LinkAdd(bridgeWithDefaultMcastSnoop); err != nil {
+		t.Fatal(err)
+	}
+	expectMcastSnooping(t, bridgeWithDefaultMcastSnoopName, true)
+	if err := LinkDel(bridgeWithDefaultMcastSnoop); err != nil {
+		t.Fatal(err)
+	}
+
+	mcastSnoop := true
+	bridgeWithMcastSnoopOnName := "bar"
+	bridgeWithMcastSnoopOn := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeWithMcastSnoopOnName}, MulticastSnooping: &mcastSnoop}
+	if err := LinkAdd(bridgeWithMcastSnoopOn); err != nil {
+		t.Fatal(err)
+	}
+	expectMcastSnooping(t, bridgeWithMcastSnoopOnName, true)
+	if err := LinkDel(bridgeWithMcastSnoopOn); err != nil {
+		t.Fatal(err)
+	}
+
+	mcastSnoop = false
+	bridgeWithMcastSnoopOffName := "foobar"
+	bridgeWithMcastSnoopOff := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeWithMcastSnoopOffName}, MulticastSnooping: &mcastSnoop}
+	if err := LinkAdd(bridgeWithMcastSnoopOff); err != nil {
+		t.Fatal(err)
+	}
+	expectMcastSnooping(t, bridgeWithMcastSnoopOffName, false)
+	if err := LinkDel(bridgeWithMcastSnoopOff); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBridgeSetMcastSnoop(t *testing.T) {
+	if os.Getenv("TRAVIS_BUILD_DIR") != "" {
+		t.Skipf("Travis CI worker Linux kernel version (3.13) is too old for this test")
+	}
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bridgeName := "foo"
+	bridge := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeName}}
+	if err := LinkAdd(bridge); err != nil {
+		t.Fatal(err)
+	}
+	expectMcastSnooping(t, bridgeName, true)
+
+	if err := BridgeSetMcastSnoop(bridge, false); err != nil {
+		t.Fatal(err)
+	}
+	expectMcastSnooping(t, bridgeName, false)
+
+	if err := BridgeSetMcastSnoop(bridge, true); err != nil {
+		t.Fatal(err)
+	}
+	expectMcastSnooping(t, bridgeName, true)
+
+	if err := LinkDel(bridge); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBridgeMulticastQuerierRouter(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	snooping, querier := true, true
+	router := uint8(BRIDGE_MCAST_ROUTER_PERM)
+	bridge := &Bridge{
+		LinkAttrs:         LinkAttrs{Name: "foo"},
+		MulticastSnooping: &snooping,
+		MulticastQuerier:  &querier,
+		MulticastRouter:   &router,
+	}
+	if err := LinkAdd(bridge); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := link.(*Bridge)
+	if result.MulticastSnooping == nil || !*result.MulticastSnooping {
+		t.Fatal("Multicast snooping not enabled")
+	}
+	if result.MulticastQuerier == nil || !*result.MulticastQuerier {
+		t.Fatal("Multicast querier not enabled")
+	}
+	if result.MulticastRouter == nil || *result.MulticastRouter != router {
+		t.Fatalf("Multicast router is %v, expected %d", result.MulticastRouter, router)
+	}
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "bar", MasterIndex: result.Index}, PeerName: "baz"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetBrMcastRouter(veth, BRIDGE_MCAST_ROUTER_PERM); err != nil {
+		t.Fatal(err)
+	}
+	pi, err := LinkGetProtinfo(veth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pi.McastRouter != BRIDGE_MCAST_ROUTER_PERM {
+		t.Fatalf("Port multicast router is %d, expected %d", pi.McastRouter, BRIDGE_MCAST_ROUTER_PERM)
+	}
+}
+
+func expectMcastSnooping(t *testing.T, linkName string, expected bool) {
+	bridge, err := LinkByName(linkName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := *bridge.(*Bridge).MulticastSnooping; actual != expected {
+		t.Fatalf("expected %t got %t", expected, actual)
+	}
+}
+
+func TestBridgeCreationWithStpAndAgeing(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	stpState := uint32(1)
+	ageingTime := uint32(20000)
+	bridge := &Bridge{
+		LinkAttrs:  LinkAttrs{Name: "foo"},
+		StpState:   &stpState,
+		AgeingTime: &ageingTime,
+	}
+	if err := LinkAdd(bridge); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	retrieved := link.(*Bridge)
+	if retrieved.StpState == nil || *retrieved.StpState != stpState {
+		t.Fatalf("expected stp state %d got %v", stpState, retrieved.StpState)
+	}
+	if retrieved.AgeingTime == nil || *retrieved.AgeingTime != ageingTime {
+		t.Fatalf("expected ageing time %d got %v", ageingTime, retrieved.AgeingTime)
+	}
+
+	stpState = 0
+	changed, err := LinkModify(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}, StpState: &stpState, AgeingTime: &ageingTime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != "stp_state" {
+		t.Fatalf("Changed %v, expected only stp_state", changed)
+	}
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := *link.(*Bridge).StpState; actual != stpState {
+		t.Fatalf("expected stp state %d got %d", stpState, actual)
+	}
+	if link.Attrs().TxQLen != retrieved.TxQLen {
+		t.Fatalf("TxQLen is %d, expected it unchanged at %d", link.Attrs().TxQLen, retrieved.TxQLen)
+	}
+	if err := LinkDel(bridge); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBridgeCreationWithHelloTime(t *testing.T) {
+	if os.Getenv("TRAVIS_BUILD_DIR") != "" {
+		t.Skipf("Travis CI worker Linux kernel version (3.13) is too old for this test")
+	}
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bridgeWithSpecifiedHelloTimeName := "foo"
+	helloTime := uint32(300)
+	bridgeWithSpecifiedHelloTime := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeWithSpecifiedHelloTimeName}, HelloTime: &helloTime}
+	if err := LinkAdd(bridgeWithSpecifiedHelloTime); err != nil {
+		t.Fatal(err)
+	}
+
+	retrievedBridge, err := LinkByName(bridgeWithSpecifiedHelloTimeName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actualHelloTime := *retrievedBridge.(*Bridge).HelloTime
+	if actualHelloTime != helloTime {
+		t.Fatalf("expected %d got %d", helloTime, actualHelloTime)
+	}
+	if err := LinkDel(bridgeWithSpecifiedHelloTime); err != nil {
+		t.Fatal(err)
+	}
+
+	bridgeWithDefaultHelloTimeName := "bar"
+	bridgeWithDefaultHelloTime := &Bridge{LinkAttrs: LinkAttrs{Name: bridgeWithDefaultHelloTimeName}}
+	if err := LinkAdd(bridgeWithDefaultHelloTime); err != nil {
+		t.Fatal(err)
+	}
+
+	retrievedBridge, err = LinkByName(bridgeWithDefaultHelloTimeName)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1427,3 +2750,364 @@ func TestLinkByAliasWhenLinkIsNotFound(t *testing.T) {
 		t.Errorf("Error returned expected to of LinkNotFoundError type: %v", err)
 	}
 }
+
+func TestLinkStatsDelta(t *testing.T) {
+	prev := &LinkStatistics64{RxPackets: 100, TxPackets: 50, RxBytes: 10000, TxBytes: 5000, RxDropped: 1}
+	curr := &LinkStatistics64{RxPackets: 300, TxPackets: 150, RxBytes: 30000, TxBytes: 5000, RxDropped: 3}
+
+	delta := LinkStatsDelta(prev, curr)
+	expected := LinkStatistics64{RxPackets: 200, TxPackets: 100, RxBytes: 20000, RxDropped: 2}
+	if delta != expected {
+		t.Fatalf("Delta is %+v, expected %+v", delta, expected)
+	}
+
+	rate := LinkRate(delta, 2*time.Second)
+	if rate.RxPackets != 100 || rate.TxPackets != 50 || rate.RxBytes != 10000 || rate.TxBytes != 0 {
+		t.Fatalf("Rate is %+v", rate)
+	}
+	if rate := LinkRate(delta, 0); rate != (LinkRates{}) {
+		t.Fatalf("Rate over no interval is %+v, expected zero", rate)
+	}
+}
+
+func TestLinkStatsDeltaWrap(t *testing.T) {
+	prev := &LinkStatistics64{RxBytes: math.MaxUint64 - 9, TxBytes: math.MaxUint64}
+	curr := &LinkStatistics64{RxBytes: 20, TxBytes: 4}
+
+	delta := LinkStatsDelta(prev, curr)
+	if delta.RxBytes != 30 {
+		t.Fatalf("RxBytes delta is %d, expected 30", delta.RxBytes)
+	}
+	if delta.TxBytes != 5 {
+		t.Fatalf("TxBytes delta is %d, expected 5", delta.TxBytes)
+	}
+}
+
+func TestLinkSetGroupState(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	const group = 10
+	if err := LinkAdd(&Dummy{LinkAttrs{Name: "foo", Group: group}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkAdd(&Dummy{LinkAttrs{Name: "bar"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkAdd(&Dummy{LinkAttrs{Name: "baz"}}); err != nil {
+		t.Fatal(err)
+	}
+	bar, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetGroup(bar, group); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, group uint32, up bool) {
+		link, err := LinkByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link.Attrs().Group != group {
+			t.Fatalf("%s is in group %d, expected %d", name, link.Attrs().Group, group)
+		}
+		if (link.Attrs().Flags&net.FlagUp != 0) != up {
+			t.Fatalf("%s has flags %v, expected up to be %v", name, link.Attrs().Flags, up)
+		}
+	}
+
+	if err := LinkSetGroupState(group, true); err != nil {
+		t.Fatal(err)
+	}
+	check("foo", group, true)
+	check("bar", group, true)
+	check("baz", 0, false)
+
+	baz, err := LinkByName("baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(baz); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetGroupState(group, false); err != nil {
+		t.Fatal(err)
+	}
+	check("foo", group, false)
+	check("bar", group, false)
+	check("baz", 0, true)
+}
+
+func TestLinkAddDelIpoib(t *testing.T) {
+	links, err := LinkList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parent Link
+	for _, link := range links {
+		if link.Attrs().EncapType == "infiniband" {
+			parent = link
+			break
+		}
+	}
+	if parent == nil {
+		t.Skipf("No infiniband link found")
+	}
+
+	ipoib := &Ipoib{
+		LinkAttrs: LinkAttrs{Name: "ibfoo", ParentIndex: parent.Attrs().Index},
+		Pkey:      0x8001,
+		Mode:      IPOIB_MODE_CONNECTED,
+		Umcast:    1,
+	}
+	if err := LinkAdd(ipoib); err != nil {
+		t.Fatal(err)
+	}
+	defer LinkDel(ipoib)
+
+	link, err := LinkByName("ibfoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := link.(*Ipoib)
+	if !ok {
+		t.Fatal("Result of create is not an ipoib")
+	}
+	if result.Pkey != ipoib.Pkey || result.Mode != ipoib.Mode || result.Umcast != ipoib.Umcast {
+		t.Fatalf("Got pkey %#x mode %d umcast %d, expected pkey %#x mode %d umcast %d",
+			result.Pkey, result.Mode, result.Umcast, ipoib.Pkey, ipoib.Mode, ipoib.Umcast)
+	}
+}
+
+func TestLinkIpoibAttrs(t *testing.T) {
+	// pkey returns the IFLA_IPOIB_PKEY sent for ipoib, nil if left out
+	pkey := func(ipoib *Ipoib) []byte {
+		linkInfo := nl.NewRtAttr(syscall.IFLA_LINKINFO, nil)
+		nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_KIND, nl.NonZeroTerminated("ipoib"))
+		addIpoibAttrs(ipoib, linkInfo)
+
+		infos, err := nl.ParseRouteAttr(linkInfo.Serialize()[syscall.SizeofRtAttr:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, info := range infos {
+			if info.Attr.Type != nl.IFLA_INFO_DATA {
+				continue
+			}
+			data, err := nl.ParseRouteAttr(info.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, datum := range data {
+				if datum.Attr.Type == nl.IFLA_IPOIB_PKEY {
+					return datum.Value
+				}
+			}
+		}
+		return nil
+	}
+	if value := pkey(&Ipoib{Pkey: 0x8001, Mode: IPOIB_MODE_CONNECTED}); !bytes.Equal(value, nl.Uint16Attr(0x8001)) {
+		t.Fatalf("pkey attribute is %v, expected %v", value, nl.Uint16Attr(0x8001))
+	}
+	// the kernel takes the pkey of the parent when it's left out
+	if value := pkey(&Ipoib{Mode: IPOIB_MODE_CONNECTED}); value != nil {
+		t.Fatalf("pkey attribute is %v, expected none", value)
+	}
+
+	// and decoded back from a link message
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Index = 1
+	b := msg.Serialize()
+	b = append(b, nl.NewRtAttr(syscall.IFLA_IFNAME, nl.ZeroTerminated("ibfoo")).Serialize()...)
+	linkInfo := nl.NewRtAttr(syscall.IFLA_LINKINFO, nil)
+	nl.NewRtAttrChild(linkInfo, nl.IFLA_INFO_KIND, nl.ZeroTerminated("ipoib"))
+	addIpoibAttrs(&Ipoib{Pkey: 0x8001, Mode: IPOIB_MODE_CONNECTED}, linkInfo)
+	b = append(b, linkInfo.Serialize()...)
+
+	link, err := LinkDeserialize(nil, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipoib, ok := link.(*Ipoib)
+	if !ok {
+		t.Fatalf("expected *Ipoib, got %T", link)
+	}
+	if ipoib.Pkey != 0x8001 || ipoib.Mode != IPOIB_MODE_CONNECTED {
+		t.Fatalf("Got pkey %#x mode %d, expected pkey 0x8001 mode %d", ipoib.Pkey, ipoib.Mode, IPOIB_MODE_CONNECTED)
+	}
+}
+
+func TestLinkAddDelAmt(t *testing.T) {
+	tearDown := setUpNetlinkTestWithKModule(t, "amt")
+	defer tearDown()
+
+	parent := &Dummy{LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(parent); err != nil {
+		t.Fatal(err)
+	}
+	amts := []*Amt{
+		{
+			LinkAttrs:   LinkAttrs{Name: "bar", ParentIndex: parent.Index},
+			Mode:        AMT_MODE_GATEWAY,
+			RelayPort:   2268,
+			GatewayPort: 2269,
+			LocalIP:     net.IPv4(192, 0, 2, 1),
+			DiscoveryIP: net.IPv4(192, 0, 2, 2),
+		},
+		{
+			LinkAttrs:  LinkAttrs{Name: "baz", ParentIndex: parent.Index},
+			Mode:       AMT_MODE_RELAY,
+			RelayPort:  2268,
+			LocalIP:    net.IPv4(192, 0, 2, 3),
+			MaxTunnels: 64,
+		},
+	}
+	for _, amt := range amts {
+		if err := LinkAdd(amt); err != nil {
+			t.Fatal(err)
+		}
+
+		link, err := LinkByName(amt.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, ok := link.(*Amt)
+		if !ok {
+			t.Fatal("Result of create is not an amt")
+		}
+		if result.Mode != amt.Mode || result.ParentIndex != parent.Index || result.MaxTunnels != amt.MaxTunnels {
+			t.Fatalf("Got mode %d over %d with %d tunnels, expected mode %d over %d with %d tunnels",
+				result.Mode, result.ParentIndex, result.MaxTunnels, amt.Mode, parent.Index, amt.MaxTunnels)
+		}
+		if result.RelayPort != amt.RelayPort || amt.GatewayPort != 0 && result.GatewayPort != amt.GatewayPort {
+			t.Fatalf("Got relay port %d gateway port %d, expected %d and %d",
+				result.RelayPort, result.GatewayPort, amt.RelayPort, amt.GatewayPort)
+		}
+		if !result.LocalIP.Equal(amt.LocalIP) || amt.DiscoveryIP != nil && !result.DiscoveryIP.Equal(amt.DiscoveryIP) {
+			t.Fatalf("Got local %s discovery %s, expected %s and %s",
+				result.LocalIP, result.DiscoveryIP, amt.LocalIP, amt.DiscoveryIP)
+		}
+
+		if err := LinkDel(link); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLinkPhysPortVirtual(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().PhysPortName != "" || link.Attrs().PhysSwitchID != nil {
+		t.Fatalf("Virtual link has port name %q and switch id %x", link.Attrs().PhysPortName, link.Attrs().PhysSwitchID)
+	}
+}
+
+func TestLinkDeserializePhysPort(t *testing.T) {
+	switchID := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77}
+	msg := nl.NewIfInfomsg(syscall.AF_UNSPEC)
+	msg.Index = 1
+	b := msg.Serialize()
+	b = append(b, nl.NewRtAttr(syscall.IFLA_IFNAME, nl.ZeroTerminated("eth0_0")).Serialize()...)
+	b = append(b, nl.NewRtAttr(nl.IFLA_PHYS_PORT_NAME, nl.ZeroTerminated("pf0vf0")).Serialize()...)
+	b = append(b, nl.NewRtAttr(nl.IFLA_PHYS_SWITCH_ID, switchID).Serialize()...)
+
+	link, err := LinkDeserialize(nil, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().PhysPortName != "pf0vf0" {
+		t.Fatalf("PhysPortName is %q, expected pf0vf0", link.Attrs().PhysPortName)
+	}
+	if !bytes.Equal(link.Attrs().PhysSwitchID, switchID) {
+		t.Fatalf("PhysSwitchID is %x, expected %x", link.Attrs().PhysSwitchID, switchID)
+	}
+}
+
+func TestLinkPhysPortDevices(t *testing.T) {
+	links, err := LinkList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sysfs answers EOPNOTSUPP for the devices without a port name or
+	// switch id
+	found := false
+	for _, link := range links {
+		base := link.Attrs()
+		if portName, err := ioutil.ReadFile("/sys/class/net/" + base.Name + "/phys_port_name"); err == nil {
+			found = true
+			if base.PhysPortName != strings.TrimSpace(string(portName)) {
+				t.Fatalf("PhysPortName of %s is %q, expected %q", base.Name, base.PhysPortName, strings.TrimSpace(string(portName)))
+			}
+		}
+		if switchID, err := ioutil.ReadFile("/sys/class/net/" + base.Name + "/phys_switch_id"); err == nil {
+			found = true
+			if hex.EncodeToString(base.PhysSwitchID) != strings.TrimSpace(string(switchID)) {
+				t.Fatalf("PhysSwitchID of %s is %x, expected %s", base.Name, base.PhysSwitchID, strings.TrimSpace(string(switchID)))
+			}
+		}
+	}
+	if !found {
+		t.Skip("No link with a physical port name or switch id found")
+	}
+}
+
+func TestLinkParentDevVirtual(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	if err := LinkAdd(&Dummy{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().ParentDevName != "" || link.Attrs().ParentDevBusName != "" {
+		t.Fatalf("Virtual link has parent device %q on bus %q", link.Attrs().ParentDevName, link.Attrs().ParentDevBusName)
+	}
+}
+
+func TestLinkParentDevDevices(t *testing.T) {
+	links, err := LinkList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the device link of a hardware device in sysfs is its parent, which
+	// links to its bus through subsystem
+	found := false
+	for _, link := range links {
+		base := link.Attrs()
+		parent, err := os.Readlink("/sys/class/net/" + base.Name + "/device")
+		if err != nil {
+			continue
+		}
+		found = true
+		if base.ParentDevName == "" {
+			t.Skip("Kernel doesn't report the parent device of links")
+		}
+		if base.ParentDevName != filepath.Base(parent) {
+			t.Fatalf("ParentDevName of %s is %q, expected %q", base.Name, base.ParentDevName, filepath.Base(parent))
+		}
+		subsystem, err := os.Readlink("/sys/class/net/" + base.Name + "/device/subsystem")
+		if err != nil || filepath.Base(filepath.Dir(subsystem)) != "bus" {
+			continue
+		}
+		if base.ParentDevBusName != filepath.Base(subsystem) {
+			t.Fatalf("ParentDevBusName of %s is %q, expected %q", base.Name, base.ParentDevBusName, filepath.Base(subsystem))
+		}
+	}
+	if !found {
+		t.Skip("No link with a parent device found")
+	}
+}
@@ -0,0 +1,21 @@
+package netlink
+
+import (
+	"fmt"
+	"net"
+)
+
+// MdbEntry is a multicast group to port mapping of a bridge multicast
+// database. State is nl.MDB_PERMANENT for static entries, Flags are the
+// nl.MDB_FLAGS_* the kernel reports.
+type MdbEntry struct {
+	LinkIndex int // the bridge port
+	Group     net.IP
+	Vid       uint16
+	State     uint8
+	Flags     uint8
+}
+
+func (e MdbEntry) String() string {
+	return fmt.Sprintf("{Port: %d Group: %s Vid: %d State: %d}", e.LinkIndex, e.Group, e.Vid, e.State)
+}
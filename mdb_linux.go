@@ -0,0 +1,147 @@
+package netlink
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/vishvananda/netlink/nl"
+)
+
+// MdbAdd adds a multicast database entry to the bridge.
+// Equivalent to: `bridge mdb add dev $bridge port $port grp $group [permanent] [vid $vid]`
+func MdbAdd(bridge Link, entry *MdbEntry) error {
+	return pkgHandle.MdbAdd(bridge, entry)
+}
+
+// MdbAdd adds a multicast database entry to the bridge.
+// Equivalent to: `bridge mdb add dev $bridge port $port grp $group [permanent] [vid $vid]`
+func (h *Handle) MdbAdd(bridge Link, entry *MdbEntry) error {
+	req := h.newNetlinkRequest(nl.RTM_NEWMDB, syscall.NLM_F_CREATE|syscall.NLM_F_EXCL|syscall.NLM_F_ACK)
+	return h.mdbHandle(bridge, entry, req)
+}
+
+// MdbDel removes a multicast database entry from the bridge.
+// Equivalent to: `bridge mdb del dev $bridge port $port grp $group [vid $vid]`
+func MdbDel(bridge Link, entry *MdbEntry) error {
+	return pkgHandle.MdbDel(bridge, entry)
+}
+
+// MdbDel removes a multicast database entry from the bridge.
+// Equivalent to: `bridge mdb del dev $bridge port $port grp $group [vid $vid]`
+func (h *Handle) MdbDel(bridge Link, entry *MdbEntry) error {
+	req := h.newNetlinkRequest(nl.RTM_DELMDB, syscall.NLM_F_ACK)
+	return h.mdbHandle(bridge, entry, req)
+}
+
+func (h *Handle) mdbHandle(bridge Link, entry *MdbEntry, req *nl.NetlinkRequest) error {
+	base := bridge.Attrs()
+	h.ensureIndex(base)
+
+	e := &nl.BrMdbEntry{
+		Ifindex: uint32(entry.LinkIndex),
+		State:   entry.State,
+		Vid:     entry.Vid,
+	}
+	// proto is in network byte order
+	if ip4 := entry.Group.To4(); ip4 != nil {
+		e.Proto = native.Uint16(nl.BEUint16Attr(syscall.ETH_P_IP))
+		copy(e.Addr[:], ip4)
+	} else if ip6 := entry.Group.To16(); ip6 != nil {
+		e.Proto = native.Uint16(nl.BEUint16Attr(syscall.ETH_P_IPV6))
+		copy(e.Addr[:], ip6)
+	} else {
+		return fmt.Errorf("invalid multicast group %s", entry.Group)
+	}
+
+	req.AddData(nl.NewBrPortMsg(base.Index))
+	req.AddData(nl.NewRtAttr(nl.MDBA_SET_ENTRY, e.Serialize()))
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+// MdbList gets the multicast database entries of the bridge.
+// Equivalent to: `bridge mdb show dev $bridge`
+func MdbList(bridge Link) ([]MdbEntry, error) {
+	return pkgHandle.MdbList(bridge)
+}
+
+// MdbList gets the multicast database entries of the bridge.
+// Equivalent to: `bridge mdb show dev $bridge`
+func (h *Handle) MdbList(bridge Link) ([]MdbEntry, error) {
+	base := bridge.Attrs()
+	h.ensureIndex(base)
+
+	req := h.newNetlinkRequest(nl.RTM_GETMDB, syscall.NLM_F_DUMP)
+	req.AddData(nl.NewBrPortMsg(0))
+	// the kernel answers dumps with RTM_GETMDB messages
+	msgs, err := req.Execute(syscall.NETLINK_ROUTE, nl.RTM_GETMDB)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []MdbEntry
+	for _, m := range msgs {
+		// the kernel dumps the database of every bridge
+		msg := nl.DeserializeBrPortMsg(m)
+		if int(msg.Ifindex) != base.Index {
+			continue
+		}
+		entries, err := parseMdb(m[msg.Len():])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, entries...)
+	}
+	return res, nil
+}
+
+func parseMdb(b []byte) ([]MdbEntry, error) {
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return nil, err
+	}
+	var res []MdbEntry
+	for _, attr := range attrs {
+		if attr.Attr.Type != nl.MDBA_MDB {
+			continue
+		}
+		groups, err := nl.ParseRouteAttr(attr.Value)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			if group.Attr.Type != nl.MDBA_MDB_ENTRY {
+				continue
+			}
+			infos, err := nl.ParseRouteAttr(group.Value)
+			if err != nil {
+				return nil, err
+			}
+			for _, info := range infos {
+				if info.Attr.Type != nl.MDBA_MDB_ENTRY_INFO || len(info.Value) < nl.SizeofBrMdbEntry {
+					continue
+				}
+				e := nl.DeserializeBrMdbEntry(info.Value)
+				entry := MdbEntry{
+					LinkIndex: int(e.Ifindex),
+					Vid:       e.Vid,
+					State:     e.State,
+					Flags:     e.Flags,
+				}
+				switch binary.BigEndian.Uint16(nl.Uint16Attr(e.Proto)) {
+				case syscall.ETH_P_IP:
+					entry.Group = net.IP(append([]byte{}, e.Addr[:net.IPv4len]...))
+				case syscall.ETH_P_IPV6:
+					entry.Group = net.IP(append([]byte{}, e.Addr[:]...))
+				default:
+					// L2 entries are not supported
+					continue
+				}
+				res = append(res, entry)
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,75 @@
+// +build linux
+
+package netlink
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+)
+
+func TestMdbAddDel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bridge := &Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(bridge); err != nil {
+		t.Fatal(err)
+	}
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "bar", MasterIndex: bridge.Index}, PeerName: "baz"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	// the kernel only programs the database of running bridges
+	if err := LinkSetUp(bridge); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(veth); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := []*MdbEntry{
+		{LinkIndex: veth.Index, Group: net.ParseIP("239.1.1.1"), State: nl.MDB_PERMANENT},
+		{LinkIndex: veth.Index, Group: net.ParseIP("ff0e::1"), State: nl.MDB_PERMANENT},
+	}
+	for _, entry := range entries {
+		if err := MdbAdd(bridge, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := MdbList(bridge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		found := false
+		for _, e := range res {
+			if e.Group.Equal(entry.Group) && e.LinkIndex == entry.LinkIndex {
+				if e.State != nl.MDB_PERMANENT {
+					t.Fatalf("Entry %s is not permanent", e)
+				}
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("Entry %s not found in %v", entry, res)
+		}
+	}
+
+	for _, entry := range entries {
+		if err := MdbDel(bridge, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err = MdbList(bridge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range res {
+		if e.State == nl.MDB_PERMANENT {
+			t.Fatalf("Entry %s not removed", e)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package netlink
+
+import (
+	"syscall"
+
+	"github.com/vishvananda/netlink/nl"
+	"github.com/vishvananda/netns"
+)
+
+// defaultMonitorGroups are joined by MonitorAll when no groups are given.
+var defaultMonitorGroups = []int{
+	syscall.RTNLGRP_LINK,
+	syscall.RTNLGRP_IPV4_IFADDR,
+	syscall.RTNLGRP_IPV6_IFADDR,
+	syscall.RTNLGRP_IPV4_ROUTE,
+	syscall.RTNLGRP_IPV6_ROUTE,
+	syscall.RTNLGRP_NEIGH,
+}
+
+// MonitorAll takes a chan down which notifications for all the given
+// RTNLGRP_* multicast groups will be sent over a single netlink socket.
+// Updates are delivered in kernel order as LinkUpdate, AddrUpdate,
+// RouteUpdate or NeighUpdate values. If groups is empty, link, address,
+// route and neighbor groups are joined. Close the 'done' chan to stop
+// subscription.
+// Equivalent to: `ip monitor`
+func MonitorAll(ch chan<- interface{}, done <-chan struct{}, groups []int) error {
+	return monitorAll(netns.None(), netns.None(), ch, done, groups)
+}
+
+// MonitorAllAt works like MonitorAll plus it allows the caller
+// to choose the network namespace in which to subscribe (ns).
+func MonitorAllAt(ns netns.NsHandle, ch chan<- interface{}, done <-chan struct{}, groups []int) error {
+	return monitorAll(ns, netns.None(), ch, done, groups)
+}
+
+// Subscription is the socket of a MonitorSubscribe, whose RTNLGRP_*
+// multicast groups can be changed while it runs.
+type Subscription struct {
+	s *nl.NetlinkSocket
+}
+
+// AddGroup joins the RTNLGRP_* multicast group, its updates are sent
+// down the chan of the subscription.
+func (sub *Subscription) AddGroup(group int) error {
+	return sub.s.JoinGroup(uint(group))
+}
+
+// RemoveGroup leaves the RTNLGRP_* multicast group.
+func (sub *Subscription) RemoveGroup(group int) error {
+	return sub.s.LeaveGroup(uint(group))
+}
+
+// MonitorSubscribe works like MonitorAll and returns the Subscription,
+// to join or leave groups later on.
+func MonitorSubscribe(ch chan<- interface{}, done <-chan struct{}, groups []int) (*Subscription, error) {
+	return monitorSubscribe(netns.None(), netns.None(), ch, done, groups)
+}
+
+// MonitorSubscribeAt works like MonitorSubscribe plus it allows the
+// caller to choose the network namespace in which to subscribe (ns).
+func MonitorSubscribeAt(ns netns.NsHandle, ch chan<- interface{}, done <-chan struct{}, groups []int) (*Subscription, error) {
+	return monitorSubscribe(ns, netns.None(), ch, done, groups)
+}
+
+func monitorAll(newNs, curNs netns.NsHandle, ch chan<- interface{}, done <-chan struct{}, groups []int) error {
+	_, err := monitorSubscribe(newNs, curNs, ch, done, groups)
+	return err
+}
+
+func monitorSubscribe(newNs, curNs netns.NsHandle, ch chan<- interface{}, done <-chan struct{}, groups []int) (*Subscription, error) {
+	if len(groups) == 0 {
+		groups = defaultMonitorGroups
+	}
+	nlGroups := make([]uint, 0, len(groups))
+	for _, g := range groups {
+		nlGroups = append(nlGroups, uint(g))
+	}
+	s, err := nl.SubscribeAt(newNs, curNs, syscall.NETLINK_ROUTE, nlGroups...)
+	if err != nil {
+		return nil, err
+	}
+	if done != nil {
+		go func() {
+			<-done
+			s.Close()
+		}()
+	}
+	go func() {
+		defer close(ch)
+		for {
+			msgs, err := s.Receive()
+			if err != nil {
+				return
+			}
+			for _, m := range msgs {
+				update, err := parseMonitorMsg(m)
+				if err != nil || update == nil {
+					continue
+				}
+				ch <- update
+			}
+		}
+	}()
+
+	return &Subscription{s: s}, nil
+}
+
+// parseMonitorMsg decodes a multicast rtnetlink message based on its
+// type. Messages of unknown types are ignored and yield a nil update.
+func parseMonitorMsg(m syscall.NetlinkMessage) (interface{}, error) {
+	switch m.Header.Type {
+	case syscall.RTM_NEWLINK, syscall.RTM_DELLINK:
+		ifmsg := nl.DeserializeIfInfomsg(m.Data)
+		link, err := LinkDeserialize(&m.Header, m.Data)
+		if err != nil {
+			return nil, err
+		}
+		return LinkUpdate{IfInfomsg: *ifmsg, Header: m.Header, Link: link}, nil
+	case syscall.RTM_NEWADDR, syscall.RTM_DELADDR:
+		addr, _, ifindex, err := parseAddr(m.Data)
+		if err != nil {
+			return nil, err
+		}
+		return AddrUpdate{LinkAddress: *addr.IPNet,
+			LinkIndex:   ifindex,
+			NewAddr:     m.Header.Type == syscall.RTM_NEWADDR,
+			Flags:       addr.Flags,
+			Scope:       addr.Scope,
+			PreferedLft: addr.PreferedLft,
+			ValidLft:    addr.ValidLft}, nil
+	case syscall.RTM_NEWROUTE, syscall.RTM_DELROUTE:
+		route, err := deserializeRoute(m.Data)
+		if err != nil {
+			return nil, err
+		}
+		return RouteUpdate{Type: m.Header.Type, Route: route}, nil
+	case syscall.RTM_NEWNEIGH, syscall.RTM_DELNEIGH:
+		neigh, err := NeighDeserialize(m.Data)
+		if err != nil {
+			return nil, err
+		}
+		return NeighUpdate{Type: m.Header.Type, Neigh: *neigh}, nil
+	}
+	return nil, nil
+}
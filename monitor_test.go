@@ -0,0 +1,118 @@
+// +build linux
+
+package netlink
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestMonitorAll(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	ch := make(chan interface{})
+	done := make(chan struct{})
+	defer close(done)
+	groups := []int{syscall.RTNLGRP_LINK, syscall.RTNLGRP_IPV4_IFADDR}
+	if err := MonitorAll(ch, done, groups); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	addr := &Addr{IPNet: &net.IPNet{IP: net.IPv4(127, 0, 0, 2), Mask: net.CIDRMask(24, 32)}}
+	if err := AddrAdd(link, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotLink, gotAddr bool
+	timeout := time.After(time.Minute)
+	for !gotLink || !gotAddr {
+		select {
+		case update := <-ch:
+			switch u := update.(type) {
+			case LinkUpdate:
+				if u.Link.Attrs().Name == "lo" {
+					gotLink = true
+				}
+			case AddrUpdate:
+				if u.NewAddr && u.LinkAddress.IP.Equal(addr.IP) {
+					gotAddr = true
+				}
+			default:
+				t.Fatalf("Unexpected update type %T", update)
+			}
+		case <-timeout:
+			t.Fatalf("Updates not received as expected: link %v, addr %v", gotLink, gotAddr)
+		}
+	}
+}
+
+func TestMonitorSubscribeAddGroup(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	ch := make(chan interface{})
+	done := make(chan struct{})
+	defer close(done)
+	sub, err := MonitorSubscribe(ch, done, []int{syscall.RTNLGRP_LINK})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	addr := &Addr{IPNet: &net.IPNet{IP: net.IPv4(127, 0, 0, 2), Mask: net.CIDRMask(24, 32)}}
+	if err := AddrAdd(link, addr); err != nil {
+		t.Fatal(err)
+	}
+	// the address group isn't joined yet, lo going up arrives alone
+	timeout := time.After(time.Minute)
+	for gotLink := false; !gotLink; {
+		select {
+		case update := <-ch:
+			switch u := update.(type) {
+			case LinkUpdate:
+				gotLink = u.Link.Attrs().Name == "lo"
+			default:
+				t.Fatalf("Unexpected update %T before joining its group", update)
+			}
+		case <-timeout:
+			t.Fatal("Link update not received")
+		}
+	}
+
+	if err := sub.AddGroup(syscall.RTNLGRP_IPV4_IFADDR); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddrDel(link, addr); err != nil {
+		t.Fatal(err)
+	}
+	for gotAddr := false; !gotAddr; {
+		select {
+		case update := <-ch:
+			if u, ok := update.(AddrUpdate); ok {
+				gotAddr = !u.NewAddr && u.LinkAddress.IP.Equal(addr.IP)
+			}
+		case <-timeout:
+			t.Fatal("Address update not received after joining its group")
+		}
+	}
+
+	if err := sub.RemoveGroup(syscall.RTNLGRP_IPV4_IFADDR); err != nil {
+		t.Fatal(err)
+	}
+}
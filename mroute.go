@@ -0,0 +1,36 @@
+package netlink
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// MrouteOif is an outgoing interface of a multicast route. Packets are
+// only forwarded on it when their TTL is above the TTL threshold.
+type MrouteOif struct {
+	LinkIndex int
+	TTL       int
+}
+
+// Mroute is an IPv4 multicast forwarding cache entry, the traffic from
+// Src to Group arriving on ILinkIndex is forwarded to the Oifs. All the
+// interfaces must be vifs of the multicast routing socket of the table.
+type Mroute struct {
+	Src        net.IP // nil for a (*,G) entry
+	Group      net.IP
+	ILinkIndex int
+	Oifs       []MrouteOif
+	Table      int       // RT_TABLE_DEFAULT when zero
+	Unresolved bool      // read only, the kernel is waiting for a resolution
+	Stats      *MfcStats // read only
+}
+
+func (m Mroute) String() string {
+	oifs := make([]string, 0, len(m.Oifs))
+	for _, oif := range m.Oifs {
+		oifs = append(oifs, fmt.Sprintf("%d:%d", oif.LinkIndex, oif.TTL))
+	}
+	return fmt.Sprintf("{Src: %s Group: %s Iif: %d Oifs: [%s] Table: %d}",
+		m.Src, m.Group, m.ILinkIndex, strings.Join(oifs, " "), m.Table)
+}
@@ -0,0 +1,211 @@
+package netlink
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/vishvananda/netlink/nl"
+	"github.com/vishvananda/netns"
+)
+
+// MrouteAdd adds a multicast route to the system. The outgoing interfaces
+// are mapped to their vifs through procfs, so they can only be set on
+// routes of the default table.
+// Equivalent to: `smcroute -a $iif $src $group $oif...`
+func MrouteAdd(mroute *Mroute) error {
+	return pkgHandle.MrouteAdd(mroute)
+}
+
+// MrouteAdd adds a multicast route to the system. The outgoing interfaces
+// are mapped to their vifs through procfs, so they can only be set on
+// routes of the default table.
+// Equivalent to: `smcroute -a $iif $src $group $oif...`
+func (h *Handle) MrouteAdd(mroute *Mroute) error {
+	req := h.newNetlinkRequest(syscall.RTM_NEWROUTE, syscall.NLM_F_CREATE|syscall.NLM_F_ACK)
+	return h.mrouteHandle(mroute, req)
+}
+
+// MrouteDel removes a multicast route from the system.
+// Equivalent to: `smcroute -r $iif $src $group`
+func MrouteDel(mroute *Mroute) error {
+	return pkgHandle.MrouteDel(mroute)
+}
+
+// MrouteDel removes a multicast route from the system.
+// Equivalent to: `smcroute -r $iif $src $group`
+func (h *Handle) MrouteDel(mroute *Mroute) error {
+	req := h.newNetlinkRequest(syscall.RTM_DELROUTE, syscall.NLM_F_ACK)
+	return h.mrouteHandle(mroute, req)
+}
+
+func (h *Handle) mrouteHandle(mroute *Mroute, req *nl.NetlinkRequest) error {
+	group := mroute.Group.To4()
+	if group == nil {
+		return fmt.Errorf("multicast group %s is not an IPv4 address", mroute.Group)
+	}
+
+	// The kernel takes the table from RTA_TABLE only
+	table := mroute.Table
+	if table == 0 {
+		table = syscall.RT_TABLE_DEFAULT
+	}
+	msg := nl.NewRtMsg()
+	msg.Family = nl.RTNL_FAMILY_IPMR
+	msg.Type = syscall.RTN_MULTICAST
+	// only static and mrouted entries are accepted
+	msg.Protocol = syscall.RTPROT_STATIC
+	msg.Dst_len = 32
+	if table < 256 {
+		msg.Table = uint8(table)
+	} else {
+		msg.Table = syscall.RT_TABLE_UNSPEC
+	}
+
+	var rtAttrs []*nl.RtAttr
+	if mroute.Src != nil {
+		src := mroute.Src.To4()
+		if src == nil {
+			return fmt.Errorf("multicast source %s is not an IPv4 address", mroute.Src)
+		}
+		msg.Src_len = 32
+		rtAttrs = append(rtAttrs, nl.NewRtAttr(syscall.RTA_SRC, src))
+	}
+	rtAttrs = append(rtAttrs, nl.NewRtAttr(syscall.RTA_DST, group))
+	rtAttrs = append(rtAttrs, nl.NewRtAttr(syscall.RTA_IIF, nl.Uint32Attr(uint32(mroute.ILinkIndex))))
+	rtAttrs = append(rtAttrs, nl.NewRtAttr(syscall.RTA_TABLE, nl.Uint32Attr(uint32(table))))
+	if len(mroute.Oifs) > 0 {
+		ttls, err := h.mrouteVifTTLs(mroute.Oifs, table)
+		if err != nil {
+			return err
+		}
+		// The kernel ignores the ifindex of the nexthops, the i-th
+		// one carries the TTL threshold of vif i, 255 disables it.
+		buf := []byte{}
+		for _, ttl := range ttls {
+			rtnh := &nl.RtNexthop{
+				RtNexthop: syscall.RtNexthop{Hops: ttl},
+			}
+			buf = append(buf, rtnh.Serialize()...)
+		}
+		rtAttrs = append(rtAttrs, nl.NewRtAttr(syscall.RTA_MULTIPATH, buf))
+	}
+
+	req.AddData(msg)
+	for _, attr := range rtAttrs {
+		req.AddData(attr)
+	}
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+// mrouteVifTTLs returns the TTL thresholds of the oifs indexed by their
+// vif. rtnetlink has no way to list the vifs, they are read from
+// ip_mr_vif in procfs which only shows the default table of the network
+// namespace of the reading thread.
+func (h *Handle) mrouteVifTTLs(oifs []MrouteOif, table int) ([]uint8, error) {
+	if table != syscall.RT_TABLE_DEFAULT {
+		return nil, fmt.Errorf("outgoing interfaces can only be resolved to vifs in the default table")
+	}
+	data, err := h.readMrouteVifs()
+	if err != nil {
+		return nil, err
+	}
+	vifs := map[string]int{}
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		vif, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		vifs[fields[1]] = vif
+	}
+
+	var ttls []uint8
+	for _, oif := range oifs {
+		link, err := h.LinkByIndex(oif.LinkIndex)
+		if err != nil {
+			return nil, err
+		}
+		vif, ok := vifs[link.Attrs().Name]
+		if !ok {
+			return nil, fmt.Errorf("link %s is not a multicast vif", link.Attrs().Name)
+		}
+		for len(ttls) <= vif {
+			ttls = append(ttls, 255)
+		}
+		ttls[vif] = uint8(oif.TTL)
+	}
+	return ttls, nil
+}
+
+// readMrouteVifs reads ip_mr_vif in the network namespace of the handle,
+// moving the locked calling thread into it if the handle has one of its
+// own. /proc/self/net shows the namespace of the thread group leader, so
+// the entry of the calling thread is read.
+func (h *Handle) readMrouteVifs() ([]byte, error) {
+	if h.sockets != nil && h.ns.IsOpen() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		curNs, err := netns.Get()
+		if err != nil {
+			return nil, err
+		}
+		defer curNs.Close()
+		if err := netns.Set(h.ns); err != nil {
+			return nil, err
+		}
+		defer netns.Set(curNs)
+	}
+	return ioutil.ReadFile(fmt.Sprintf("/proc/self/task/%d/net/ip_mr_vif", syscall.Gettid()))
+}
+
+// MrouteList gets the multicast routes of all the tables.
+// Equivalent to: `ip mroute show table all`
+func MrouteList() ([]Mroute, error) {
+	return pkgHandle.MrouteList()
+}
+
+// MrouteList gets the multicast routes of all the tables.
+// Equivalent to: `ip mroute show table all`
+func (h *Handle) MrouteList() ([]Mroute, error) {
+	req := h.routeDumpRequest(nl.RTNL_FAMILY_IPMR, &Route{Table: syscall.RT_TABLE_UNSPEC}, RT_FILTER_TABLE)
+	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWROUTE)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Mroute
+	for _, m := range msgs {
+		route, err := deserializeRoute(m)
+		if err != nil {
+			return nil, err
+		}
+		if route.Dst == nil {
+			continue
+		}
+		mroute := Mroute{
+			Group:      route.Dst.IP,
+			ILinkIndex: route.ILinkIndex,
+			Table:      route.Table,
+			Unresolved: route.Flags&nl.RTNH_F_UNRESOLVED != 0,
+			Stats:      route.MfcStats,
+		}
+		// (*,G) entries are reported with a zero source
+		if route.SrcNet != nil && !route.SrcNet.IP.Equal(net.IPv4zero) {
+			mroute.Src = route.SrcNet.IP
+		}
+		for _, nh := range route.MultiPath {
+			mroute.Oifs = append(mroute.Oifs, MrouteOif{LinkIndex: nh.LinkIndex, TTL: nh.Hops})
+		}
+		res = append(res, mroute)
+	}
+	return res, nil
+}
@@ -0,0 +1,106 @@
+// +build linux
+
+package netlink
+
+import (
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/vishvananda/netns"
+)
+
+func TestMrouteAddDel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	fd, vifs := setUpMrouteVifs(t)
+	defer syscall.Close(fd)
+
+	mroute := &Mroute{
+		Src:        net.IPv4(10, 0, 0, 1),
+		Group:      net.IPv4(239, 1, 1, 1),
+		ILinkIndex: vifs[0],
+		Oifs:       []MrouteOif{{LinkIndex: vifs[1], TTL: 2}},
+	}
+	if err := MrouteAdd(mroute); err != nil {
+		t.Fatal(err)
+	}
+
+	mroutes, err := MrouteList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mroutes) != 1 {
+		t.Fatalf("Expected 1 multicast route, got %v", mroutes)
+	}
+	found := mroutes[0]
+	if !found.Src.Equal(mroute.Src) || !found.Group.Equal(mroute.Group) {
+		t.Fatalf("Multicast route is %s, expected %s", found, mroute)
+	}
+	if found.ILinkIndex != vifs[0] || found.Table != syscall.RT_TABLE_DEFAULT || found.Unresolved {
+		t.Fatalf("Multicast route is %s, expected %s", found, mroute)
+	}
+	if len(found.Oifs) != 1 || found.Oifs[0] != mroute.Oifs[0] {
+		t.Fatalf("Multicast route forwards to %v, expected %v", found.Oifs, mroute.Oifs)
+	}
+	if found.Stats == nil {
+		t.Fatalf("Multicast route has no stats: %s", found)
+	}
+
+	if err := MrouteDel(mroute); err != nil {
+		t.Fatal(err)
+	}
+	mroutes, err = MrouteList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mroutes) != 0 {
+		t.Fatalf("Multicast route not removed: %v", mroutes)
+	}
+}
+
+func TestHandleMrouteAddAt(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	fd, vifs := setUpMrouteVifs(t)
+	defer syscall.Close(fd)
+
+	ns, err := netns.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ns.Close()
+	h, err := NewHandleAt(ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	// the vifs must be resolved in the handle's namespace, not in the
+	// one of the calling thread
+	otherNs, err := netns.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer otherNs.Close()
+	defer netns.Set(ns)
+
+	mroute := &Mroute{
+		Src:        net.IPv4(10, 0, 0, 1),
+		Group:      net.IPv4(239, 1, 1, 1),
+		ILinkIndex: vifs[0],
+		Oifs:       []MrouteOif{{LinkIndex: vifs[1], TTL: 2}},
+	}
+	if err := h.MrouteAdd(mroute); err != nil {
+		t.Fatal(err)
+	}
+	mroutes, err := h.MrouteList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mroutes) != 1 || len(mroutes[0].Oifs) != 1 || mroutes[0].Oifs[0] != mroute.Oifs[0] {
+		t.Fatalf("Multicast routes are %v, expected %s", mroutes, mroute)
+	}
+}
@@ -3,6 +3,7 @@ package netlink
 import (
 	"fmt"
 	"net"
+	"time"
 )
 
 // Neigh represents a link layer neighbor from netlink.
@@ -15,9 +16,55 @@ type Neigh struct {
 	IP           net.IP
 	HardwareAddr net.HardwareAddr
 	LLIPAddr     net.IP //Used in the case of NHRP
+	Vlan         uint16 // bridge FDB entries only
+	VNI          uint32 // vxlan FDB entries only
+	Port         uint16 // vxlan FDB entries only, the remote udp port
 }
 
 // String returns $ip/$hwaddr $label
 func (neigh *Neigh) String() string {
 	return fmt.Sprintf("%s %s", neigh.IP, neigh.HardwareAddr)
 }
+
+// NeighUpdate is sent when a neighbor changes - type is RTM_NEWNEIGH or RTM_DELNEIGH
+type NeighUpdate struct {
+	Type uint16
+	Neigh
+}
+
+// NeighNotFoundError is returned by NeighGet when no entry exists
+// for the requested address, so that callers can distinguish it from
+// other errors.
+type NeighNotFoundError struct {
+	error
+}
+
+// NeighTable represents a neighbor table, e.g. arp_cache or ndisc_cache,
+// or the parameters the table uses for one link. The thresholds and the
+// garbage collection interval are only reported for the table itself,
+// whose Parms hold the defaults and have a zero LinkIndex.
+type NeighTable struct {
+	Family     int
+	Name       string
+	Thresh1    uint32
+	Thresh2    uint32
+	Thresh3    uint32
+	GcInterval time.Duration
+	Parms      NeighTableParms
+}
+
+// NeighTableParms holds the tunable parameters of a neighbor table, the
+// same as the neigh sysctls. Zero values are left unchanged by
+// NeighTableSet.
+type NeighTableParms struct {
+	LinkIndex         int
+	ReachableTime     time.Duration // read only, derived from BaseReachableTime
+	BaseReachableTime time.Duration
+	RetransTime       time.Duration
+	GcStaleTime       time.Duration
+	DelayProbeTime    time.Duration
+	QueueLenBytes     uint32
+	AppProbes         uint32
+	UcastProbes       uint32
+	McastProbes       uint32
+}
@@ -1,8 +1,11 @@
 package netlink
 
 import (
+	"errors"
+	"fmt"
 	"net"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/vishvananda/netlink/nl"
@@ -21,6 +24,45 @@ const (
 	NDA_MAX = NDA_IFINDEX
 )
 
+// Neighbor table attributes
+const (
+	NDTA_UNSPEC = iota
+	NDTA_NAME
+	NDTA_THRESH1
+	NDTA_THRESH2
+	NDTA_THRESH3
+	NDTA_CONFIG
+	NDTA_PARMS
+	NDTA_STATS
+	NDTA_GC_INTERVAL
+	NDTA_PAD
+	NDTA_MAX = NDTA_PAD
+)
+
+// Neighbor table parameters, nested in NDTA_PARMS
+const (
+	NDTPA_UNSPEC = iota
+	NDTPA_IFINDEX
+	NDTPA_REFCNT
+	NDTPA_REACHABLE_TIME
+	NDTPA_BASE_REACHABLE_TIME
+	NDTPA_RETRANS_TIME
+	NDTPA_GC_STALETIME
+	NDTPA_DELAY_PROBE_TIME
+	NDTPA_QUEUE_LEN
+	NDTPA_APP_PROBES
+	NDTPA_UCAST_PROBES
+	NDTPA_MCAST_PROBES
+	NDTPA_ANYCAST_DELAY
+	NDTPA_PROXY_DELAY
+	NDTPA_PROXY_QLEN
+	NDTPA_LOCKTIME
+	NDTPA_QUEUE_LENBYTES
+	NDTPA_MCAST_REPROBES
+	NDTPA_PAD
+	NDTPA_MAX = NDTPA_PAD
+)
+
 // Neighbor Cache Entry States.
 const (
 	NUD_NONE       = 0x00
@@ -64,6 +106,20 @@ func (msg *Ndmsg) Len() int {
 	return int(unsafe.Sizeof(*msg))
 }
 
+type Ndtmsg struct {
+	Family uint8
+	Pad1   uint8
+	Pad2   uint16
+}
+
+func (msg *Ndtmsg) Serialize() []byte {
+	return (*(*[unsafe.Sizeof(*msg)]byte)(unsafe.Pointer(msg)))[:]
+}
+
+func (msg *Ndtmsg) Len() int {
+	return int(unsafe.Sizeof(*msg))
+}
+
 // NeighAdd will add an IP to MAC mapping to the ARP table
 // Equivalent to: `ip neigh add ....`
 func NeighAdd(neigh *Neigh) error {
@@ -77,12 +133,16 @@ func (h *Handle) NeighAdd(neigh *Neigh) error {
 }
 
 // NeighSet will add or replace an IP to MAC mapping to the ARP table
+// An existing entry keeps its place in the cache and takes the given NUD
+// state, e.g. NUD_STALE to force it to be revalidated.
 // Equivalent to: `ip neigh replace....`
 func NeighSet(neigh *Neigh) error {
 	return pkgHandle.NeighSet(neigh)
 }
 
 // NeighSet will add or replace an IP to MAC mapping to the ARP table
+// An existing entry keeps its place in the cache and takes the given NUD
+// state, e.g. NUD_STALE to force it to be revalidated.
 // Equivalent to: `ip neigh replace....`
 func (h *Handle) NeighSet(neigh *Neigh) error {
 	return h.neighAdd(neigh, syscall.NLM_F_CREATE|syscall.NLM_F_REPLACE)
@@ -160,6 +220,16 @@ func neighHandle(neigh *Neigh, req *nl.NetlinkRequest) error {
 		req.AddData(hwData)
 	}
 
+	if neigh.Vlan != 0 {
+		req.AddData(nl.NewRtAttr(NDA_VLAN, nl.Uint16Attr(neigh.Vlan)))
+	}
+	if neigh.VNI != 0 {
+		req.AddData(nl.NewRtAttr(NDA_VNI, nl.Uint32Attr(neigh.VNI)))
+	}
+	if neigh.Port != 0 {
+		req.AddData(nl.NewRtAttr(NDA_PORT, htons(neigh.Port)))
+	}
+
 	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
 	return err
 }
@@ -192,6 +262,42 @@ func (h *Handle) NeighProxyList(linkIndex, family int) ([]Neigh, error) {
 	return h.neighList(linkIndex, family, NTF_PROXY)
 }
 
+// NeighGet gets the neighbor entry for ip on the given link.
+// Equivalent to: `ip neighbor get $ip dev $link`
+func NeighGet(linkIndex int, ip net.IP) (*Neigh, error) {
+	return pkgHandle.NeighGet(linkIndex, ip)
+}
+
+// NeighGet gets the neighbor entry for ip on the given link.
+// Equivalent to: `ip neighbor get $ip dev $link`
+func (h *Handle) NeighGet(linkIndex int, ip net.IP) (*Neigh, error) {
+	req := h.newNetlinkRequest(syscall.RTM_GETNEIGH, 0)
+	msg := Ndmsg{
+		Family: uint8(nl.GetIPFamily(ip)),
+		Index:  uint32(linkIndex),
+	}
+	req.AddData(&msg)
+
+	ipData := ip.To4()
+	if ipData == nil {
+		ipData = ip.To16()
+	}
+	req.AddData(nl.NewRtAttr(NDA_DST, ipData))
+
+	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWNEIGH)
+	if err != nil {
+		if errors.Is(err, syscall.ENOENT) {
+			return nil, NeighNotFoundError{fmt.Errorf("Neighbor %s not found", ip)}
+		}
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, NeighNotFoundError{fmt.Errorf("Neighbor %s not found", ip)}
+	}
+
+	return NeighDeserialize(msgs[0])
+}
+
 func (h *Handle) neighList(linkIndex, family, flags int) ([]Neigh, error) {
 	req := h.newNetlinkRequest(syscall.RTM_GETNEIGH, syscall.NLM_F_DUMP)
 	msg := Ndmsg{
@@ -268,8 +374,185 @@ func NeighDeserialize(m []byte) (*Neigh, error) {
 			} else {
 				neigh.HardwareAddr = net.HardwareAddr(attr.Value)
 			}
+		case NDA_VLAN:
+			neigh.Vlan = native.Uint16(attr.Value[0:2])
+		case NDA_VNI:
+			neigh.VNI = native.Uint32(attr.Value[0:4])
+		case NDA_PORT:
+			neigh.Port = ntohs(attr.Value[0:2])
 		}
 	}
 
 	return &neigh, nil
 }
+
+// NeighTableList gets the neighbor tables of the given family and the
+// parameters they use for each link.
+// Equivalent to: `ip ntable show`
+func NeighTableList(family int) ([]NeighTable, error) {
+	return pkgHandle.NeighTableList(family)
+}
+
+// NeighTableList gets the neighbor tables of the given family and the
+// parameters they use for each link.
+// Equivalent to: `ip ntable show`
+func (h *Handle) NeighTableList(family int) ([]NeighTable, error) {
+	req := h.newNetlinkRequest(syscall.RTM_GETNEIGHTBL, syscall.NLM_F_DUMP)
+	req.AddData(&Ndtmsg{Family: uint8(family)})
+
+	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWNEIGHTBL)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []NeighTable
+	for _, m := range msgs {
+		table, err := neighTableDeserialize(m)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *table)
+	}
+	return res, nil
+}
+
+// NeighTableSet changes the thresholds and the garbage collection interval
+// of the table, and its parameters for Parms.LinkIndex, or its default
+// parameters when it is zero. Only the non zero fields are changed. The
+// thresholds and the interval can only be changed in the initial network
+// namespace.
+// Equivalent to: `ip ntable change name $name ...`
+func NeighTableSet(table *NeighTable) error {
+	return pkgHandle.NeighTableSet(table)
+}
+
+// NeighTableSet changes the thresholds and the garbage collection interval
+// of the table, and its parameters for Parms.LinkIndex, or its default
+// parameters when it is zero. Only the non zero fields are changed. The
+// thresholds and the interval can only be changed in the initial network
+// namespace.
+// Equivalent to: `ip ntable change name $name ...`
+func (h *Handle) NeighTableSet(table *NeighTable) error {
+	req := h.newNetlinkRequest(syscall.RTM_SETNEIGHTBL, syscall.NLM_F_ACK)
+	req.AddData(&Ndtmsg{Family: uint8(table.Family)})
+	req.AddData(nl.NewRtAttr(NDTA_NAME, nl.ZeroTerminated(table.Name)))
+
+	for _, t := range []struct {
+		typ int
+		v   uint32
+	}{
+		{NDTA_THRESH1, table.Thresh1},
+		{NDTA_THRESH2, table.Thresh2},
+		{NDTA_THRESH3, table.Thresh3},
+	} {
+		if t.v != 0 {
+			req.AddData(nl.NewRtAttr(t.typ, nl.Uint32Attr(t.v)))
+		}
+	}
+	if table.GcInterval != 0 {
+		req.AddData(nl.NewRtAttr(NDTA_GC_INTERVAL, nl.Uint64Attr(uint64(table.GcInterval/time.Millisecond))))
+	}
+
+	p := &table.Parms
+	var parms []*nl.RtAttr
+	if p.LinkIndex != 0 {
+		parms = append(parms, nl.NewRtAttr(NDTPA_IFINDEX, nl.Uint32Attr(uint32(p.LinkIndex))))
+	}
+	for _, d := range []struct {
+		typ int
+		v   time.Duration
+	}{
+		{NDTPA_BASE_REACHABLE_TIME, p.BaseReachableTime},
+		{NDTPA_RETRANS_TIME, p.RetransTime},
+		{NDTPA_GC_STALETIME, p.GcStaleTime},
+		{NDTPA_DELAY_PROBE_TIME, p.DelayProbeTime},
+	} {
+		if d.v != 0 {
+			parms = append(parms, nl.NewRtAttr(d.typ, nl.Uint64Attr(uint64(d.v/time.Millisecond))))
+		}
+	}
+	for _, c := range []struct {
+		typ int
+		v   uint32
+	}{
+		{NDTPA_QUEUE_LENBYTES, p.QueueLenBytes},
+		{NDTPA_APP_PROBES, p.AppProbes},
+		{NDTPA_UCAST_PROBES, p.UcastProbes},
+		{NDTPA_MCAST_PROBES, p.McastProbes},
+	} {
+		if c.v != 0 {
+			parms = append(parms, nl.NewRtAttr(c.typ, nl.Uint32Attr(c.v)))
+		}
+	}
+	if len(parms) > 0 {
+		nest := nl.NewRtAttr(NDTA_PARMS, nil)
+		for _, attr := range parms {
+			nest.AddChild(attr)
+		}
+		req.AddData(nest)
+	}
+
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+func neighTableDeserialize(m []byte) (*NeighTable, error) {
+	msg := (*Ndtmsg)(unsafe.Pointer(&m[0:unsafe.Sizeof(Ndtmsg{})][0]))
+	table := &NeighTable{Family: int(msg.Family)}
+
+	attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+	if err != nil {
+		return nil, err
+	}
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case NDTA_NAME:
+			table.Name = nl.BytesToString(attr.Value)
+		case NDTA_THRESH1:
+			table.Thresh1 = native.Uint32(attr.Value[0:4])
+		case NDTA_THRESH2:
+			table.Thresh2 = native.Uint32(attr.Value[0:4])
+		case NDTA_THRESH3:
+			table.Thresh3 = native.Uint32(attr.Value[0:4])
+		case NDTA_GC_INTERVAL:
+			table.GcInterval = time.Duration(native.Uint64(attr.Value[0:8])) * time.Millisecond
+		case NDTA_PARMS:
+			parms, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return nil, err
+			}
+			parseNeighTableParms(&table.Parms, parms)
+		}
+	}
+	return table, nil
+}
+
+func parseNeighTableParms(p *NeighTableParms, attrs []syscall.NetlinkRouteAttr) {
+	msecs := func(b []byte) time.Duration {
+		return time.Duration(native.Uint64(b[0:8])) * time.Millisecond
+	}
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case NDTPA_IFINDEX:
+			p.LinkIndex = int(native.Uint32(attr.Value[0:4]))
+		case NDTPA_REACHABLE_TIME:
+			p.ReachableTime = msecs(attr.Value)
+		case NDTPA_BASE_REACHABLE_TIME:
+			p.BaseReachableTime = msecs(attr.Value)
+		case NDTPA_RETRANS_TIME:
+			p.RetransTime = msecs(attr.Value)
+		case NDTPA_GC_STALETIME:
+			p.GcStaleTime = msecs(attr.Value)
+		case NDTPA_DELAY_PROBE_TIME:
+			p.DelayProbeTime = msecs(attr.Value)
+		case NDTPA_QUEUE_LENBYTES:
+			p.QueueLenBytes = native.Uint32(attr.Value[0:4])
+		case NDTPA_APP_PROBES:
+			p.AppProbes = native.Uint32(attr.Value[0:4])
+		case NDTPA_UCAST_PROBES:
+			p.UcastProbes = native.Uint32(attr.Value[0:4])
+		case NDTPA_MCAST_PROBES:
+			p.McastProbes = native.Uint32(attr.Value[0:4])
+		}
+	}
+}
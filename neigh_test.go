@@ -4,7 +4,9 @@ package netlink
 
 import (
 	"net"
+	"syscall"
 	"testing"
+	"time"
 )
 
 type arpEntry struct {
@@ -249,3 +251,211 @@ func TestNeighAddDelProxy(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestNeighGet(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	ensureIndex(veth.Attrs())
+
+	ip := net.ParseIP("10.99.0.1")
+	mac := parseMAC("aa:bb:cc:dd:00:01")
+	if err := NeighAdd(&Neigh{
+		LinkIndex:    veth.Index,
+		State:        NUD_PERMANENT,
+		IP:           ip,
+		HardwareAddr: mac,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	neigh, err := NeighGet(veth.Index, ip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !neigh.IP.Equal(ip) || neigh.HardwareAddr.String() != mac.String() {
+		t.Fatalf("Got wrong neighbor: %v", neigh)
+	}
+	if neigh.State != NUD_PERMANENT {
+		t.Fatalf("Neighbor state is %d, expected %d", neigh.State, NUD_PERMANENT)
+	}
+
+	_, err = NeighGet(veth.Index, net.ParseIP("10.99.0.2"))
+	if _, ok := err.(NeighNotFoundError); !ok {
+		t.Fatalf("Expected NeighNotFoundError, got %v", err)
+	}
+}
+
+func TestNeighSetState(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	ensureIndex(veth.Attrs())
+
+	neigh := &Neigh{
+		LinkIndex:    veth.Index,
+		State:        NUD_REACHABLE,
+		IP:           net.ParseIP("10.99.0.1"),
+		HardwareAddr: parseMAC("aa:bb:cc:dd:00:01"),
+	}
+	if err := NeighAdd(neigh); err != nil {
+		t.Fatal(err)
+	}
+
+	neigh.State = NUD_STALE
+	if err := NeighSet(neigh); err != nil {
+		t.Fatal(err)
+	}
+
+	dump, err := NeighList(veth.Index, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dump) != 1 {
+		t.Fatalf("Expected 1 neighbor, got %d", len(dump))
+	}
+	if dump[0].State != NUD_STALE {
+		t.Fatalf("Neighbor state is %d, expected %d", dump[0].State, NUD_STALE)
+	}
+}
+
+func TestNeighTableListSet(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	ensureIndex(veth.Attrs())
+
+	findTable := func(linkIndex int) *NeighTable {
+		tables, err := NeighTableList(syscall.AF_INET)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range tables {
+			if tables[i].Name == "arp_cache" && tables[i].Parms.LinkIndex == linkIndex {
+				return &tables[i]
+			}
+		}
+		return nil
+	}
+
+	table := findTable(0)
+	if table == nil {
+		t.Fatal("arp_cache table not found")
+	}
+	if table.Thresh3 == 0 || table.Thresh3 < table.Thresh1 {
+		t.Fatalf("Bad arp_cache thresholds %d/%d/%d", table.Thresh1, table.Thresh2, table.Thresh3)
+	}
+	if table.Parms.BaseReachableTime == 0 {
+		t.Fatal("arp_cache base reachable time not decoded")
+	}
+
+	if err := NeighTableSet(&NeighTable{
+		Family: syscall.AF_INET,
+		Name:   "arp_cache",
+		Parms: NeighTableParms{
+			LinkIndex:         veth.Index,
+			BaseReachableTime: 42 * time.Second,
+			UcastProbes:       7,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	table = findTable(veth.Index)
+	if table == nil {
+		t.Fatal("arp_cache parameters of foo not found")
+	}
+	if table.Parms.BaseReachableTime != 42*time.Second || table.Parms.UcastProbes != 7 {
+		t.Fatalf("arp_cache parameters of foo are %+v", table.Parms)
+	}
+}
+
+func TestNeighFdbVlanVniPort(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bridge := &Bridge{LinkAttrs: LinkAttrs{Name: "br0"}}
+	if err := LinkAdd(bridge); err != nil {
+		t.Fatal(err)
+	}
+	vxlan := &Vxlan{
+		LinkAttrs: LinkAttrs{Name: "vxlan0", MasterIndex: bridge.Index},
+		VxlanId:   10,
+		Port:      4789,
+		Learning:  false,
+	}
+	if err := LinkAdd(vxlan); err != nil {
+		t.Fatal(err)
+	}
+	if err := BridgeVlanAdd(vxlan, 20, false, false, false, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// the vlan is stored by the bridge, the vni and port by vxlan
+	bridgeEntry := &Neigh{
+		LinkIndex:    vxlan.Index,
+		Family:       syscall.AF_BRIDGE,
+		State:        NUD_PERMANENT,
+		Flags:        NTF_MASTER,
+		HardwareAddr: parseMAC("aa:bb:cc:dd:00:01"),
+		Vlan:         20,
+	}
+	if err := NeighAppend(bridgeEntry); err != nil {
+		t.Fatal(err)
+	}
+	vxlanEntry := &Neigh{
+		LinkIndex:    vxlan.Index,
+		Family:       syscall.AF_BRIDGE,
+		State:        NUD_PERMANENT,
+		Flags:        NTF_SELF,
+		IP:           net.ParseIP("198.51.100.1"),
+		HardwareAddr: parseMAC("aa:bb:cc:dd:00:02"),
+		VNI:          30,
+		Port:         5000,
+	}
+	if err := NeighAppend(vxlanEntry); err != nil {
+		t.Fatal(err)
+	}
+
+	neighs, err := NeighList(vxlan.Index, syscall.AF_BRIDGE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var foundBridge, foundVxlan bool
+	for _, n := range neighs {
+		switch n.HardwareAddr.String() {
+		case bridgeEntry.HardwareAddr.String():
+			if n.Vlan != bridgeEntry.Vlan {
+				t.Fatalf("Bridge FDB entry vlan is %d, expected %d", n.Vlan, bridgeEntry.Vlan)
+			}
+			foundBridge = true
+		case vxlanEntry.HardwareAddr.String():
+			if n.VNI != vxlanEntry.VNI || n.Port != vxlanEntry.Port {
+				t.Fatalf("Vxlan FDB entry has vni %d and port %d, expected %d and %d",
+					n.VNI, n.Port, vxlanEntry.VNI, vxlanEntry.Port)
+			}
+			foundVxlan = true
+		}
+	}
+	if !foundBridge || !foundVxlan {
+		t.Fatalf("FDB entries not found: %v", neighs)
+	}
+
+	if err := NeighDel(bridgeEntry); err != nil {
+		t.Fatal(err)
+	}
+	if err := NeighDel(vxlanEntry); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -32,6 +32,10 @@ func LinkSetName(link Link, name string) error {
 	return ErrNotImplemented
 }
 
+func LinkSwapNames(a, b Link) error {
+	return ErrNotImplemented
+}
+
 func LinkSetAlias(link Link, name string) error {
 	return ErrNotImplemented
 }
@@ -52,6 +56,10 @@ func LinkSetVfTxRate(link Link, vf, rate int) error {
 	return ErrNotImplemented
 }
 
+func LinkSetVfConfig(link Link, vf int, cfg VfConfig) error {
+	return ErrNotImplemented
+}
+
 func LinkSetNoMaster(link Link) error {
 	return ErrNotImplemented
 }
@@ -60,6 +68,10 @@ func LinkSetMasterByIndex(link Link, masterIndex int) error {
 	return ErrNotImplemented
 }
 
+func LinkGetVrfTable(link Link) (uint32, bool) {
+	return 0, false
+}
+
 func LinkSetXdpFd(link Link, fd int) error {
 	return ErrNotImplemented
 }
@@ -72,6 +84,14 @@ func LinkSetARPOn(link Link) error {
 	return ErrNotImplemented
 }
 
+func LinkSetAllmulticastOn(link Link) error {
+	return ErrNotImplemented
+}
+
+func LinkSetAllmulticastOff(link Link) error {
+	return ErrNotImplemented
+}
+
 func LinkByName(name string) (Link, error) {
 	return nil, ErrNotImplemented
 }
@@ -108,10 +128,82 @@ func LinkSetFlood(link Link, mode bool) error {
 	return ErrNotImplemented
 }
 
+func MacvlanSetBroadcast(link Link, queueLen uint32, cutoff int32) error {
+	return ErrNotImplemented
+}
+
+func MacvlanGetBroadcast(link Link) (uint32, int32, error) {
+	return 0, 0, ErrNotImplemented
+}
+
+func LinkSetMcastFlood(link Link, mode bool) error {
+	return ErrNotImplemented
+}
+
+func LinkSetBrMcastRouter(link Link, mode uint8) error {
+	return ErrNotImplemented
+}
+
+func LinkSetBrVlanTunnel(link Link, mode bool) error {
+	return ErrNotImplemented
+}
+
 func LinkSetTxQLen(link Link, qlen int) error {
 	return ErrNotImplemented
 }
 
+func LinkSetGroup(link Link, group uint32) error {
+	return ErrNotImplemented
+}
+
+func LinkSetGroupState(group uint32, up bool) error {
+	return ErrNotImplemented
+}
+
+func LinkSetIP6AddrGenToken(link Link, token net.IP) error {
+	return ErrNotImplemented
+}
+
+func LinkSetIP6AddrGenMode(link Link, mode IP6AddrGenMode) error {
+	return ErrNotImplemented
+}
+
+func EthtoolDrvInfo(name string) (*DrvInfo, error) {
+	return nil, ErrNotImplemented
+}
+
+func EthtoolFeatures(name string) (map[string]EthtoolFeatureState, error) {
+	return nil, ErrNotImplemented
+}
+
+func EthtoolSetFeature(name, feature string, enabled bool) error {
+	return ErrNotImplemented
+}
+
+func LinkModify(desired Link) ([]string, error) {
+	return nil, ErrNotImplemented
+}
+
+func LinkSetLinkMode(link Link, mode LinkMode) error {
+	return ErrNotImplemented
+}
+
+func LinkGetThreaded(link Link) (bool, error) {
+	return false, ErrNotImplemented
+}
+
+func LinkSetThreaded(link Link, enable bool) error {
+	return ErrNotImplemented
+}
+
+func LinkStatsSnapshot(link Link) (*LinkStatistics64, error) {
+	return nil, ErrNotImplemented
+}
+
+func LinkStatsSince(link Link, snapshot *LinkStatistics64) (LinkStatistics64, error) {
+	return LinkStatistics64{}, ErrNotImplemented
+}
+
 func LinkAdd(link Link) error {
 	return ErrNotImplemented
 }
@@ -148,6 +240,10 @@ func LinkList() ([]Link, error) {
 	return nil, ErrNotImplemented
 }
 
+func LinkListWithOptions(options *LinkListOptions) ([]Link, error) {
+	return nil, ErrNotImplemented
+}
+
 func AddrAdd(link Link, addr *Addr) error {
 	return ErrNotImplemented
 }
@@ -156,6 +252,10 @@ func AddrDel(link Link, addr *Addr) error {
 	return ErrNotImplemented
 }
 
+func AddrReplaceAtomic(link Link, remove, add *Addr) (removeErr, addErr error) {
+	return ErrNotImplemented, ErrNotImplemented
+}
+
 func AddrList(link Link, family int) ([]Addr, error) {
 	return nil, ErrNotImplemented
 }
@@ -164,14 +264,46 @@ func RouteAdd(route *Route) error {
 	return ErrNotImplemented
 }
 
+func RouteAddBatch(routes []*Route) ([]error, error) {
+	return nil, ErrNotImplemented
+}
+
 func RouteDel(route *Route) error {
 	return ErrNotImplemented
 }
 
+func RouteSetPriority(route *Route, priority int) error {
+	return ErrNotImplemented
+}
+
+func RouteListChunked(table int, chunkSize int, fn func([]Route) error) error {
+	return ErrNotImplemented
+}
+
+func RouteDelByOif(oif int) (int, error) {
+	return 0, ErrNotImplemented
+}
+
 func RouteList(link Link, family int) ([]Route, error) {
 	return nil, ErrNotImplemented
 }
 
+func RouteGetExact(route *Route) (bool, *Route, error) {
+	return false, nil, ErrNotImplemented
+}
+
+func MdbAdd(bridge Link, entry *MdbEntry) error {
+	return ErrNotImplemented
+}
+
+func MdbDel(bridge Link, entry *MdbEntry) error {
+	return ErrNotImplemented
+}
+
+func MdbList(bridge Link) ([]MdbEntry, error) {
+	return nil, ErrNotImplemented
+}
+
 func XfrmPolicyAdd(policy *XfrmPolicy) error {
 	return ErrNotImplemented
 }
@@ -216,6 +348,18 @@ func NeighList(linkIndex, family int) ([]Neigh, error) {
 	return nil, ErrNotImplemented
 }
 
+func NeighGet(linkIndex int, ip net.IP) (*Neigh, error) {
+	return nil, ErrNotImplemented
+}
+
+func NeighTableList(family int) ([]NeighTable, error) {
+	return nil, ErrNotImplemented
+}
+
+func NeighTableSet(table *NeighTable) error {
+	return ErrNotImplemented
+}
+
 func NeighDeserialize(m []byte) (*Neigh, error) {
 	return nil, ErrNotImplemented
 }
@@ -223,3 +367,35 @@ func NeighDeserialize(m []byte) (*Neigh, error) {
 func SocketGet(local, remote net.Addr) (*Socket, error) {
 	return nil, ErrNotImplemented
 }
+
+func WireguardPeerList(link Link) ([]WireguardPeer, error) {
+	return nil, ErrNotImplemented
+}
+
+func WireguardAddPeer(link Link, peer WireguardPeer) error {
+	return ErrNotImplemented
+}
+
+func WireguardRemovePeer(link Link, publicKey [32]byte) error {
+	return ErrNotImplemented
+}
+
+func WireguardSetPrivateKey(link Link, key [32]byte) error {
+	return ErrNotImplemented
+}
+
+func WireguardSetListenPort(link Link, port uint16) error {
+	return ErrNotImplemented
+}
+
+func MrouteAdd(mroute *Mroute) error {
+	return ErrNotImplemented
+}
+
+func MrouteDel(mroute *Mroute) error {
+	return ErrNotImplemented
+}
+
+func MrouteList() ([]Mroute, error) {
+	return nil, ErrNotImplemented
+}
@@ -54,6 +54,7 @@ func (msg *IfAddrmsg) Len() int {
 // };
 
 const IFA_CACHEINFO = 6
+const IFA_RT_PRIORITY = 9 /* u32, priority/metric for prefix route */
 const SizeofIfaCacheInfo = 0x10
 
 type IfaCacheInfo struct {
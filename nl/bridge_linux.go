@@ -2,6 +2,7 @@ package nl
 
 import (
 	"fmt"
+	"syscall"
 	"unsafe"
 )
 
@@ -20,12 +21,27 @@ const (
  *     [IFLA_BRIDGE_FLAGS]
  *     [IFLA_BRIDGE_MODE]
  *     [IFLA_BRIDGE_VLAN_INFO]
+ *     [IFLA_BRIDGE_VLAN_TUNNEL_INFO]
  * }
  */
 const (
 	IFLA_BRIDGE_FLAGS = iota
 	IFLA_BRIDGE_MODE
 	IFLA_BRIDGE_VLAN_INFO
+	IFLA_BRIDGE_VLAN_TUNNEL_INFO
+)
+
+/* [IFLA_BRIDGE_VLAN_TUNNEL_INFO] = {
+ *     [IFLA_BRIDGE_VLAN_TUNNEL_ID]
+ *     [IFLA_BRIDGE_VLAN_TUNNEL_VID]
+ *     [IFLA_BRIDGE_VLAN_TUNNEL_FLAGS]
+ * }
+ */
+const (
+	IFLA_BRIDGE_VLAN_TUNNEL_UNSPEC = iota
+	IFLA_BRIDGE_VLAN_TUNNEL_ID
+	IFLA_BRIDGE_VLAN_TUNNEL_VID
+	IFLA_BRIDGE_VLAN_TUNNEL_FLAGS
 )
 
 const (
@@ -71,4 +87,129 @@ const (
 	RTEXT_FILTER_VF = 1 << iota
 	RTEXT_FILTER_BRVLAN
 	RTEXT_FILTER_BRVLAN_COMPRESSED
+	RTEXT_FILTER_SKIP_STATS
+)
+
+/* Multicast database messages, RTM_*MDB are missing from the syscall package */
+const (
+	RTM_NEWMDB = 0x54
+	RTM_DELMDB = 0x55
+	RTM_GETMDB = 0x56
+)
+
+/* Bridge multicast database attributes
+ * [MDBA_MDB] = {
+ *     [MDBA_MDB_ENTRY] = {
+ *         [MDBA_MDB_ENTRY_INFO] {
+ *            struct br_mdb_entry
+ *            [MDBA_MDB_EATTR attributes]
+ *         }
+ *     }
+ * }
+ * [MDBA_ROUTER] = {
+ *    [MDBA_ROUTER_PORT]
+ * }
+ */
+const (
+	MDBA_UNSPEC = iota
+	MDBA_MDB
+	MDBA_ROUTER
 )
+
+const (
+	MDBA_MDB_UNSPEC = iota
+	MDBA_MDB_ENTRY
+)
+
+const (
+	MDBA_MDB_ENTRY_UNSPEC = iota
+	MDBA_MDB_ENTRY_INFO
+)
+
+const (
+	MDBA_SET_ENTRY_UNSPEC = iota
+	MDBA_SET_ENTRY
+	MDBA_SET_ENTRY_ATTRS
+)
+
+/* br_mdb_entry states */
+const (
+	MDB_TEMPORARY = iota
+	MDB_PERMANENT
+)
+
+/* br_mdb_entry flags */
+const (
+	MDB_FLAGS_OFFLOAD = 1 << iota
+	MDB_FLAGS_FAST_LEAVE
+	MDB_FLAGS_STAR_EXCL
+	MDB_FLAGS_BLOCKED
+)
+
+const (
+	SizeofBrPortMsg  = 0x08
+	SizeofBrMdbEntry = 0x1c
+)
+
+// struct br_port_msg {
+//   __u8  family;
+//   __u32 ifindex;
+// };
+
+type BrPortMsg struct {
+	Family  uint8
+	_       [3]byte
+	Ifindex uint32
+}
+
+func NewBrPortMsg(ifindex int) *BrPortMsg {
+	return &BrPortMsg{
+		Family:  syscall.AF_BRIDGE,
+		Ifindex: uint32(ifindex),
+	}
+}
+
+func (msg *BrPortMsg) Serialize() []byte {
+	return (*(*[SizeofBrPortMsg]byte)(unsafe.Pointer(msg)))[:]
+}
+
+func (msg *BrPortMsg) Len() int {
+	return SizeofBrPortMsg
+}
+
+func DeserializeBrPortMsg(b []byte) *BrPortMsg {
+	return (*BrPortMsg)(unsafe.Pointer(&b[0:SizeofBrPortMsg][0]))
+}
+
+// struct br_mdb_entry {
+//   __u32 ifindex;
+//   __u8 state;
+//   __u8 flags;
+//   __u16 vid;
+//   struct {
+//     union {
+//       __be32 ip4;
+//       struct in6_addr ip6;
+//       unsigned char mac_addr[ETH_ALEN];
+//     } u;
+//     __be16 proto;
+//   } addr;
+// };
+
+type BrMdbEntry struct {
+	Ifindex uint32
+	State   uint8
+	Flags   uint8
+	Vid     uint16
+	Addr    [16]byte
+	Proto   uint16 // big endian
+	_       [2]byte
+}
+
+func (e *BrMdbEntry) Serialize() []byte {
+	return (*(*[SizeofBrMdbEntry]byte)(unsafe.Pointer(e)))[:]
+}
+
+func DeserializeBrMdbEntry(b []byte) *BrMdbEntry {
+	return (*BrMdbEntry)(unsafe.Pointer(&b[0:SizeofBrMdbEntry][0]))
+}
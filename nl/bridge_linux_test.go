@@ -33,3 +33,35 @@ func TestBridgeVlanInfoDeserializeSerialize(t *testing.T) {
 	msg := DeserializeBridgeVlanInfo(orig)
 	testDeserializeSerialize(t, orig, safemsg, msg)
 }
+
+func (msg *BrMdbEntry) write(b []byte) {
+	native := NativeEndian()
+	native.PutUint32(b[0:4], msg.Ifindex)
+	b[4] = msg.State
+	b[5] = msg.Flags
+	native.PutUint16(b[6:8], msg.Vid)
+	copy(b[8:24], msg.Addr[:])
+	native.PutUint16(b[24:26], msg.Proto)
+}
+
+func (msg *BrMdbEntry) serializeSafe() []byte {
+	b := make([]byte, SizeofBrMdbEntry)
+	msg.write(b)
+	return b
+}
+
+func deserializeBrMdbEntrySafe(b []byte) *BrMdbEntry {
+	var msg = BrMdbEntry{}
+	binary.Read(bytes.NewReader(b[0:SizeofBrMdbEntry]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestBrMdbEntryDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofBrMdbEntry)
+	rand.Read(orig)
+	// zero the trailing padding
+	copy(orig[26:], []byte{0, 0})
+	safemsg := deserializeBrMdbEntrySafe(orig)
+	msg := DeserializeBrMdbEntry(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
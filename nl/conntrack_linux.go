@@ -31,10 +31,34 @@ var L4ProtoMap = map[uint8]string{
 // 	IPCTNL_MSG_MAX
 // };
 const (
+	IPCTNL_MSG_CT_NEW    = 0
 	IPCTNL_MSG_CT_GET    = 1
 	IPCTNL_MSG_CT_DELETE = 2
 )
 
+// enum ctnl_exp_msg_types {
+// 	IPCTNL_MSG_EXP_NEW,
+// 	IPCTNL_MSG_EXP_GET,
+// 	IPCTNL_MSG_EXP_DELETE,
+// 	IPCTNL_MSG_EXP_GET_STATS_CPU,
+//
+// 	IPCTNL_MSG_EXP_MAX
+// };
+const (
+	IPCTNL_MSG_EXP_NEW    = 0
+	IPCTNL_MSG_EXP_GET    = 1
+	IPCTNL_MSG_EXP_DELETE = 2
+)
+
+// Conntrack status bits, from:
+// https://github.com/torvalds/linux/blob/master/include/uapi/linux/netfilter/nf_conntrack_common.h
+const (
+	IPS_EXPECTED   = 1 << 0
+	IPS_SEEN_REPLY = 1 << 1
+	IPS_ASSURED    = 1 << 2
+	IPS_CONFIRMED  = 1 << 3
+)
+
 // #define NFNETLINK_V0	0
 const (
 	NFNETLINK_V0 = 0
@@ -82,6 +106,49 @@ const (
 	CTA_TIMEOUT     = 7
 	CTA_MARK        = 8
 	CTA_PROTOINFO   = 4
+	CTA_HELP        = 5
+	CTA_ZONE        = 18
+)
+
+// enum ctattr_help {
+// 	CTA_HELP_UNSPEC,
+// 	CTA_HELP_NAME,
+// 	CTA_HELP_INFO,
+// 	__CTA_HELP_MAX
+// };
+// #define CTA_HELP_MAX (__CTA_HELP_MAX - 1)
+const (
+	CTA_HELP_NAME = 1
+)
+
+// enum ctattr_expect {
+// 	CTA_EXPECT_UNSPEC,
+// 	CTA_EXPECT_MASTER,
+// 	CTA_EXPECT_TUPLE,
+// 	CTA_EXPECT_MASK,
+// 	CTA_EXPECT_TIMEOUT,
+// 	CTA_EXPECT_ID,
+// 	CTA_EXPECT_HELP_NAME,
+// 	CTA_EXPECT_ZONE,
+// 	CTA_EXPECT_FLAGS,
+// 	CTA_EXPECT_CLASS,
+// 	CTA_EXPECT_NAT,
+// 	CTA_EXPECT_FN,
+// 	__CTA_EXPECT_MAX
+// };
+// #define CTA_EXPECT_MAX (__CTA_EXPECT_MAX - 1)
+const (
+	CTA_EXPECT_MASTER    = 1
+	CTA_EXPECT_TUPLE     = 2
+	CTA_EXPECT_MASK      = 3
+	CTA_EXPECT_TIMEOUT   = 4
+	CTA_EXPECT_ID        = 5
+	CTA_EXPECT_HELP_NAME = 6
+	CTA_EXPECT_ZONE      = 7
+	CTA_EXPECT_FLAGS     = 8
+	CTA_EXPECT_CLASS     = 9
+	CTA_EXPECT_NAT       = 10
+	CTA_EXPECT_FN        = 11
 )
 
 // enum ctattr_tuple {
@@ -163,6 +230,37 @@ const (
 	CTA_PROTOINFO_TCP_FLAGS_REPLY     = 5
 )
 
+// enum tcp_conntrack {
+// 	TCP_CONNTRACK_NONE,
+// 	TCP_CONNTRACK_SYN_SENT,
+// 	TCP_CONNTRACK_SYN_RECV,
+// 	TCP_CONNTRACK_ESTABLISHED,
+// 	TCP_CONNTRACK_FIN_WAIT,
+// 	TCP_CONNTRACK_CLOSE_WAIT,
+// 	TCP_CONNTRACK_LAST_ACK,
+// 	TCP_CONNTRACK_TIME_WAIT,
+// 	TCP_CONNTRACK_CLOSE,
+// 	TCP_CONNTRACK_LISTEN,	/* obsolete */
+// #define TCP_CONNTRACK_SYN_SENT2	TCP_CONNTRACK_LISTEN
+// 	TCP_CONNTRACK_MAX,
+// 	TCP_CONNTRACK_IGNORE,
+// 	TCP_CONNTRACK_RETRANS,
+// 	TCP_CONNTRACK_UNACK,
+// 	TCP_CONNTRACK_TIMEOUT_MAX
+// };
+const (
+	TCP_CONNTRACK_NONE        = 0
+	TCP_CONNTRACK_SYN_SENT    = 1
+	TCP_CONNTRACK_SYN_RECV    = 2
+	TCP_CONNTRACK_ESTABLISHED = 3
+	TCP_CONNTRACK_FIN_WAIT    = 4
+	TCP_CONNTRACK_CLOSE_WAIT  = 5
+	TCP_CONNTRACK_LAST_ACK    = 6
+	TCP_CONNTRACK_TIME_WAIT   = 7
+	TCP_CONNTRACK_CLOSE       = 8
+	TCP_CONNTRACK_SYN_SENT2   = 9
+)
+
 // /* General form of address family dependent message.
 //  */
 // struct nfgenmsg {
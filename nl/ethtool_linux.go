@@ -0,0 +1,91 @@
+package nl
+
+const (
+	ETHTOOL_GENL_NAME    = "ethtool"
+	ETHTOOL_GENL_VERSION = 1
+)
+
+// ethtool netlink commands, the kernel replies with the same values
+const (
+	ETHTOOL_MSG_STRSET_GET   = 1
+	ETHTOOL_MSG_FEATURES_GET = 11
+	ETHTOOL_MSG_FEATURES_SET = 12
+)
+
+const (
+	ETHTOOL_A_HEADER_UNSPEC = iota
+	ETHTOOL_A_HEADER_DEV_INDEX
+	ETHTOOL_A_HEADER_DEV_NAME
+	ETHTOOL_A_HEADER_FLAGS
+)
+
+const (
+	ETHTOOL_FLAG_COMPACT_BITSETS = 1 << iota
+	ETHTOOL_FLAG_OMIT_REPLY
+	ETHTOOL_FLAG_STATS
+)
+
+const (
+	ETHTOOL_A_BITSET_BIT_UNSPEC = iota
+	ETHTOOL_A_BITSET_BIT_INDEX
+	ETHTOOL_A_BITSET_BIT_NAME
+	ETHTOOL_A_BITSET_BIT_VALUE
+)
+
+const (
+	ETHTOOL_A_BITSET_BITS_UNSPEC = iota
+	ETHTOOL_A_BITSET_BITS_BIT
+)
+
+const (
+	ETHTOOL_A_BITSET_UNSPEC = iota
+	ETHTOOL_A_BITSET_NOMASK
+	ETHTOOL_A_BITSET_SIZE
+	ETHTOOL_A_BITSET_BITS
+	ETHTOOL_A_BITSET_VALUE
+	ETHTOOL_A_BITSET_MASK
+)
+
+const (
+	ETHTOOL_A_STRING_UNSPEC = iota
+	ETHTOOL_A_STRING_INDEX
+	ETHTOOL_A_STRING_VALUE
+)
+
+const (
+	ETHTOOL_A_STRINGS_UNSPEC = iota
+	ETHTOOL_A_STRINGS_STRING
+)
+
+const (
+	ETHTOOL_A_STRINGSET_UNSPEC = iota
+	ETHTOOL_A_STRINGSET_ID
+	ETHTOOL_A_STRINGSET_COUNT
+	ETHTOOL_A_STRINGSET_STRINGS
+)
+
+const (
+	ETHTOOL_A_STRINGSETS_UNSPEC = iota
+	ETHTOOL_A_STRINGSETS_STRINGSET
+)
+
+const (
+	ETHTOOL_A_STRSET_UNSPEC = iota
+	ETHTOOL_A_STRSET_HEADER
+	ETHTOOL_A_STRSET_STRINGSETS
+	ETHTOOL_A_STRSET_COUNTS_ONLY
+)
+
+const (
+	ETHTOOL_A_FEATURES_UNSPEC = iota
+	ETHTOOL_A_FEATURES_HEADER
+	ETHTOOL_A_FEATURES_HW
+	ETHTOOL_A_FEATURES_WANTED
+	ETHTOOL_A_FEATURES_ACTIVE
+	ETHTOOL_A_FEATURES_NOCHANGE
+)
+
+// ethtool string sets
+const (
+	ETH_SS_FEATURES = 4
+)
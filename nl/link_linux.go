@@ -30,6 +30,37 @@ const (
 	IFLA_GSO_MAX_SIZE
 	IFLA_PAD
 	IFLA_XDP
+	IFLA_EVENT
+	IFLA_NEW_NETNSID
+	IFLA_IF_NETNSID
+	IFLA_CARRIER_UP_COUNT
+	IFLA_CARRIER_DOWN_COUNT
+	IFLA_NEW_IFINDEX
+	IFLA_MIN_MTU
+	IFLA_MAX_MTU
+	IFLA_PROP_LIST
+	IFLA_ALT_IFNAME
+	IFLA_PERM_ADDRESS
+	IFLA_PROTO_DOWN_REASON
+	IFLA_PARENT_DEV_NAME
+	IFLA_PARENT_DEV_BUS_NAME
+	IFLA_GRO_MAX_SIZE
+	IFLA_TSO_MAX_SIZE
+	IFLA_TSO_MAX_SEGS
+	IFLA_ALLMULTI /* Allmulti count: > 0 means acts ALLMULTI */
+)
+
+const (
+	IFLA_INET6_UNSPEC = iota
+	IFLA_INET6_FLAGS
+	IFLA_INET6_CONF
+	IFLA_INET6_STATS
+	IFLA_INET6_MCAST
+	IFLA_INET6_CACHEINFO
+	IFLA_INET6_ICMP6STATS
+	IFLA_INET6_TOKEN
+	IFLA_INET6_ADDR_GEN_MODE
+	IFLA_INET6_MAX = IFLA_INET6_ADDR_GEN_MODE
 )
 
 const (
@@ -37,7 +68,9 @@ const (
 	IFLA_INFO_KIND
 	IFLA_INFO_DATA
 	IFLA_INFO_XSTATS
-	IFLA_INFO_MAX = IFLA_INFO_XSTATS
+	IFLA_INFO_SLAVE_KIND
+	IFLA_INFO_SLAVE_DATA
+	IFLA_INFO_MAX = IFLA_INFO_SLAVE_DATA
 )
 
 const (
@@ -105,7 +138,24 @@ const (
 	IFLA_BRPORT_PROXYARP
 	IFLA_BRPORT_LEARNING_SYNC
 	IFLA_BRPORT_PROXYARP_WIFI
-	IFLA_BRPORT_MAX = IFLA_BRPORT_PROXYARP_WIFI
+	IFLA_BRPORT_ROOT_ID
+	IFLA_BRPORT_BRIDGE_ID
+	IFLA_BRPORT_DESIGNATED_PORT
+	IFLA_BRPORT_DESIGNATED_COST
+	IFLA_BRPORT_ID
+	IFLA_BRPORT_NO
+	IFLA_BRPORT_TOPOLOGY_CHANGE_ACK
+	IFLA_BRPORT_CONFIG_PENDING
+	IFLA_BRPORT_MESSAGE_AGE_TIMER
+	IFLA_BRPORT_FORWARD_DELAY_TIMER
+	IFLA_BRPORT_HOLD_TIMER
+	IFLA_BRPORT_FLUSH
+	IFLA_BRPORT_MULTICAST_ROUTER
+	IFLA_BRPORT_PAD
+	IFLA_BRPORT_MCAST_FLOOD
+	IFLA_BRPORT_MCAST_TO_UCAST
+	IFLA_BRPORT_VLAN_TUNNEL
+	IFLA_BRPORT_MAX = IFLA_BRPORT_VLAN_TUNNEL
 )
 
 const (
@@ -118,7 +168,14 @@ const (
 	IFLA_MACVLAN_UNSPEC = iota
 	IFLA_MACVLAN_MODE
 	IFLA_MACVLAN_FLAGS
-	IFLA_MACVLAN_MAX = IFLA_MACVLAN_FLAGS
+	IFLA_MACVLAN_MACADDR_MODE
+	IFLA_MACVLAN_MACADDR
+	IFLA_MACVLAN_MACADDR_DATA
+	IFLA_MACVLAN_MACADDR_COUNT
+	IFLA_MACVLAN_BC_QUEUE_LEN
+	IFLA_MACVLAN_BC_QUEUE_LEN_USED
+	IFLA_MACVLAN_BC_CUTOFF
+	IFLA_MACVLAN_MAX = IFLA_MACVLAN_BC_CUTOFF
 )
 
 const (
@@ -199,7 +256,13 @@ const (
 	IFLA_GRE_ENCAP_SPORT
 	IFLA_GRE_ENCAP_DPORT
 	IFLA_GRE_COLLECT_METADATA
-	IFLA_GRE_MAX = IFLA_GRE_COLLECT_METADATA
+	IFLA_GRE_IGNORE_DF
+	IFLA_GRE_FWMARK
+	IFLA_GRE_ERSPAN_INDEX
+	IFLA_GRE_ERSPAN_VER
+	IFLA_GRE_ERSPAN_DIR
+	IFLA_GRE_ERSPAN_HWID
+	IFLA_GRE_MAX = IFLA_GRE_ERSPAN_HWID
 )
 
 const (
@@ -230,9 +293,18 @@ const (
 	IFLA_VF_RSS_QUERY_EN /* RSS Redirection Table and Hash Key query
 	 * on/off switch
 	 */
-	IFLA_VF_STATS /* network device statistics */
-	IFLA_VF_TRUST /* Trust state of VF */
-	IFLA_VF_MAX   = IFLA_VF_TRUST
+	IFLA_VF_STATS        /* network device statistics */
+	IFLA_VF_TRUST        /* Trust state of VF */
+	IFLA_VF_IB_NODE_GUID /* VF Infiniband node GUID */
+	IFLA_VF_IB_PORT_GUID /* VF Infiniband port GUID */
+	IFLA_VF_VLAN_LIST    /* nested list of vlans, option for QinQ */
+	IFLA_VF_MAX          = IFLA_VF_VLAN_LIST
+)
+
+const (
+	IFLA_VF_VLAN_INFO_UNSPEC = iota
+	IFLA_VF_VLAN_INFO        /* VLAN ID, QoS and VLAN protocol */
+	IFLA_VF_VLAN_INFO_MAX    = IFLA_VF_VLAN_INFO
 )
 
 const (
@@ -261,6 +333,7 @@ const (
 	SizeofVfLinkState  = 0x08
 	SizeofVfRssQueryEn = 0x08
 	SizeofVfTrust      = 0x08
+	SizeofVfVlanInfo   = 0x10
 )
 
 // struct ifla_vf_mac {
@@ -443,20 +516,59 @@ func (msg *VfTrust) Serialize() []byte {
 	return (*(*[SizeofVfTrust]byte)(unsafe.Pointer(msg)))[:]
 }
 
+// struct ifla_vf_vlan_info {
+//   __u32 vf;
+//   __u32 vlan; /* 0 - 4095, 0 disables VLAN filter */
+//   __u32 qos;
+//   __be16 vlan_proto; /* VLAN protocol either 802.1Q or 802.1ad */
+// };
+
+type VfVlanInfo struct {
+	VfVlan
+	VlanProto uint16 // network byte order
+	_         uint16
+}
+
+func (msg *VfVlanInfo) Len() int {
+	return SizeofVfVlanInfo
+}
+
+func DeserializeVfVlanInfo(b []byte) *VfVlanInfo {
+	return (*VfVlanInfo)(unsafe.Pointer(&b[0:SizeofVfVlanInfo][0]))
+}
+
+func (msg *VfVlanInfo) Serialize() []byte {
+	return (*(*[SizeofVfVlanInfo]byte)(unsafe.Pointer(msg)))[:]
+}
+
 const (
 	XDP_FLAGS_UPDATE_IF_NOEXIST = 1 << iota
 	XDP_FLAGS_SKB_MODE
 	XDP_FLAGS_DRV_MODE
-	XDP_FLAGS_MASK = XDP_FLAGS_UPDATE_IF_NOEXIST | XDP_FLAGS_SKB_MODE | XDP_FLAGS_DRV_MODE
+	XDP_FLAGS_HW_MODE
+	XDP_FLAGS_MODES = XDP_FLAGS_SKB_MODE | XDP_FLAGS_DRV_MODE | XDP_FLAGS_HW_MODE
+	XDP_FLAGS_MASK  = XDP_FLAGS_UPDATE_IF_NOEXIST | XDP_FLAGS_MODES
+)
+
+// Values of IFLA_XDP_ATTACHED
+const (
+	XDP_ATTACHED_NONE = iota
+	XDP_ATTACHED_DRV
+	XDP_ATTACHED_SKB
+	XDP_ATTACHED_HW
+	XDP_ATTACHED_MULTI
 )
 
 const (
-	IFLA_XDP_UNSPEC   = iota
-	IFLA_XDP_FD       /* fd of xdp program to attach, or -1 to remove */
-	IFLA_XDP_ATTACHED /* read-only bool indicating if prog is attached */
-	IFLA_XDP_FLAGS    /* xdp prog related flags */
-	IFLA_XDP_PROG_ID  /* xdp prog id */
-	IFLA_XDP_MAX      = IFLA_XDP_PROG_ID
+	IFLA_XDP_UNSPEC      = iota
+	IFLA_XDP_FD          /* fd of xdp program to attach, or -1 to remove */
+	IFLA_XDP_ATTACHED    /* read-only XDP_ATTACHED_* mode of the attached progs */
+	IFLA_XDP_FLAGS       /* xdp prog related flags */
+	IFLA_XDP_PROG_ID     /* xdp prog id, unless attached in several modes */
+	IFLA_XDP_DRV_PROG_ID /* id of the prog attached in driver mode */
+	IFLA_XDP_SKB_PROG_ID /* id of the prog attached in generic mode */
+	IFLA_XDP_HW_PROG_ID  /* id of the prog offloaded to the hardware */
+	IFLA_XDP_MAX         = IFLA_XDP_HW_PROG_ID
 )
 
 const (
@@ -478,6 +590,16 @@ const (
 	IFLA_IPTUN_MAX = IFLA_IPTUN_6RD_RELAY_PREFIXLEN
 )
 
+// Flags of ip6 tunnels
+const (
+	IP6_TNL_F_IGN_ENCAP_LIMIT    = 0x1
+	IP6_TNL_F_USE_ORIG_TCLASS    = 0x2
+	IP6_TNL_F_USE_ORIG_FLOWLABEL = 0x4
+	IP6_TNL_F_MIP6_DEV           = 0x8
+	IP6_TNL_F_RCV_DSCP_COPY      = 0x10
+	IP6_TNL_F_USE_ORIG_FWMARK    = 0x20
+)
+
 const (
 	IFLA_VTI_UNSPEC = iota
 	IFLA_VTI_LINK
@@ -493,6 +615,21 @@ const (
 	IFLA_VRF_TABLE
 )
 
+const (
+	IFLA_VRF_PORT_UNSPEC = iota
+	IFLA_VRF_PORT_TABLE
+)
+
+const (
+	IFLA_NETKIT_UNSPEC = iota
+	IFLA_NETKIT_PEER_INFO
+	IFLA_NETKIT_PRIMARY
+	IFLA_NETKIT_POLICY
+	IFLA_NETKIT_PEER_POLICY
+	IFLA_NETKIT_MODE
+	IFLA_NETKIT_MAX = IFLA_NETKIT_MODE
+)
+
 const (
 	IFLA_BR_UNSPEC = iota
 	IFLA_BR_FORWARD_DELAY
@@ -554,3 +691,36 @@ const (
 	GTP_ROLE_GGSN = iota
 	GTP_ROLE_SGSN
 )
+
+const (
+	IFLA_HSR_UNSPEC = iota
+	IFLA_HSR_SLAVE1
+	IFLA_HSR_SLAVE2
+	IFLA_HSR_MULTICAST_SPEC
+	IFLA_HSR_SUPERVISION_ADDR
+	IFLA_HSR_SEQ_NR
+	IFLA_HSR_VERSION
+	IFLA_HSR_PROTOCOL
+	IFLA_HSR_MAX = IFLA_HSR_PROTOCOL
+)
+
+const (
+	IFLA_IPOIB_UNSPEC = iota
+	IFLA_IPOIB_PKEY
+	IFLA_IPOIB_MODE
+	IFLA_IPOIB_UMCAST
+	IFLA_IPOIB_MAX = IFLA_IPOIB_UMCAST
+)
+
+const (
+	IFLA_AMT_UNSPEC = iota
+	IFLA_AMT_MODE
+	IFLA_AMT_RELAY_PORT
+	IFLA_AMT_GATEWAY_PORT
+	IFLA_AMT_LINK
+	IFLA_AMT_LOCAL_IP
+	IFLA_AMT_REMOTE_IP
+	IFLA_AMT_DISCOVERY_IP
+	IFLA_AMT_MAX_TUNNELS
+	IFLA_AMT_MAX = IFLA_AMT_MAX_TUNNELS
+)
@@ -0,0 +1,78 @@
+package nl
+
+import (
+	"unsafe"
+)
+
+// Nexthop object messages, missing from the syscall package
+const (
+	RTM_NEWNEXTHOP = 0x68
+	RTM_DELNEXTHOP = 0x69
+	RTM_GETNEXTHOP = 0x6a
+)
+
+const (
+	NHA_UNSPEC = iota
+	NHA_ID
+	NHA_GROUP
+	NHA_GROUP_TYPE
+	NHA_BLACKHOLE
+	NHA_OIF
+	NHA_GATEWAY
+	NHA_ENCAP_TYPE
+	NHA_ENCAP
+	NHA_GROUPS
+	NHA_MASTER
+	NHA_FDB
+)
+
+const (
+	SizeofNhmsg      = 0x08
+	SizeofNexthopGrp = 0x08
+)
+
+// struct nhmsg {
+//   unsigned char nh_family;
+//   unsigned char nh_scope;
+//   unsigned char nh_protocol;
+//   unsigned char resvd;
+//   unsigned int  nh_flags;
+// };
+
+type Nhmsg struct {
+	Family   uint8
+	Scope    uint8
+	Protocol uint8
+	Resvd    uint8
+	Flags    uint32
+}
+
+func (msg *Nhmsg) Len() int {
+	return SizeofNhmsg
+}
+
+func DeserializeNhmsg(b []byte) *Nhmsg {
+	return (*Nhmsg)(unsafe.Pointer(&b[0:SizeofNhmsg][0]))
+}
+
+func (msg *Nhmsg) Serialize() []byte {
+	return (*(*[SizeofNhmsg]byte)(unsafe.Pointer(msg)))[:]
+}
+
+// struct nexthop_grp {
+//   __u32 id;
+//   __u8  weight;
+//   __u8  weight_high;
+//   __u16 resvd2;
+// };
+
+type NexthopGrp struct {
+	Id         uint32
+	Weight     uint8
+	WeightHigh uint8
+	Resvd2     uint16
+}
+
+func DeserializeNexthopGrp(b []byte) *NexthopGrp {
+	return (*NexthopGrp)(unsafe.Pointer(&b[0:SizeofNexthopGrp][0]))
+}
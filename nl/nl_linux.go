@@ -4,6 +4,7 @@ package nl
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"net"
 	"runtime"
@@ -23,9 +24,48 @@ const (
 	FAMILY_MPLS = AF_MPLS
 )
 
+const (
+	SOL_NETLINK = 0x10e
+
+	// Netlink socket options
+	NETLINK_CAP_ACK        = 0xa
+	NETLINK_EXT_ACK        = 0xb
+	NETLINK_GET_STRICT_CHK = 0xc
+)
+
+// Flags and attributes of extended acks
+const (
+	NLM_F_CAPPED   = 0x100 /* request was capped */
+	NLM_F_ACK_TLVS = 0x200 /* extended ACK TVLs were included */
+
+	NLMSGERR_ATTR_UNUSED = 0
+	NLMSGERR_ATTR_MSG    = 1
+	NLMSGERR_ATTR_OFFS   = 2
+)
+
 // SupportedNlFamilies contains the list of netlink families this netlink package supports
 var SupportedNlFamilies = []int{syscall.NETLINK_ROUTE, syscall.NETLINK_XFRM, syscall.NETLINK_NETFILTER}
 
+// EnableErrorMessageReporting makes the sockets opened by this package ask
+// the kernel for extended acks, so errors carry the kernel's explanation.
+var EnableErrorMessageReporting = false
+
+// ErrorMessage is returned for a failed request when the kernel sent an
+// extended ack message along with the error number.
+type ErrorMessage struct {
+	syscall.Errno
+	Message string
+}
+
+func (e ErrorMessage) Error() string {
+	return fmt.Sprintf("%s: %s", e.Message, e.Errno.Error())
+}
+
+// Unwrap returns the error number of the failed request.
+func (e ErrorMessage) Unwrap() error {
+	return e.Errno
+}
+
 var nextSeqNr uint32
 
 // GetIPFamily returns the family type of a net.IP.
@@ -272,22 +312,34 @@ func NewRtAttr(attrType int, data []byte) *RtAttr {
 
 // Create a new RtAttr obj anc add it as a child of an existing object
 func NewRtAttrChild(parent *RtAttr, attrType int, data []byte) *RtAttr {
+	return parent.AddRtAttr(attrType, data)
+}
+
+// AddRtAttr creates a new RtAttr and adds it as a child of the attribute
+func (a *RtAttr) AddRtAttr(attrType int, data []byte) *RtAttr {
 	attr := NewRtAttr(attrType, data)
-	parent.children = append(parent.children, attr)
+	a.children = append(a.children, attr)
 	return attr
 }
 
+// AddChild adds an existing attribute, or any other request data,
+// as a child of the attribute
+func (a *RtAttr) AddChild(attr NetlinkRequestData) {
+	a.children = append(a.children, attr)
+}
+
 func (a *RtAttr) Len() int {
 	if len(a.children) == 0 {
 		return (syscall.SizeofRtAttr + len(a.Data))
 	}
 
-	l := 0
+	// the children start after the padded data
+	l := rtaAlignOf(len(a.Data))
 	for _, child := range a.children {
 		l += rtaAlignOf(child.Len())
 	}
 	l += syscall.SizeofRtAttr
-	return rtaAlignOf(l + len(a.Data))
+	return rtaAlignOf(l)
 }
 
 // Serialize the RtAttr into a byte array
@@ -323,6 +375,9 @@ type NetlinkRequest struct {
 	Data    []NetlinkRequestData
 	RawData []byte
 	Sockets map[int]*SocketHandle
+	// PreserveSeq keeps the Seq set by the caller instead of numbering
+	// the request from the sequence counter of the socket it is sent on.
+	PreserveSeq bool
 }
 
 // Serialize the Netlink Request into a byte array
@@ -366,10 +421,28 @@ func (req *NetlinkRequest) AddRawData(data []byte) {
 	}
 }
 
-// Execute the request against a the given sockType.
+// Execute the request against the given sockType, the netlink family of
+// the request such as NETLINK_ROUTE or NETLINK_GENERIC. The socket of
+// that family in Sockets is used if there is one, a new one otherwise.
 // Returns a list of netlink messages in serialized format, optionally filtered
 // by resType.
 func (req *NetlinkRequest) Execute(sockType int, resType uint16) ([][]byte, error) {
+	var res [][]byte
+	err := req.ExecuteIter(sockType, resType, func(msg []byte) bool {
+		res = append(res, msg)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ExecuteIter executes the request against the given sockType and calls f
+// for each netlink message in serialized format, optionally filtered by
+// resType, as they are received. If f returns false the remaining messages
+// are drained from the socket without being passed to f.
+func (req *NetlinkRequest) ExecuteIter(sockType int, resType uint16, f func(msg []byte) bool) error {
 	var (
 		s   *NetlinkSocket
 		err error
@@ -378,7 +451,9 @@ func (req *NetlinkRequest) Execute(sockType int, resType uint16) ([][]byte, erro
 	if req.Sockets != nil {
 		if sh, ok := req.Sockets[sockType]; ok {
 			s = sh.Socket
-			req.Seq = atomic.AddUint32(&sh.Seq, 1)
+			if !req.PreserveSeq {
+				req.Seq = atomic.AddUint32(&sh.Seq, 1)
+			}
 		}
 	}
 	sharedSocket := s != nil
@@ -386,7 +461,7 @@ func (req *NetlinkRequest) Execute(sockType int, resType uint16) ([][]byte, erro
 	if s == nil {
 		s, err = getNetlinkSocket(sockType)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		defer s.Close()
 	} else {
@@ -395,31 +470,31 @@ func (req *NetlinkRequest) Execute(sockType int, resType uint16) ([][]byte, erro
 	}
 
 	if err := s.Send(req); err != nil {
-		return nil, err
+		return err
 	}
 
 	pid, err := s.GetPid()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var res [][]byte
+	stop := false
 
 done:
 	for {
 		msgs, err := s.Receive()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		for _, m := range msgs {
 			if m.Header.Seq != req.Seq {
 				if sharedSocket {
 					continue
 				}
-				return nil, fmt.Errorf("Wrong Seq nr %d, expected %d", m.Header.Seq, req.Seq)
+				return fmt.Errorf("Wrong Seq nr %d, expected %d", m.Header.Seq, req.Seq)
 			}
 			if m.Header.Pid != pid {
-				return nil, fmt.Errorf("Wrong pid %d, expected %d", m.Header.Pid, pid)
+				return fmt.Errorf("Wrong pid %d, expected %d", m.Header.Pid, pid)
 			}
 			if m.Header.Type == syscall.NLMSG_DONE {
 				break done
@@ -430,18 +505,166 @@ done:
 				if error == 0 {
 					break done
 				}
-				return nil, syscall.Errno(-error)
+				errno := syscall.Errno(-error)
+				if msg := extAckMessage(&m); msg != "" {
+					return ErrorMessage{Errno: errno, Message: msg}
+				}
+				return errno
 			}
 			if resType != 0 && m.Header.Type != resType {
 				continue
 			}
-			res = append(res, m.Data)
+			if !stop && !f(m.Data) {
+				stop = true
+			}
 			if m.Header.Flags&syscall.NLM_F_MULTI == 0 {
 				break done
 			}
 		}
 	}
-	return res, nil
+	return nil
+}
+
+// ExecuteBatch sends the requests, which must all ask for an ack, against
+// the given sockType without waiting for each ack before sending the next
+// one. It returns the error acked by the kernel for each request, in order,
+// and a non nil error if the acks could not all be received, in which case
+// the requests left without an ack have ErrNoAck as their error. All the
+// requests must use the same sockets.
+func ExecuteBatch(sockType int, reqs []*NetlinkRequest) ([]error, error) {
+	errs := make([]error, len(reqs))
+	if len(reqs) == 0 {
+		return errs, nil
+	}
+
+	var (
+		s   *NetlinkSocket
+		sh  *SocketHandle
+		err error
+	)
+	if reqs[0].Sockets != nil {
+		sh = reqs[0].Sockets[sockType]
+	}
+	if sh != nil {
+		s = sh.Socket
+		s.Lock()
+		defer s.Unlock()
+	} else {
+		s, err = getNetlinkSocket(sockType)
+		if err != nil {
+			return nil, err
+		}
+		defer s.Close()
+	}
+
+	pid, err := s.GetPid()
+	if err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(reqs); start += batchSize {
+		end := start + batchSize
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+		if err := executeBatch(s, sh, pid, reqs[start:end], errs[start:end]); err != nil {
+			for i := end; i < len(reqs); i++ {
+				errs[i] = ErrNoAck
+			}
+			return errs, err
+		}
+	}
+	return errs, nil
+}
+
+// batchSize caps the requests sent by ExecuteBatch before waiting for their
+// acks, so that the acks do not overflow the receive buffer of the socket.
+const batchSize = 64
+
+// ErrNoAck is the error of the requests of a batch that got no ack.
+var ErrNoAck = errors.New("no ack received")
+
+// executeBatch sends reqs in a single write and stores their acked errors in
+// errs. On failure the requests still waiting for an ack get ErrNoAck.
+func executeBatch(s *NetlinkSocket, sh *SocketHandle, pid uint32, reqs []*NetlinkRequest, errs []error) (err error) {
+	pending := make(map[uint32]int, len(reqs))
+	defer func() {
+		if err != nil {
+			for _, i := range pending {
+				errs[i] = ErrNoAck
+			}
+		}
+	}()
+	var buf []byte
+	for i, req := range reqs {
+		if sh != nil && !req.PreserveSeq {
+			req.Seq = atomic.AddUint32(&sh.Seq, 1)
+		}
+		pending[req.Seq] = i
+		buf = append(buf, req.Serialize()...)
+	}
+	fd := s.GetFd()
+	if fd < 0 {
+		return fmt.Errorf("Send called on a closed socket")
+	}
+	if err = syscall.Sendto(fd, buf, 0, &s.lsa); err != nil {
+		return err
+	}
+
+	for len(pending) > 0 {
+		msgs, err := s.Receive()
+		if err != nil {
+			return err
+		}
+		for _, m := range msgs {
+			i, ok := pending[m.Header.Seq]
+			if !ok || m.Header.Type != syscall.NLMSG_ERROR {
+				continue
+			}
+			if m.Header.Pid != pid {
+				return fmt.Errorf("Wrong pid %d, expected %d", m.Header.Pid, pid)
+			}
+			delete(pending, m.Header.Seq)
+			error := int32(NativeEndian().Uint32(m.Data[0:4]))
+			if error == 0 {
+				continue
+			}
+			errno := syscall.Errno(-error)
+			if msg := extAckMessage(&m); msg != "" {
+				errs[i] = ErrorMessage{Errno: errno, Message: msg}
+			} else {
+				errs[i] = errno
+			}
+		}
+	}
+	return nil
+}
+
+// extAckMessage returns the message of the extended ack carried by an
+// NLMSG_ERROR message, if any.
+func extAckMessage(m *syscall.NetlinkMessage) string {
+	if m.Header.Flags&NLM_F_ACK_TLVS == 0 || len(m.Data) < 4+syscall.SizeofNlMsghdr {
+		return ""
+	}
+	// the error is followed by the header of the failed request, and
+	// by its payload as well unless it was capped
+	offset := 4 + syscall.SizeofNlMsghdr
+	if m.Header.Flags&NLM_F_CAPPED == 0 {
+		offset = 4 + int(NativeEndian().Uint32(m.Data[4:8]))
+	}
+	if offset > len(m.Data) {
+		return ""
+	}
+	attrs, err := ParseRouteAttr(m.Data[offset:])
+	if err != nil {
+		return ""
+	}
+	for _, attr := range attrs {
+		if attr.Attr.Type == NLMSGERR_ATTR_MSG {
+			return string(bytes.TrimRight(attr.Value, "\x00"))
+		}
+	}
+	return ""
 }
 
 // Create a new netlink request from proto and flags
@@ -477,6 +700,9 @@ func getNetlinkSocket(protocol int) (*NetlinkSocket, error) {
 		syscall.Close(fd)
 		return nil, err
 	}
+	if EnableErrorMessageReporting {
+		s.SetExtAck(true)
+	}
 
 	return s, nil
 }
@@ -589,6 +815,35 @@ func (s *NetlinkSocket) Close() {
 	syscall.Close(fd)
 }
 
+// SetExtAck enables or disables extended acks on the socket. When enabled
+// the errors of requests are capped to their header and may carry a
+// message from the kernel. Kernels without support return ENOPROTOOPT.
+func (s *NetlinkSocket) SetExtAck(enable bool) error {
+	var v int
+	if enable {
+		v = 1
+	}
+	fd := int(atomic.LoadInt32(&s.fd))
+	if err := syscall.SetsockoptInt(fd, SOL_NETLINK, NETLINK_EXT_ACK, v); err != nil {
+		return err
+	}
+	return syscall.SetsockoptInt(fd, SOL_NETLINK, NETLINK_CAP_ACK, v)
+}
+
+// JoinGroup subscribes the socket to the multicast group, which unlike the
+// groups passed to Subscribe may be greater than 32.
+func (s *NetlinkSocket) JoinGroup(group uint) error {
+	fd := int(atomic.LoadInt32(&s.fd))
+	return syscall.SetsockoptInt(fd, SOL_NETLINK, syscall.NETLINK_ADD_MEMBERSHIP, int(group))
+}
+
+// LeaveGroup unsubscribes the socket from the multicast group, whether it
+// was joined by Subscribe or by JoinGroup.
+func (s *NetlinkSocket) LeaveGroup(group uint) error {
+	fd := int(atomic.LoadInt32(&s.fd))
+	return syscall.SetsockoptInt(fd, SOL_NETLINK, syscall.NETLINK_DROP_MEMBERSHIP, int(group))
+}
+
 func (s *NetlinkSocket) GetFd() int {
 	return int(atomic.LoadInt32(&s.fd))
 }
@@ -681,6 +936,21 @@ func Uint64Attr(v uint64) []byte {
 	return bytes
 }
 
+func BEUint16Attr(v uint16) []byte {
+	bytes := make([]byte, 2)
+	binary.BigEndian.PutUint16(bytes, v)
+	return bytes
+}
+
+func BEUint32Attr(v uint32) []byte {
+	bytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(bytes, v)
+	return bytes
+}
+
+// ParseRouteAttr parses a buffer of netlink attributes, as produced by
+// RtAttr.Serialize. The value of a nested attribute can be parsed again
+// to get its children.
 func ParseRouteAttr(b []byte) ([]syscall.NetlinkRouteAttr, error) {
 	var attrs []syscall.NetlinkRouteAttr
 	for len(b) >= syscall.SizeofRtAttr {
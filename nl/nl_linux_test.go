@@ -60,3 +60,116 @@ func TestIfInfomsgDeserializeSerialize(t *testing.T) {
 	msg := DeserializeIfInfomsg(orig)
 	testDeserializeSerialize(t, orig, safemsg, msg)
 }
+
+func TestRtAttrNestedSerializeParse(t *testing.T) {
+	native := NativeEndian()
+	root := NewRtAttr(1|syscall.NLA_F_NESTED, nil)
+	root.AddRtAttr(2, Uint32Attr(7))
+	root.AddRtAttr(3, ZeroTerminated("abc"))
+	nest := NewRtAttr(4, nil)
+	nest.AddRtAttr(5, Uint16Attr(0x1234))
+	root.AddChild(nest)
+
+	attr := func(typ, length uint16, data ...byte) []byte {
+		b := make([]byte, 4)
+		native.PutUint16(b[0:2], length)
+		native.PutUint16(b[2:4], typ)
+		return append(b, data...)
+	}
+	u32 := make([]byte, 4)
+	native.PutUint32(u32, 7)
+	u16 := make([]byte, 2)
+	native.PutUint16(u16, 0x1234)
+
+	var body []byte
+	body = append(body, attr(2, 8, u32...)...)
+	body = append(body, attr(3, 8, 'a', 'b', 'c', 0)...)
+	body = append(body, attr(4, 12, append(attr(5, 6, u16...), 0, 0)...)...)
+	expected := attr(1|syscall.NLA_F_NESTED, uint16(4+len(body)), body...)
+
+	b := root.Serialize()
+	if !bytes.Equal(b, expected) {
+		t.Fatalf("Serialization failed.\n%v\n%v", b, expected)
+	}
+	if root.Len() != len(expected) {
+		t.Fatalf("Len is %d, should be %d", root.Len(), len(expected))
+	}
+
+	attrs, err := ParseRouteAttr(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attrs) != 1 || attrs[0].Attr.Type != 1|syscall.NLA_F_NESTED {
+		t.Fatal("Failed to parse the root attribute")
+	}
+	children, err := ParseRouteAttr(attrs[0].Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 3 {
+		t.Fatalf("Expected 3 children, got %d", len(children))
+	}
+	if children[0].Attr.Type != 2 || !bytes.Equal(children[0].Value, u32) {
+		t.Fatal("First child does not match")
+	}
+	if children[1].Attr.Type != 3 || !bytes.Equal(children[1].Value, []byte("abc\x00")) {
+		t.Fatal("Second child does not match")
+	}
+	nested, err := ParseRouteAttr(children[2].Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if children[2].Attr.Type != 4 || len(nested) != 1 || nested[0].Attr.Type != 5 || !bytes.Equal(nested[0].Value, u16) {
+		t.Fatal("Nested child does not match")
+	}
+}
+
+func TestRtAttrUnalignedDataWithChildren(t *testing.T) {
+	attr := NewRtAttr(1, []byte{1, 2, 3})
+	attr.AddRtAttr(2, Uint32Attr(0xffffffff))
+	b := attr.Serialize()
+	// 4 header + 4 padded data + 8 child
+	if attr.Len() != 16 || len(b) != 16 {
+		t.Fatalf("Expected 16 bytes, got Len %d and %d serialized", attr.Len(), len(b))
+	}
+	children, err := ParseRouteAttr(b[8:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 1 || children[0].Attr.Type != 2 {
+		t.Fatal("Child after unaligned data was not serialized")
+	}
+}
+
+func TestNetlinkRequestPreserveSeq(t *testing.T) {
+	s, err := getNetlinkSocket(syscall.NETLINK_ROUTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sockets := map[int]*SocketHandle{syscall.NETLINK_ROUTE: {Socket: s}}
+	defer sockets[syscall.NETLINK_ROUTE].Close()
+
+	req := NewNetlinkRequest(syscall.RTM_GETLINK, syscall.NLM_F_DUMP)
+	req.AddData(NewIfInfomsg(syscall.AF_UNSPEC))
+	req.Seq = 4242
+	req.Sockets = sockets
+	req.PreserveSeq = true
+	if _, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWLINK); err != nil {
+		t.Fatal(err)
+	}
+	if req.Seq != 4242 {
+		t.Fatalf("Request seq is %d, expected 4242", req.Seq)
+	}
+
+	// the raw response carries the sequence number of the request
+	if err := s.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := s.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) == 0 || msgs[0].Header.Seq != 4242 {
+		t.Fatalf("Response does not carry seq 4242: %v", msgs)
+	}
+}
@@ -21,6 +21,14 @@ const (
 	FRA_TABLE  /* Extended table id */
 	FRA_FWMASK /* mask for netfilter mark */
 	FRA_OIFNAME
+	FRA_PAD
+	FRA_L3MDEV /* iif or oif is l3mdev goto its table */
+	FRA_UID_RANGE
+	FRA_PROTOCOL /* Originator of the rule */
+	FRA_IP_PROTO
+	FRA_SPORT_RANGE
+	FRA_DPORT_RANGE
+	FRA_DSCP /* dscp */
 )
 
 // ip rule netlink request types
@@ -36,6 +44,11 @@ const (
 	FR_ACT_PROHIBIT    /* Drop with EACCES */
 )
 
+// tos bits IPv4 rules match on
+const (
+	IPTOS_TOS_MASK = 0x1E /* linux/ip.h */
+)
+
 // socket diags related
 const (
 	SOCK_DIAG_BY_FAMILY = 20         /* linux.sock_diag.h */
@@ -46,10 +59,43 @@ const (
 	AF_MPLS = 28
 )
 
+// rtm_family of the multicast routing tables
+const (
+	RTNL_FAMILY_IPMR  = 128
+	RTNL_FAMILY_IP6MR = 129
+)
+
+const (
+	RTA_MFC_STATS     = 0x11
+	RTA_VIA           = 0x12
+	RTA_NEWDST        = 0x13
+	RTA_ENCAP_TYPE    = 0x15
+	RTA_ENCAP         = 0x16
+	RTA_UID           = 0x19
+	RTA_TTL_PROPAGATE = 0x1a
+	RTA_NH_ID         = 0x1e
+)
+
+// RTA_METRICS types missing from the syscall package
 const (
-	RTA_NEWDST     = 0x13
-	RTA_ENCAP_TYPE = 0x15
-	RTA_ENCAP      = 0x16
+	RTAX_QUICKACK           = 0xf
+	RTAX_CC_ALGO            = 0x10
+	RTAX_FASTOPEN_NO_COOKIE = 0x11
+)
+
+// rtmsg flags missing from the syscall package
+const (
+	RTM_F_FIB_MATCH = 0x2000 /* return full fib lookup match */
+)
+
+// rtnexthop flags missing from the syscall package
+const (
+	RTNH_F_DEAD       = 0x1  /* Nexthop is dead (used by multipath) */
+	RTNH_F_PERVASIVE  = 0x2  /* Do recursive gateway lookup */
+	RTNH_F_ONLINK     = 0x4  /* Gateway is forced on link */
+	RTNH_F_OFFLOAD    = 0x8  /* offloaded route */
+	RTNH_F_LINKDOWN   = 0x10 /* carrier-down on nexthop */
+	RTNH_F_UNRESOLVED = 0x20 /* The entry is unresolved (ipmr) */
 )
 
 // RTA_ENCAP subtype
@@ -58,6 +104,20 @@ const (
 	MPLS_IPTUNNEL_DST
 )
 
+// RTA_ENCAP subtype of LWTUNNEL_ENCAP_ILA
+const (
+	ILA_ATTR_UNSPEC = iota
+	ILA_ATTR_LOCATOR
+	ILA_ATTR_IDENTIFIER
+	ILA_ATTR_LOCATOR_MATCH
+	ILA_ATTR_IFINDEX
+	ILA_ATTR_DIR
+	ILA_ATTR_PAD
+	ILA_ATTR_CSUM_MODE
+	ILA_ATTR_IDENT_TYPE
+	ILA_ATTR_HOOK_TYPE
+)
+
 // light weight tunnel encap types
 const (
 	LWTUNNEL_ENCAP_NONE = iota
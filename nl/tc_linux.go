@@ -41,7 +41,24 @@ const (
 	TCA_FCNT
 	TCA_STATS2
 	TCA_STAB
-	TCA_MAX = TCA_STAB
+	TCA_PAD
+	TCA_DUMP_INVISIBLE
+	TCA_CHAIN
+	TCA_MAX = TCA_CHAIN
+)
+
+// Statistics nested in TCA_STATS2
+const (
+	TCA_STATS_UNSPEC = iota
+	TCA_STATS_BASIC
+	TCA_STATS_RATE_EST
+	TCA_STATS_QUEUE
+	TCA_STATS_APP
+	TCA_STATS_RATE_EST64
+	TCA_STATS_PAD
+	TCA_STATS_BASIC_HW
+	TCA_STATS_PKT64
+	TCA_STATS_MAX = TCA_STATS_PKT64
 )
 
 const (
@@ -74,12 +91,15 @@ const (
 	SizeofTcNetemReorder = 0x08
 	SizeofTcNetemCorrupt = 0x08
 	SizeofTcTbfQopt      = 2*SizeofTcRateSpec + 0x0c
+	SizeofTcFifoQopt     = 0x04
 	SizeofTcHtbCopt      = 2*SizeofTcRateSpec + 0x14
 	SizeofTcHtbGlob      = 0x14
 	SizeofTcU32Key       = 0x10
 	SizeofTcU32Sel       = 0x10 // without keys
 	SizeofTcGen          = 0x14
 	SizeofTcMirred       = SizeofTcGen + 0x08
+	SizeofTcConnmark     = SizeofTcGen + 0x04
+	SizeofTcVlan         = SizeofTcGen + 0x04
 	SizeofTcPolice       = 2*SizeofTcRateSpec + 0x20
 )
 
@@ -348,6 +368,26 @@ func (x *TcTbfQopt) Serialize() []byte {
 	return (*(*[SizeofTcTbfQopt]byte)(unsafe.Pointer(x)))[:]
 }
 
+// struct tc_fifo_qopt {
+//   __u32   limit;  /* Queue length: bytes for bfifo, packets for pfifo */
+// };
+
+type TcFifoQopt struct {
+	Limit uint32
+}
+
+func (msg *TcFifoQopt) Len() int {
+	return SizeofTcFifoQopt
+}
+
+func DeserializeTcFifoQopt(b []byte) *TcFifoQopt {
+	return (*TcFifoQopt)(unsafe.Pointer(&b[0:SizeofTcFifoQopt][0]))
+}
+
+func (x *TcFifoQopt) Serialize() []byte {
+	return (*(*[SizeofTcFifoQopt]byte)(unsafe.Pointer(x)))[:]
+}
+
 const (
 	TCA_HTB_UNSPEC = iota
 	TCA_HTB_PARMS
@@ -412,6 +452,19 @@ func (x *TcHtbGlob) Serialize() []byte {
 	return (*(*[SizeofTcHtbGlob]byte)(unsafe.Pointer(x)))[:]
 }
 
+const (
+	TCA_DRR_UNSPEC = iota
+	TCA_DRR_QUANTUM
+	TCA_DRR_MAX = TCA_DRR_QUANTUM
+)
+
+const (
+	TCA_QFQ_UNSPEC = iota
+	TCA_QFQ_WEIGHT
+	TCA_QFQ_LMAX
+	TCA_QFQ_MAX = TCA_QFQ_LMAX
+)
+
 const (
 	TCA_U32_UNSPEC = iota
 	TCA_U32_CLASSID
@@ -626,6 +679,94 @@ func (x *TcMirred) Serialize() []byte {
 	return (*(*[SizeofTcMirred]byte)(unsafe.Pointer(x)))[:]
 }
 
+const (
+	TCA_ACT_SKBEDIT = 11
+)
+
+const (
+	TCA_SKBEDIT_UNSPEC = iota
+	TCA_SKBEDIT_TM
+	TCA_SKBEDIT_PARMS
+	TCA_SKBEDIT_PRIORITY
+	TCA_SKBEDIT_QUEUE_MAPPING
+	TCA_SKBEDIT_MARK
+	TCA_SKBEDIT_PAD
+	TCA_SKBEDIT_PTYPE
+	TCA_SKBEDIT_MASK
+	TCA_SKBEDIT_FLAGS
+	TCA_SKBEDIT_MAX = TCA_SKBEDIT_FLAGS
+)
+
+const (
+	TCA_ACT_CONNMARK = 14
+)
+
+const (
+	TCA_CONNMARK_UNSPEC = iota
+	TCA_CONNMARK_PARMS
+	TCA_CONNMARK_TM
+	TCA_CONNMARK_MAX = TCA_CONNMARK_TM
+)
+
+// struct tc_connmark {
+// 	tc_gen;
+// 	__u16 zone;
+// };
+
+type TcConnmark struct {
+	TcGen
+	Zone uint16
+}
+
+func (msg *TcConnmark) Len() int {
+	return SizeofTcConnmark
+}
+
+func DeserializeTcConnmark(b []byte) *TcConnmark {
+	return (*TcConnmark)(unsafe.Pointer(&b[0:SizeofTcConnmark][0]))
+}
+
+func (x *TcConnmark) Serialize() []byte {
+	return (*(*[SizeofTcConnmark]byte)(unsafe.Pointer(x)))[:]
+}
+
+const (
+	TCA_ACT_VLAN = 12
+)
+
+const (
+	TCA_VLAN_UNSPEC = iota
+	TCA_VLAN_TM
+	TCA_VLAN_PARMS
+	TCA_VLAN_PUSH_VLAN_ID
+	TCA_VLAN_PUSH_VLAN_PROTOCOL
+	TCA_VLAN_PAD
+	TCA_VLAN_PUSH_VLAN_PRIORITY
+	TCA_VLAN_MAX = TCA_VLAN_PUSH_VLAN_PRIORITY
+)
+
+// struct tc_vlan {
+// 	tc_gen;
+// 	int v_action;
+// };
+
+type TcVlan struct {
+	TcGen
+	VAction int32
+}
+
+func (msg *TcVlan) Len() int {
+	return SizeofTcVlan
+}
+
+func DeserializeTcVlan(b []byte) *TcVlan {
+	return (*TcVlan)(unsafe.Pointer(&b[0:SizeofTcVlan][0]))
+}
+
+func (x *TcVlan) Serialize() []byte {
+	return (*(*[SizeofTcVlan]byte)(unsafe.Pointer(x)))[:]
+}
+
 // struct tc_police {
 // 	__u32			index;
 // 	int			action;
@@ -664,6 +805,16 @@ func (x *TcPolice) Serialize() []byte {
 	return (*(*[SizeofTcPolice]byte)(unsafe.Pointer(x)))[:]
 }
 
+const (
+	TCA_MATCHALL_UNSPEC = iota
+	TCA_MATCHALL_CLASSID
+	TCA_MATCHALL_ACT
+	TCA_MATCHALL_FLAGS
+	TCA_MATCHALL_PCNT
+	TCA_MATCHALL_PAD
+	TCA_MATCHALL_MAX = TCA_MATCHALL_PAD
+)
+
 const (
 	TCA_FW_UNSPEC = iota
 	TCA_FW_CLASSID
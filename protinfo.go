@@ -12,8 +12,11 @@ type Protinfo struct {
 	RootBlock    bool
 	Learning     bool
 	Flood        bool
+	McastFlood   bool
 	ProxyArp     bool
 	ProxyArpWiFi bool
+	McastRouter  uint8 // one of BRIDGE_MCAST_ROUTER_*
+	VlanTunnel   bool
 }
 
 // String returns a list of enabled flags
@@ -37,12 +40,18 @@ func (prot *Protinfo) String() string {
 	if prot.Flood {
 		boolStrings = append(boolStrings, "Flood")
 	}
+	if prot.McastFlood {
+		boolStrings = append(boolStrings, "McastFlood")
+	}
 	if prot.ProxyArp {
 		boolStrings = append(boolStrings, "ProxyArp")
 	}
 	if prot.ProxyArpWiFi {
 		boolStrings = append(boolStrings, "ProxyArpWiFi")
 	}
+	if prot.VlanTunnel {
+		boolStrings = append(boolStrings, "VlanTunnel")
+	}
 	return strings.Join(boolStrings, " ")
 }
 
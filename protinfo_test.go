@@ -161,3 +161,38 @@ func TestProtinfo(t *testing.T) {
 		t.Fatalf("Flood field was changed for %s but shouldn't", iface4.Name)
 	}
 }
+
+func TestProtinfoLinkDecode(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	master := &Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(master); err != nil {
+		t.Fatal(err)
+	}
+	port := &Veth{LinkAttrs: LinkAttrs{Name: "bar", MasterIndex: master.Index}, PeerName: "bar-peer"}
+	if err := LinkAdd(port); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LinkSetHairpin(port, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetMcastFlood(port, false); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pi := link.Attrs().Protinfo
+	if pi == nil {
+		t.Fatalf("Bridge port flags not decoded for %s", port.Name)
+	}
+	if !pi.Hairpin {
+		t.Fatalf("Hairpin mode is not enabled for %s, but should", port.Name)
+	}
+	if pi.McastFlood {
+		t.Fatalf("McastFlood is enabled for %s, but shouldn't", port.Name)
+	}
+}
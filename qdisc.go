@@ -3,6 +3,8 @@ package netlink
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -30,20 +32,38 @@ type QdiscAttrs struct {
 	Handle    uint32
 	Parent    uint32
 	Refcnt    uint32 // read only
+	// Statistics of the qdisc, read only
+	Statistics *QdiscStatistics
+}
+
+// QdiscStatistics holds the counters reported by `tc -s qdisc`.
+type QdiscStatistics struct {
+	Bytes      uint64
+	Packets    uint64
+	Drops      uint32
+	Overlimits uint32
+	Requeues   uint32
+	Backlog    uint32
+	Qlen       uint32
+	BpsRate    uint64
+	PpsRate    uint64
 }
 
 func (q QdiscAttrs) String() string {
 	return fmt.Sprintf("{LinkIndex: %d, Handle: %s, Parent: %s, Refcnt: %d}", q.LinkIndex, HandleStr(q.Handle), HandleStr(q.Parent), q.Refcnt)
 }
 
+// MakeHandle returns the tc handle major:minor.
 func MakeHandle(major, minor uint16) uint32 {
 	return (uint32(major) << 16) | uint32(minor)
 }
 
+// MajorMinor splits a tc handle into its major and minor numbers.
 func MajorMinor(handle uint32) (uint16, uint16) {
-	return uint16((handle & 0xFFFF0000) >> 16), uint16(handle & 0x0000FFFFF)
+	return uint16((handle & 0xFFFF0000) >> 16), uint16(handle & 0x0000FFFF)
 }
 
+// HandleStr renders a tc handle the way tc does, e.g. "1:10" or "root".
 func HandleStr(handle uint32) string {
 	switch handle {
 	case HANDLE_NONE:
@@ -58,6 +78,34 @@ func HandleStr(handle uint32) string {
 	}
 }
 
+// ParseHandle parses a tc handle rendered by HandleStr. The major and
+// minor numbers are hexadecimal and the minor may be omitted, as in "1:".
+func ParseHandle(s string) (uint32, error) {
+	switch s {
+	case "none":
+		return HANDLE_NONE, nil
+	case "ingress":
+		return HANDLE_INGRESS, nil
+	case "root":
+		return HANDLE_ROOT, nil
+	}
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return 0, fmt.Errorf("invalid tc handle %q", s)
+	}
+	major, err := strconv.ParseUint(s[:i], 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid major of tc handle %q", s)
+	}
+	var minor uint64
+	if i+1 < len(s) {
+		if minor, err = strconv.ParseUint(s[i+1:], 16, 16); err != nil {
+			return 0, fmt.Errorf("invalid minor of tc handle %q", s)
+		}
+	}
+	return MakeHandle(uint16(major), uint16(minor)), nil
+}
+
 func Percentage2u32(percentage float32) uint32 {
 	// FIXME this is most likely not the best way to convert from % to uint32
 	if percentage == 100 {
@@ -203,6 +251,36 @@ func (qdisc *Tbf) Type() string {
 	return "tbf"
 }
 
+// Pfifo is a classless qdisc queueing up to Limit packets, the kernel
+// uses the tx queue length of the link when Limit is 0
+type Pfifo struct {
+	QdiscAttrs
+	Limit uint32
+}
+
+func (qdisc *Pfifo) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Pfifo) Type() string {
+	return "pfifo"
+}
+
+// Bfifo is a classless qdisc queueing up to Limit bytes, the kernel
+// derives it from the tx queue length and MTU of the link when Limit is 0
+type Bfifo struct {
+	QdiscAttrs
+	Limit uint32
+}
+
+func (qdisc *Bfifo) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Bfifo) Type() string {
+	return "bfifo"
+}
+
 // Ingress is a qdisc for adding ingress filters
 type Ingress struct {
 	QdiscAttrs
@@ -216,6 +294,33 @@ func (qdisc *Ingress) Type() string {
 	return "ingress"
 }
 
+// Drr is a classful qdisc that schedules its classes using deficit
+// round robin
+type Drr struct {
+	QdiscAttrs
+}
+
+func (qdisc *Drr) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Drr) Type() string {
+	return "drr"
+}
+
+// Qfq is a classful qdisc implementing quick fair queueing
+type Qfq struct {
+	QdiscAttrs
+}
+
+func (qdisc *Qfq) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Qfq) Type() string {
+	return "qfq"
+}
+
 // GenericQdisc qdiscs represent types that are not currently understood
 // by this netlink library.
 type GenericQdisc struct {
@@ -1,8 +1,12 @@
 package netlink
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -152,9 +156,117 @@ func (h *Handle) qdiscModify(cmd, flags int, qdisc Qdisc) error {
 	}
 
 	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	if cmd != syscall.RTM_DELQDISC {
+		return tcKindError(err, "qdisc", qdisc.Type(), qdiscKinds)
+	}
 	return err
 }
 
+// qdiscBuiltinKinds are always built into a kernel supporting tc, the
+// other kinds come from their sch_ modules.
+var qdiscBuiltinKinds = []string{"blackhole", "bfifo", "mq", "noop", "noqueue", "pfifo", "pfifo_fast", "pfifo_head_drop"}
+
+// QdiscKinds returns the qdisc kinds the kernel supports, from the kinds
+// always built in and the sch_ modules that are loaded, built in or
+// installed. The kinds of modules built in can only be found when the
+// modules of the running kernel are installed.
+func QdiscKinds() ([]string, error) {
+	kinds, _, err := qdiscKinds()
+	return kinds, err
+}
+
+func qdiscKinds() ([]string, bool, error) {
+	kinds, installed, err := tcModuleKinds("sch_")
+	if err != nil {
+		return nil, false, err
+	}
+	for _, kind := range kinds {
+		if kind == "ingress" {
+			// sch_ingress also provides clsact
+			kinds = append(kinds, "clsact")
+			break
+		}
+	}
+	kinds = append(kinds, qdiscBuiltinKinds...)
+	sort.Strings(kinds)
+	return kinds, installed, nil
+}
+
+// tcModuleKinds returns the kinds provided by the tc modules whose name
+// starts with prefix, from /proc/modules and the modules.builtin and
+// modules.dep files of the running kernel, which may be missing. It also
+// reports whether the modules of the running kernel are installed, only
+// then are the kinds complete.
+func tcModuleKinds(prefix string) ([]string, bool, error) {
+	var uts syscall.Utsname
+	if err := syscall.Uname(&uts); err != nil {
+		return nil, false, err
+	}
+	release := make([]byte, 0, len(uts.Release))
+	for _, c := range uts.Release {
+		if c == 0 {
+			break
+		}
+		release = append(release, byte(c))
+	}
+	moduleDir := path.Join("/lib/modules", string(release))
+
+	kinds := map[string]bool{}
+	installed := false
+	for _, file := range []string{"/proc/modules", path.Join(moduleDir, "modules.builtin"), path.Join(moduleDir, "modules.dep")} {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, false, err
+		}
+		if path.Base(file) == "modules.dep" {
+			installed = true
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			// the module name, or the path of its object file
+			name := strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == ':' })
+			if len(name) == 0 {
+				continue
+			}
+			module := path.Base(name[0])
+			if i := strings.Index(module, ".ko"); i >= 0 {
+				module = module[:i]
+			}
+			if strings.HasPrefix(module, prefix) {
+				kinds[strings.TrimPrefix(module, prefix)] = true
+			}
+		}
+	}
+	res := make([]string, 0, len(kinds))
+	for kind := range kinds {
+		res = append(res, kind)
+	}
+	sort.Strings(res)
+	return res, installed, nil
+}
+
+// tcKindError adds a hint to the ENOENT the kernel answers for an unknown
+// qdisc, filter or action kind. The kernel answers the same for a missing
+// parent, so the hint is only added when the modules of the running kernel
+// are installed and none of them provides kind.
+func tcKindError(err error, object, kind string, supported func() ([]string, bool, error)) error {
+	if !errors.Is(err, syscall.ENOENT) {
+		return err
+	}
+	kinds, installed, kindsErr := supported()
+	if kindsErr != nil || !installed {
+		return err
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: the kernel may be missing the module of the %s %s", err, kind, object)
+}
+
 func qdiscPayload(req *nl.NetlinkRequest, qdisc Qdisc) error {
 
 	req.AddData(nl.NewRtAttr(nl.TCA_KIND, nl.ZeroTerminated(qdisc.Type())))
@@ -182,6 +294,20 @@ func qdiscPayload(req *nl.NetlinkRequest, qdisc Qdisc) error {
 		if tbf.Peakrate > 0 {
 			nl.NewRtAttrChild(options, nl.TCA_TBF_PBURST, nl.Uint32Attr(tbf.Minburst))
 		}
+	} else if pfifo, ok := qdisc.(*Pfifo); ok {
+		if pfifo.Limit == 0 {
+			// the kernel only applies its default limit without options
+			return nil
+		}
+		opt := nl.TcFifoQopt{Limit: pfifo.Limit}
+		options = nl.NewRtAttr(nl.TCA_OPTIONS, opt.Serialize())
+	} else if bfifo, ok := qdisc.(*Bfifo); ok {
+		if bfifo.Limit == 0 {
+			// the kernel only applies its default limit without options
+			return nil
+		}
+		opt := nl.TcFifoQopt{Limit: bfifo.Limit}
+		options = nl.NewRtAttr(nl.TCA_OPTIONS, opt.Serialize())
 	} else if htb, ok := qdisc.(*Htb); ok {
 		opt := nl.TcHtbGlob{}
 		opt.Version = htb.Version
@@ -297,12 +423,20 @@ func (h *Handle) QdiscList(link Link) ([]Qdisc, error) {
 					qdisc = &Prio{}
 				case "tbf":
 					qdisc = &Tbf{}
+				case "pfifo":
+					qdisc = &Pfifo{}
+				case "bfifo":
+					qdisc = &Bfifo{}
 				case "ingress":
 					qdisc = &Ingress{}
 				case "htb":
 					qdisc = &Htb{}
 				case "netem":
 					qdisc = &Netem{}
+				case "drr":
+					qdisc = &Drr{}
+				case "qfq":
+					qdisc = &Qfq{}
 				default:
 					qdisc = &GenericQdisc{QdiscType: qdiscType}
 				}
@@ -326,6 +460,11 @@ func (h *Handle) QdiscList(link Link) ([]Qdisc, error) {
 					if err := parseTbfData(qdisc, data); err != nil {
 						return nil, err
 					}
+				case "pfifo", "bfifo":
+					// fifos return tc_fifo_qopt directly without wrapping it in rtattr
+					if err := parseFifoData(qdisc, attr.Value); err != nil {
+						return nil, err
+					}
 				case "htb":
 					data, err := nl.ParseRouteAttr(attr.Value)
 					if err != nil {
@@ -341,6 +480,16 @@ func (h *Handle) QdiscList(link Link) ([]Qdisc, error) {
 
 					// no options for ingress
 				}
+			case nl.TCA_STATS:
+				if base.Statistics == nil {
+					base.Statistics = parseTcStats(attr.Value)
+				}
+			case nl.TCA_STATS2:
+				stats, err := parseTcStats2(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				base.Statistics = stats
 			}
 		}
 		*qdisc.Attrs() = base
@@ -419,6 +568,20 @@ func parseNetemData(qdisc Qdisc, value []byte) error {
 	return nil
 }
 
+func parseFifoData(qdisc Qdisc, value []byte) error {
+	if len(value) < nl.SizeofTcFifoQopt {
+		return fmt.Errorf("Lack of bytes")
+	}
+	opt := nl.DeserializeTcFifoQopt(value)
+	switch fifo := qdisc.(type) {
+	case *Pfifo:
+		fifo.Limit = opt.Limit
+	case *Bfifo:
+		fifo.Limit = opt.Limit
+	}
+	return nil
+}
+
 func parseTbfData(qdisc Qdisc, data []syscall.NetlinkRouteAttr) error {
 	native = nl.NativeEndian()
 	tbf := qdisc.(*Tbf)
@@ -525,3 +688,66 @@ func latency(rate uint64, limit, buffer uint32) float64 {
 func Xmittime(rate uint64, size uint32) float64 {
 	return TickInUsec() * TIME_UNITS_PER_SEC * (float64(size) / float64(rate))
 }
+
+// parseTcStats decodes the legacy struct tc_stats.
+func parseTcStats(b []byte) *QdiscStatistics {
+	if len(b) < 36 {
+		return nil
+	}
+	return &QdiscStatistics{
+		Bytes:      native.Uint64(b[0:8]),
+		Packets:    uint64(native.Uint32(b[8:12])),
+		Drops:      native.Uint32(b[12:16]),
+		Overlimits: native.Uint32(b[16:20]),
+		BpsRate:    uint64(native.Uint32(b[20:24])),
+		PpsRate:    uint64(native.Uint32(b[24:28])),
+		Qlen:       native.Uint32(b[28:32]),
+		Backlog:    native.Uint32(b[32:36]),
+	}
+}
+
+// parseTcStats2 decodes the gnet_stats_* structs nested in TCA_STATS2.
+func parseTcStats2(b []byte) (*QdiscStatistics, error) {
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return nil, err
+	}
+	stats := &QdiscStatistics{}
+	var rate64 bool
+	for _, attr := range attrs {
+		v := attr.Value
+		switch attr.Attr.Type {
+		case nl.TCA_STATS_BASIC:
+			if len(v) >= 12 {
+				stats.Bytes = native.Uint64(v[0:8])
+				if stats.Packets == 0 {
+					stats.Packets = uint64(native.Uint32(v[8:12]))
+				}
+			}
+		case nl.TCA_STATS_PKT64:
+			if len(v) >= 8 {
+				stats.Packets = native.Uint64(v[0:8])
+			}
+		case nl.TCA_STATS_RATE_EST:
+			if len(v) >= 8 && !rate64 {
+				stats.BpsRate = uint64(native.Uint32(v[0:4]))
+				stats.PpsRate = uint64(native.Uint32(v[4:8]))
+			}
+		case nl.TCA_STATS_RATE_EST64:
+			if len(v) >= 16 {
+				stats.BpsRate = native.Uint64(v[0:8])
+				stats.PpsRate = native.Uint64(v[8:16])
+				rate64 = true
+			}
+		case nl.TCA_STATS_QUEUE:
+			if len(v) >= 20 {
+				stats.Qlen = native.Uint32(v[0:4])
+				stats.Backlog = native.Uint32(v[4:8])
+				stats.Drops = native.Uint32(v[8:12])
+				stats.Requeues = native.Uint32(v[12:16])
+				stats.Overlimits = native.Uint32(v[16:20])
+			}
+		}
+	}
+	return stats, nil
+}
@@ -3,9 +3,51 @@
 package netlink
 
 import (
+	"errors"
+	"net"
+	"strings"
+	"syscall"
 	"testing"
 )
 
+func TestHandleStrParse(t *testing.T) {
+	tests := []struct {
+		handle uint32
+		str    string
+	}{
+		{HANDLE_ROOT, "root"},
+		{HANDLE_INGRESS, "ingress"},
+		{HANDLE_NONE, "none"},
+		{MakeHandle(1, 0), "1:0"},
+		{MakeHandle(1, 0x10), "1:10"},
+		{MakeHandle(0xffff, 0xfff0), "ffff:fff0"},
+	}
+	for _, tt := range tests {
+		if s := HandleStr(tt.handle); s != tt.str {
+			t.Fatalf("HandleStr(%#x) is %q, expected %q", tt.handle, s, tt.str)
+		}
+		handle, err := ParseHandle(tt.str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if handle != tt.handle {
+			t.Fatalf("ParseHandle(%q) is %#x, expected %#x", tt.str, handle, tt.handle)
+		}
+	}
+
+	if major, minor := MajorMinor(MakeHandle(1, 0x10)); major != 1 || minor != 0x10 {
+		t.Fatalf("MajorMinor is %x:%x, expected 1:10", major, minor)
+	}
+	if handle, err := ParseHandle("1:"); err != nil || handle != MakeHandle(1, 0) {
+		t.Fatalf("ParseHandle(\"1:\") is %#x, %v", handle, err)
+	}
+	for _, s := range []string{"", "1", "x:1", "1:x", "10000:0", "1:10000"} {
+		if _, err := ParseHandle(s); err == nil {
+			t.Fatalf("ParseHandle(%q) should fail", s)
+		}
+	}
+}
+
 func TestTbfAddDel(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -122,6 +164,166 @@ func TestHtbAddDel(t *testing.T) {
 	}
 }
 
+func TestHtbStatistics(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []Link{link, peer} {
+		if err := LinkSetUp(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addr, err := ParseAddr("10.99.0.1/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AddrAdd(link, addr); err != nil {
+		t.Fatal(err)
+	}
+	dst := net.ParseIP("10.99.0.2")
+	if err := NeighAdd(&Neigh{
+		LinkIndex:    link.Attrs().Index,
+		State:        NUD_PERMANENT,
+		IP:           dst,
+		HardwareAddr: peer.Attrs().HardwareAddr,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	qdisc := NewHtb(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: dst, Port: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for i := 0; i < 10; i++ {
+		if _, err := conn.Write([]byte("netlink")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	qdiscs, err := SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	stats := qdiscs[0].Attrs().Statistics
+	if stats == nil {
+		t.Fatal("Qdisc statistics not decoded")
+	}
+	if stats.Packets < 10 || stats.Bytes == 0 {
+		t.Fatalf("Qdisc statistics are %+v, expected at least 10 packets", *stats)
+	}
+}
+
+func TestPfifoUnderHtbClass(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	htb := NewHtb(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	if err := QdiscAdd(htb); err != nil {
+		t.Fatal(err)
+	}
+	class := NewHtbClass(ClassAttrs{
+		LinkIndex: link.Attrs().Index,
+		Parent:    MakeHandle(1, 0),
+		Handle:    MakeHandle(1, 1),
+	}, HtbClassAttrs{Rate: 1000000})
+	if err := ClassAdd(class); err != nil {
+		t.Fatal(err)
+	}
+
+	pfifo := &Pfifo{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(10, 0),
+			Parent:    MakeHandle(1, 1),
+		},
+		Limit: 100,
+	}
+	if err := QdiscAdd(pfifo); err != nil {
+		t.Fatal(err)
+	}
+
+	qdiscs, err := SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result *Pfifo
+	for _, qdisc := range qdiscs {
+		if p, ok := qdisc.(*Pfifo); ok {
+			result = p
+		}
+	}
+	if result == nil {
+		t.Fatal("Failed to add pfifo qdisc")
+	}
+	if result.Parent != pfifo.Parent {
+		t.Fatalf("Pfifo parent is %s, expected %s", HandleStr(result.Parent), HandleStr(pfifo.Parent))
+	}
+	if result.Limit != pfifo.Limit {
+		t.Fatalf("Pfifo limit is %d, expected %d", result.Limit, pfifo.Limit)
+	}
+
+	if err := QdiscDel(pfifo); err != nil {
+		t.Fatal(err)
+	}
+	bfifo := &Bfifo{QdiscAttrs: pfifo.QdiscAttrs, Limit: 15000}
+	if err := QdiscAdd(bfifo); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err = SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, qdisc := range qdiscs {
+		if b, ok := qdisc.(*Bfifo); ok {
+			found = true
+			if b.Limit != bfifo.Limit {
+				t.Fatalf("Bfifo limit is %d, expected %d", b.Limit, bfifo.Limit)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("Failed to add bfifo qdisc")
+	}
+}
+
 func TestPrioAddDel(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -345,3 +547,102 @@ func TestTbfAddTbfChangeDel(t *testing.T) {
 		t.Fatal("Failed to remove qdisc")
 	}
 }
+
+func TestQdiscFilterKinds(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	hasKind := func(kinds []string, want string) bool {
+		for _, kind := range kinds {
+			if kind == want {
+				return true
+			}
+		}
+		return false
+	}
+
+	// pfifo_fast is built in, the modules don't matter
+	kinds, err := QdiscKinds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasKind(kinds, "pfifo_fast") {
+		t.Fatalf("pfifo_fast not in the qdisc kinds %v", kinds)
+	}
+
+	if _, installed, err := qdiscKinds(); err != nil {
+		t.Fatal(err)
+	} else if !installed {
+		t.Skip("The modules of the running kernel are not installed")
+	}
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// htb is provided by the sch_htb module
+	qdisc := NewHtb(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	kinds, err = QdiscKinds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasKind(kinds, "htb") {
+		t.Fatalf("htb not in the qdisc kinds %v", kinds)
+	}
+	if _, err := FilterKinds(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQdiscAddUnknownKind(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &GenericQdisc{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(1, 0),
+			Parent:    HANDLE_ROOT,
+		},
+		QdiscType: "nosuchqdisc",
+	}
+	err = QdiscAdd(qdisc)
+	if !errors.Is(err, syscall.ENOENT) {
+		t.Fatalf("Adding an unknown qdisc kind returned %v, expected ENOENT", err)
+	}
+	_, installed, kindsErr := qdiscKinds()
+	if kindsErr != nil {
+		t.Fatal(kindsErr)
+	}
+	if installed && !strings.Contains(err.Error(), "nosuchqdisc") {
+		t.Fatalf("Error %q doesn't hint at the qdisc kind", err)
+	}
+
+	// the kernel answers ENOENT for a missing parent as well
+	qdisc = &GenericQdisc{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(2, 0),
+			Parent:    MakeHandle(0x10, 1),
+		},
+		QdiscType: "pfifo",
+	}
+	err = QdiscAdd(qdisc)
+	if !errors.Is(err, syscall.ENOENT) {
+		t.Fatalf("Adding a qdisc to a missing parent returned %v, expected ENOENT", err)
+	}
+	if strings.Contains(err.Error(), "module") {
+		t.Fatalf("Error %q hints at a missing module for a missing parent", err)
+	}
+}
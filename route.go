@@ -27,22 +27,48 @@ type Encap interface {
 
 // Route represents a netlink route.
 type Route struct {
-	LinkIndex  int
-	ILinkIndex int
-	Scope      Scope
-	Dst        *net.IPNet
-	Src        net.IP
-	Gw         net.IP
-	MultiPath  []*NexthopInfo
-	Protocol   int
-	Priority   int
-	Table      int
-	Type       int
-	Tos        int
-	Flags      int
-	MPLSDst    *int
-	NewDst     Destination
-	Encap      Encap
+	LinkIndex    int
+	ILinkIndex   int
+	Scope        Scope
+	Dst          *net.IPNet
+	Src          net.IP
+	SrcNet       *net.IPNet // RTA_SRC prefix of IPv6 source specific routes
+	Gw           net.IP
+	Via          *Via // gateway of a different address family than the route
+	MultiPath    []*NexthopInfo
+	Protocol     int
+	Priority     int // RTA_PRIORITY; IPv6 routes added with 0 list as 1024
+	Table        int
+	Type         int
+	Tos          int
+	Flags        int
+	MPLSDst      *int
+	NewDst       Destination
+	Encap        Encap
+	TTLPropagate *int // MPLS only: 0 disables, 1 enables
+	Realm        int  // RTA_FLOW realms, used by the tc route classifier
+	// Metrics holds the numeric RTA_METRICS of the route keyed by
+	// their RTAX_* type, e.g. syscall.RTAX_ADVMSS or nl.RTAX_QUICKACK.
+	Metrics map[int]uint32
+	// MfcStats are the forwarding counters of a multicast route, only
+	// set on routes listed with the nl.RTNL_FAMILY_IPMR family.
+	MfcStats *MfcStats
+	// Uid is the uid a route returned by RouteGetWithOptions was looked
+	// up for. The kernel has no per route uid, routing by uid is done by
+	// rules with a UIDRange, so it is not sent when adding routes.
+	Uid *uint32
+	// NHID is the id of the nexthop object the route goes through, 0 if
+	// its nexthops are part of the route.
+	NHID uint32
+}
+
+// MfcStats are the counters of a multicast forwarding cache entry.
+// WrongIf counts packets received on an interface other than the
+// expected incoming one.
+type MfcStats struct {
+	Packets uint64
+	Bytes   uint64
+	WrongIf uint64
 }
 
 func (r Route) String() string {
@@ -51,22 +77,43 @@ func (r Route) String() string {
 		elems = append(elems, fmt.Sprintf("Ifindex: %d", r.LinkIndex))
 	}
 	if r.MPLSDst != nil {
-		elems = append(elems, fmt.Sprintf("Dst: %d", r.MPLSDst))
+		elems = append(elems, fmt.Sprintf("Dst: %d", *r.MPLSDst))
 	} else {
 		elems = append(elems, fmt.Sprintf("Dst: %s", r.Dst))
 	}
 	if r.NewDst != nil {
 		elems = append(elems, fmt.Sprintf("NewDst: %s", r.NewDst))
 	}
+	if t := r.typeString(); t != "" {
+		elems = append(elems, fmt.Sprintf("Type: %s", t))
+	}
 	if r.Encap != nil {
 		elems = append(elems, fmt.Sprintf("Encap: %s", r.Encap))
 	}
+	if r.TTLPropagate != nil {
+		elems = append(elems, fmt.Sprintf("TTLPropagate: %d", *r.TTLPropagate))
+	}
+	if r.Realm != 0 {
+		elems = append(elems, fmt.Sprintf("Realm: %d", r.Realm))
+	}
+	if len(r.Metrics) > 0 {
+		elems = append(elems, fmt.Sprintf("Metrics: %v", r.Metrics))
+	}
+	if r.MfcStats != nil {
+		elems = append(elems, fmt.Sprintf("MfcStats: %+v", *r.MfcStats))
+	}
 	elems = append(elems, fmt.Sprintf("Src: %s", r.Src))
+	if r.SrcNet != nil {
+		elems = append(elems, fmt.Sprintf("SrcNet: %s", r.SrcNet))
+	}
 	if len(r.MultiPath) > 0 {
 		elems = append(elems, fmt.Sprintf("Gw: %s", r.MultiPath))
 	} else {
 		elems = append(elems, fmt.Sprintf("Gw: %s", r.Gw))
 	}
+	if r.Via != nil {
+		elems = append(elems, fmt.Sprintf("Via: %s", r.Via))
+	}
 	elems = append(elems, fmt.Sprintf("Flags: %s", r.ListFlags()))
 	elems = append(elems, fmt.Sprintf("Table: %d", r.Table))
 	return fmt.Sprintf("{%s}", strings.Join(elems, " "))
@@ -80,11 +127,48 @@ func (r *Route) ClearFlag(flag NextHopFlag) {
 	r.Flags &^= int(flag)
 }
 
+// NormalizedWeights returns the share of the traffic sent to each
+// nexthop of MultiPath, in the same order, summing to 1. The weight of a
+// nexthop is Hops+1. It returns nil for a route without MultiPath.
+func (r *Route) NormalizedWeights() []float64 {
+	if len(r.MultiPath) == 0 {
+		return nil
+	}
+	total := 0
+	for _, nh := range r.MultiPath {
+		total += nh.Hops + 1
+	}
+	weights := make([]float64, len(r.MultiPath))
+	for i, nh := range r.MultiPath {
+		weights[i] = float64(nh.Hops+1) / float64(total)
+	}
+	return weights
+}
+
 type flagString struct {
 	f NextHopFlag
 	s string
 }
 
+// Via is a RTA_VIA gateway, whose address family may differ from the one
+// of the route, e.g. an IPv6 nexthop for an MPLS or IPv4 route.
+type Via struct {
+	AddrFamily int
+	Addr       net.IP
+}
+
+func (v *Via) String() string {
+	return fmt.Sprintf("Family: %d, Address: %s", v.AddrFamily, v.Addr)
+}
+
+// RouteGetOptions contains a set of options to use with
+// RouteGetWithOptions
+type RouteGetOptions struct {
+	FibMatch bool    // return the matching fib entry rather than the resolved route
+	Notify   bool    // set FLAG_NOTIFY on the resolved route
+	Uid      *uint32 // look the route up for this uid
+}
+
 // RouteUpdate is sent when a route changes - type is RTM_NEWROUTE or RTM_DELROUTE
 type RouteUpdate struct {
 	Type uint16
@@ -95,6 +179,7 @@ type NexthopInfo struct {
 	LinkIndex int
 	Hops      int
 	Gw        net.IP
+	Via       *Via
 	Flags     int
 	NewDst    Destination
 	Encap     Encap
@@ -110,7 +195,10 @@ func (n *NexthopInfo) String() string {
 		elems = append(elems, fmt.Sprintf("Encap: %s", n.Encap))
 	}
 	elems = append(elems, fmt.Sprintf("Weight: %d", n.Hops+1))
-	elems = append(elems, fmt.Sprintf("Gw: %d", n.Gw))
+	elems = append(elems, fmt.Sprintf("Gw: %s", n.Gw))
+	if n.Via != nil {
+		elems = append(elems, fmt.Sprintf("Via: %s", n.Via))
+	}
 	elems = append(elems, fmt.Sprintf("Flags: %s", n.ListFlags()))
 	return fmt.Sprintf("{%s}", strings.Join(elems, " "))
 }
@@ -1,8 +1,11 @@
 package netlink
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"syscall"
 
@@ -33,14 +36,29 @@ const (
 	RT_FILTER_TABLE
 )
 
+// FLAG_DEAD, FLAG_OFFLOAD and FLAG_LINKDOWN are only set by the kernel. They
+// are reported per nexthop and, for single path routes, in Route.Flags.
 const (
 	FLAG_ONLINK    NextHopFlag = syscall.RTNH_F_ONLINK
 	FLAG_PERVASIVE NextHopFlag = syscall.RTNH_F_PERVASIVE
+	FLAG_DEAD      NextHopFlag = nl.RTNH_F_DEAD
+	FLAG_OFFLOAD   NextHopFlag = nl.RTNH_F_OFFLOAD
+	FLAG_LINKDOWN  NextHopFlag = nl.RTNH_F_LINKDOWN
 )
 
+// FLAG_NOTIFY is a route flag rather than a nexthop one: it asks the
+// kernel to notify about changes of the route. The kernel does not keep
+// it on the FIB entry, it is only reported back on the cached routes
+// returned by RouteGetWithOptions with Notify set.
+const FLAG_NOTIFY NextHopFlag = syscall.RTM_F_NOTIFY
+
 var testFlags = []flagString{
 	{f: FLAG_ONLINK, s: "onlink"},
 	{f: FLAG_PERVASIVE, s: "pervasive"},
+	{f: FLAG_DEAD, s: "dead"},
+	{f: FLAG_OFFLOAD, s: "offload"},
+	{f: FLAG_LINKDOWN, s: "linkdown"},
+	{f: FLAG_NOTIFY, s: "notify"},
 }
 
 func listFlags(flag int) []string {
@@ -57,6 +75,51 @@ func (r *Route) ListFlags() []string {
 	return listFlags(r.Flags)
 }
 
+var routeTypeNames = map[int]string{
+	syscall.RTN_BLACKHOLE:   "blackhole",
+	syscall.RTN_UNREACHABLE: "unreachable",
+	syscall.RTN_PROHIBIT:    "prohibit",
+}
+
+func (r *Route) typeString() string {
+	return routeTypeNames[r.Type]
+}
+
+// NewBlackholeRoute returns a route that silently discards packets to dst.
+// Equivalent to: `ip route add blackhole $dst`
+func NewBlackholeRoute(dst *net.IPNet) *Route {
+	return newTypedRoute(dst, syscall.RTN_BLACKHOLE)
+}
+
+// NewUnreachableRoute returns a route that discards packets to dst and
+// signals the sender with ICMP host unreachable.
+// Equivalent to: `ip route add unreachable $dst`
+func NewUnreachableRoute(dst *net.IPNet) *Route {
+	return newTypedRoute(dst, syscall.RTN_UNREACHABLE)
+}
+
+// NewProhibitRoute returns a route that discards packets to dst and
+// signals the sender with ICMP communication administratively prohibited.
+// Equivalent to: `ip route add prohibit $dst`
+func NewProhibitRoute(dst *net.IPNet) *Route {
+	return newTypedRoute(dst, syscall.RTN_PROHIBIT)
+}
+
+func newTypedRoute(dst *net.IPNet, typ int) *Route {
+	return &Route{
+		Dst:   dst,
+		Scope: SCOPE_UNIVERSE,
+		Type:  typ,
+	}
+}
+
+// isDropRoute reports whether the route type discards packets, in which
+// case the kernel does not accept a gateway or an output interface.
+func isDropRoute(typ int) bool {
+	_, ok := routeTypeNames[typ]
+	return ok
+}
+
 func (n *NexthopInfo) ListFlags() []string {
 	return listFlags(n.Flags)
 }
@@ -86,6 +149,35 @@ func (d *MPLSDestination) String() string {
 	return strings.Join(s, "/")
 }
 
+// Encode serializes the via as a struct rtvia.
+func (v *Via) Encode() ([]byte, error) {
+	var addr []byte
+	switch v.AddrFamily {
+	case FAMILY_V4:
+		addr = v.Addr.To4()
+	case FAMILY_V6:
+		addr = v.Addr.To16()
+	default:
+		return nil, fmt.Errorf("unsupported via address family %d", v.AddrFamily)
+	}
+	if addr == nil {
+		return nil, fmt.Errorf("via address %s is not of family %d", v.Addr, v.AddrFamily)
+	}
+	buf := make([]byte, 2, 2+len(addr))
+	native.PutUint16(buf, uint16(v.AddrFamily))
+	return append(buf, addr...), nil
+}
+
+// Decode parses a struct rtvia.
+func (v *Via) Decode(buf []byte) error {
+	if len(buf) < 2 {
+		return fmt.Errorf("Lack of bytes")
+	}
+	v.AddrFamily = int(native.Uint16(buf[0:2]))
+	v.Addr = net.IP(buf[2:])
+	return nil
+}
+
 type MPLSEncap struct {
 	Labels []int
 }
@@ -129,6 +221,99 @@ func (e *MPLSEncap) String() string {
 	return strings.Join(s, "/")
 }
 
+// ILACsumMode is how the checksum is kept valid when the locator of
+// the address is rewritten.
+type ILACsumMode uint8
+
+const (
+	ILA_CSUM_ADJUST_TRANSPORT ILACsumMode = iota
+	ILA_CSUM_NEUTRAL_MAP
+	ILA_CSUM_NO_ACTION
+	ILA_CSUM_NEUTRAL_MAP_AUTO
+)
+
+// ILAIdentType is the type of the identifier in the low 64 bits of the
+// address.
+type ILAIdentType uint8
+
+const (
+	ILA_ATYPE_IID ILAIdentType = iota
+	ILA_ATYPE_LUID
+	ILA_ATYPE_VIRT_V4
+	ILA_ATYPE_VIRT_UNI_V6
+	ILA_ATYPE_VIRT_MULTI_V6
+	ILA_ATYPE_NONLOCAL_ADDR
+	ILA_ATYPE_USE_FORMAT ILAIdentType = 32
+)
+
+// ILAHookType is where the translation happens.
+type ILAHookType uint8
+
+const (
+	ILA_HOOK_ROUTE_OUTPUT ILAHookType = iota
+	ILA_HOOK_ROUTE_INPUT
+)
+
+// ILAEncap replaces the high 64 bits of the destination of IPv6 packets
+// with Locator, e.g. 0x2001000000000001 for 2001:0:0:1. The kernel only
+// accepts it on routes with a prefix of at least 67 bits to LUID
+// identifiers, IdentType must be ILA_ATYPE_LUID or ILA_ATYPE_USE_FORMAT.
+type ILAEncap struct {
+	Locator   uint64
+	CsumMode  ILACsumMode
+	IdentType ILAIdentType
+	HookType  ILAHookType
+}
+
+func (e *ILAEncap) Type() int {
+	return nl.LWTUNNEL_ENCAP_ILA
+}
+
+func (e *ILAEncap) Decode(buf []byte) error {
+	attrs, err := nl.ParseRouteAttr(buf)
+	if err != nil {
+		return err
+	}
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case nl.ILA_ATTR_LOCATOR:
+			if len(attr.Value) < 8 {
+				return fmt.Errorf("Lack of bytes")
+			}
+			e.Locator = binary.BigEndian.Uint64(attr.Value)
+		case nl.ILA_ATTR_CSUM_MODE:
+			e.CsumMode = ILACsumMode(attr.Value[0])
+		case nl.ILA_ATTR_IDENT_TYPE:
+			e.IdentType = ILAIdentType(attr.Value[0])
+		case nl.ILA_ATTR_HOOK_TYPE:
+			e.HookType = ILAHookType(attr.Value[0])
+		}
+	}
+	return nil
+}
+
+func (e *ILAEncap) Encode() ([]byte, error) {
+	locator := make([]byte, 8)
+	binary.BigEndian.PutUint64(locator, e.Locator)
+	attrs := []*nl.RtAttr{
+		nl.NewRtAttr(nl.ILA_ATTR_LOCATOR, locator),
+		nl.NewRtAttr(nl.ILA_ATTR_CSUM_MODE, nl.Uint8Attr(uint8(e.CsumMode))),
+		nl.NewRtAttr(nl.ILA_ATTR_IDENT_TYPE, nl.Uint8Attr(uint8(e.IdentType))),
+		nl.NewRtAttr(nl.ILA_ATTR_HOOK_TYPE, nl.Uint8Attr(uint8(e.HookType))),
+	}
+	var buf []byte
+	for _, attr := range attrs {
+		buf = append(buf, attr.Serialize()...)
+	}
+	return buf, nil
+}
+
+func (e *ILAEncap) String() string {
+	return fmt.Sprintf("ila %x:%x:%x:%x csum-mode %d ident-type %d hook-type %d",
+		uint16(e.Locator>>48), uint16(e.Locator>>32), uint16(e.Locator>>16), uint16(e.Locator),
+		e.CsumMode, e.IdentType, e.HookType)
+}
+
 // RouteAdd will add a route to the system.
 // Equivalent to: `ip route add $route`
 func RouteAdd(route *Route) error {
@@ -143,6 +328,44 @@ func (h *Handle) RouteAdd(route *Route) error {
 	return h.routeHandle(route, req, nl.NewRtMsg())
 }
 
+// RouteAddBatch adds the routes to the system, pipelining the requests
+// instead of waiting for each one to be acknowledged. The returned slice
+// holds the error of each route, nil when it was added. The second return
+// value is set when the batch itself failed, e.g. on a socket timeout, in
+// which case the routes without an acknowledgement may or may not have
+// been added.
+func RouteAddBatch(routes []*Route) ([]error, error) {
+	return pkgHandle.RouteAddBatch(routes)
+}
+
+// RouteAddBatch adds the routes to the system, pipelining the requests
+// instead of waiting for each one to be acknowledged. The returned slice
+// holds the error of each route, nil when it was added. The second return
+// value is set when the batch itself failed, e.g. on a socket timeout, in
+// which case the routes without an acknowledgement may or may not have
+// been added.
+func (h *Handle) RouteAddBatch(routes []*Route) ([]error, error) {
+	errs := make([]error, len(routes))
+	reqs := make([]*nl.NetlinkRequest, 0, len(routes))
+	// index in routes of each request
+	idx := make([]int, 0, len(routes))
+	flags := syscall.NLM_F_CREATE | syscall.NLM_F_EXCL | syscall.NLM_F_ACK
+	for i, route := range routes {
+		req := h.newNetlinkRequest(syscall.RTM_NEWROUTE, flags)
+		if err := routeRequest(route, req, nl.NewRtMsg()); err != nil {
+			errs[i] = err
+			continue
+		}
+		reqs = append(reqs, req)
+		idx = append(idx, i)
+	}
+	reqErrs, err := nl.ExecuteBatch(syscall.NETLINK_ROUTE, reqs)
+	for i, reqErr := range reqErrs {
+		errs[idx[i]] = reqErr
+	}
+	return errs, err
+}
+
 // RouteReplace will add a route to the system.
 // Equivalent to: `ip route replace $route`
 func RouteReplace(route *Route) error {
@@ -170,7 +393,233 @@ func (h *Handle) RouteDel(route *Route) error {
 	return h.routeHandle(route, req, nl.NewRtDelMsg())
 }
 
+// ip6RtPrioUser is the metric the kernel gives IPv6 routes added without one.
+const ip6RtPrioUser = 1024
+
+// RouteSetPriority changes the metric of an existing route. The metric is
+// part of the route key, so the route is added again with the new priority
+// before the old one is deleted, and route.Priority is updated on success.
+// The route must be as complete as when it was added.
+func RouteSetPriority(route *Route, priority int) error {
+	return pkgHandle.RouteSetPriority(route, priority)
+}
+
+// RouteSetPriority changes the metric of an existing route. The metric is
+// part of the route key, so the route is added again with the new priority
+// before the old one is deleted, and route.Priority is updated on success.
+// The route must be as complete as when it was added.
+func (h *Handle) RouteSetPriority(route *Route, priority int) error {
+	old := *route
+	if old.Priority == 0 && routeFamily(route) == FAMILY_V6 {
+		// without a metric the IPv6 delete would match either route
+		old.Priority = ip6RtPrioUser
+	}
+	if old.Priority == priority {
+		return nil
+	}
+	updated := *route
+	updated.Priority = priority
+	if err := h.RouteAdd(&updated); err != nil {
+		return err
+	}
+	if err := h.RouteDel(&old); err != nil {
+		h.RouteDel(&updated)
+		return err
+	}
+	route.Priority = priority
+	return nil
+}
+
+func routeFamily(route *Route) int {
+	switch {
+	case route.Dst != nil && route.Dst.IP != nil:
+		return nl.GetIPFamily(route.Dst.IP)
+	case route.SrcNet != nil && route.SrcNet.IP != nil:
+		return nl.GetIPFamily(route.SrcNet.IP)
+	case route.Src != nil:
+		return nl.GetIPFamily(route.Src)
+	case route.Gw != nil:
+		return nl.GetIPFamily(route.Gw)
+	case route.MPLSDst != nil:
+		return nl.FAMILY_MPLS
+	}
+	return FAMILY_ALL
+}
+
+// RouteDelByOif deletes every route, in all tables and families, whose
+// output interface or any of whose multipath nexthops is the link with
+// index oif, including routes through nexthop objects or groups of them
+// that use the link. The deletions are sent in a single batch. It returns
+// the number of deleted routes and the joined errors of the deletions
+// that failed.
+func RouteDelByOif(oif int) (int, error) {
+	return pkgHandle.RouteDelByOif(oif)
+}
+
+// RouteDelByOif deletes every route, in all tables and families, whose
+// output interface or any of whose multipath nexthops is the link with
+// index oif, including routes through nexthop objects or groups of them
+// that use the link. The deletions are sent in a single batch. It returns
+// the number of deleted routes and the joined errors of the deletions
+// that failed.
+func (h *Handle) RouteDelByOif(oif int) (int, error) {
+	routes, err := h.RouteListFiltered(FAMILY_ALL, &Route{Table: syscall.RT_TABLE_UNSPEC}, RT_FILTER_TABLE)
+	if err != nil {
+		return 0, err
+	}
+	var nexthops map[uint32]routeNexthop
+	for i := range routes {
+		if routes[i].NHID != 0 {
+			if nexthops, err = h.routeNexthopList(); err != nil {
+				return 0, err
+			}
+			break
+		}
+	}
+
+	var (
+		errs []error
+		dels []*Route
+		reqs []*nl.NetlinkRequest
+	)
+	for i := range routes {
+		route := &routes[i]
+		if route.NHID != 0 {
+			if !nexthopUsesOif(nexthops, route.NHID, oif) {
+				continue
+			}
+			route = nexthopRouteDel(nexthops, route)
+		} else if !routeUsesOif(route, oif) {
+			continue
+		}
+		req := h.newNetlinkRequest(syscall.RTM_DELROUTE, syscall.NLM_F_ACK)
+		if err := routeRequest(route, req, nl.NewRtDelMsg()); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", route, err))
+			continue
+		}
+		dels = append(dels, route)
+		reqs = append(reqs, req)
+	}
+
+	reqErrs, err := nl.ExecuteBatch(syscall.NETLINK_ROUTE, reqs)
+	deleted := 0
+	for i, reqErr := range reqErrs {
+		switch {
+		case reqErr == nil:
+			deleted++
+		case errors.Is(reqErr, syscall.ESRCH):
+			// already removed along with a previously deleted route
+		default:
+			errs = append(errs, fmt.Errorf("%s: %w", dels[i], reqErr))
+		}
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return deleted, errors.Join(errs...)
+}
+
+func routeUsesOif(route *Route, oif int) bool {
+	if route.LinkIndex == oif {
+		return true
+	}
+	for _, nh := range route.MultiPath {
+		if nh.LinkIndex == oif {
+			return true
+		}
+	}
+	return false
+}
+
+// routeNexthop is the part of a nexthop object RouteDelByOif needs.
+type routeNexthop struct {
+	family int
+	oif    int
+	group  []uint32
+}
+
+func (h *Handle) routeNexthopList() (map[uint32]routeNexthop, error) {
+	req := h.newNetlinkRequest(nl.RTM_GETNEXTHOP, syscall.NLM_F_DUMP)
+	req.AddData(&nl.Nhmsg{Family: syscall.AF_UNSPEC})
+	msgs, err := req.Execute(syscall.NETLINK_ROUTE, nl.RTM_NEWNEXTHOP)
+	if err != nil {
+		return nil, err
+	}
+	nexthops := make(map[uint32]routeNexthop, len(msgs))
+	for _, m := range msgs {
+		msg := nl.DeserializeNhmsg(m)
+		attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+		if err != nil {
+			return nil, err
+		}
+		nh := routeNexthop{family: int(msg.Family)}
+		var id uint32
+		for _, attr := range attrs {
+			switch attr.Attr.Type {
+			case nl.NHA_ID:
+				id = native.Uint32(attr.Value[0:4])
+			case nl.NHA_OIF:
+				nh.oif = int(native.Uint32(attr.Value[0:4]))
+			case nl.NHA_GROUP:
+				for b := attr.Value; len(b) >= nl.SizeofNexthopGrp; b = b[nl.SizeofNexthopGrp:] {
+					nh.group = append(nh.group, nl.DeserializeNexthopGrp(b).Id)
+				}
+			}
+		}
+		nexthops[id] = nh
+	}
+	return nexthops, nil
+}
+
+func nexthopUsesOif(nexthops map[uint32]routeNexthop, id uint32, oif int) bool {
+	nh := nexthops[id]
+	if nh.oif == oif {
+		return true
+	}
+	for _, member := range nh.group {
+		if nexthops[member].oif == oif {
+			return true
+		}
+	}
+	return false
+}
+
+// nexthopRouteDel returns the route to delete a route through a nexthop
+// object. The kernel also reports the nexthops of the object on the
+// route, it refuses to delete it if they are part of the request.
+func nexthopRouteDel(nexthops map[uint32]routeNexthop, route *Route) *Route {
+	del := &Route{
+		Dst:      route.Dst,
+		SrcNet:   route.SrcNet,
+		Table:    route.Table,
+		Priority: route.Priority,
+		Tos:      route.Tos,
+		NHID:     route.NHID,
+	}
+	if del.Dst == nil {
+		family := nexthops[route.NHID].family
+		if nh := nexthops[route.NHID]; family == syscall.AF_UNSPEC && len(nh.group) > 0 {
+			family = nexthops[nh.group[0]].family
+		}
+		if family == FAMILY_V6 {
+			del.Dst = &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}
+		} else {
+			del.Dst = &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}
+		}
+	}
+	return del
+}
+
 func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg) error {
+	if err := routeRequest(route, req, msg); err != nil {
+		return err
+	}
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+// routeRequest fills req with msg and the attributes describing route.
+func routeRequest(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg) error {
 	if (route.Dst == nil || route.Dst.IP == nil) && route.Src == nil && route.Gw == nil && route.MPLSDst == nil {
 		return fmt.Errorf("one of Dst.IP, Src, or Gw must not be nil")
 	}
@@ -219,6 +668,23 @@ func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg
 		rtAttrs = append(rtAttrs, nl.NewRtAttr(nl.RTA_ENCAP, buf))
 	}
 
+	if route.SrcNet != nil && route.SrcNet.IP != nil {
+		srcLen, _ := route.SrcNet.Mask.Size()
+		msg.Src_len = uint8(srcLen)
+		srcFamily := nl.GetIPFamily(route.SrcNet.IP)
+		if family != -1 && family != srcFamily {
+			return fmt.Errorf("source prefix and destination ip are not the same IP family")
+		}
+		family = srcFamily
+		var srcData []byte
+		if srcFamily == FAMILY_V4 {
+			srcData = route.SrcNet.IP.To4()
+		} else {
+			srcData = route.SrcNet.IP.To16()
+		}
+		rtAttrs = append(rtAttrs, nl.NewRtAttr(syscall.RTA_SRC, srcData))
+	}
+
 	if route.Src != nil {
 		srcFamily := nl.GetIPFamily(route.Src)
 		if family != -1 && family != srcFamily {
@@ -235,7 +701,7 @@ func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg
 		rtAttrs = append(rtAttrs, nl.NewRtAttr(syscall.RTA_PREFSRC, srcData))
 	}
 
-	if route.Gw != nil {
+	if route.Gw != nil && !isDropRoute(route.Type) {
 		gwFamily := nl.GetIPFamily(route.Gw)
 		if family != -1 && family != gwFamily {
 			return fmt.Errorf("gateway, source, and destination ip are not the same IP family")
@@ -250,6 +716,14 @@ func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg
 		rtAttrs = append(rtAttrs, nl.NewRtAttr(syscall.RTA_GATEWAY, gwData))
 	}
 
+	if route.Via != nil {
+		buf, err := route.Via.Encode()
+		if err != nil {
+			return err
+		}
+		rtAttrs = append(rtAttrs, nl.NewRtAttr(nl.RTA_VIA, buf))
+	}
+
 	if len(route.MultiPath) > 0 {
 		buf := []byte{}
 		for _, nh := range route.MultiPath {
@@ -272,6 +746,13 @@ func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg
 					children = append(children, nl.NewRtAttr(syscall.RTA_GATEWAY, []byte(nh.Gw.To16())))
 				}
 			}
+			if nh.Via != nil {
+				buf, err := nh.Via.Encode()
+				if err != nil {
+					return err
+				}
+				children = append(children, nl.NewRtAttr(nl.RTA_VIA, buf))
+			}
 			if nh.NewDst != nil {
 				if family != -1 && family != nh.NewDst.Family() {
 					return fmt.Errorf("new destination and destination are not the same address family")
@@ -285,7 +766,7 @@ func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg
 			if nh.Encap != nil {
 				buf := make([]byte, 2)
 				native.PutUint16(buf, uint16(nh.Encap.Type()))
-				rtAttrs = append(rtAttrs, nl.NewRtAttr(nl.RTA_ENCAP_TYPE, buf))
+				children = append(children, nl.NewRtAttr(nl.RTA_ENCAP_TYPE, buf))
 				buf, err := nh.Encap.Encode()
 				if err != nil {
 					return err
@@ -298,6 +779,30 @@ func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg
 		rtAttrs = append(rtAttrs, nl.NewRtAttr(syscall.RTA_MULTIPATH, buf))
 	}
 
+	if route.TTLPropagate != nil && family == nl.FAMILY_MPLS {
+		rtAttrs = append(rtAttrs, nl.NewRtAttr(nl.RTA_TTL_PROPAGATE, nl.Uint8Attr(uint8(*route.TTLPropagate))))
+	}
+
+	if route.NHID > 0 {
+		rtAttrs = append(rtAttrs, nl.NewRtAttr(nl.RTA_NH_ID, nl.Uint32Attr(route.NHID)))
+	}
+	if route.Realm > 0 {
+		rtAttrs = append(rtAttrs, nl.NewRtAttr(syscall.RTA_FLOW, nl.Uint32Attr(uint32(route.Realm))))
+	}
+
+	if len(route.Metrics) > 0 {
+		types := make([]int, 0, len(route.Metrics))
+		for typ := range route.Metrics {
+			types = append(types, typ)
+		}
+		sort.Ints(types)
+		metrics := nl.NewRtAttr(syscall.RTA_METRICS, nil)
+		for _, typ := range types {
+			nl.NewRtAttrChild(metrics, typ, nl.Uint32Attr(route.Metrics[typ]))
+		}
+		rtAttrs = append(rtAttrs, metrics)
+	}
+
 	if route.Table > 0 {
 		if route.Table >= 256 {
 			msg.Table = syscall.RT_TABLE_UNSPEC
@@ -332,16 +837,16 @@ func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg
 		req.AddData(attr)
 	}
 
-	var (
-		b      = make([]byte, 4)
-		native = nl.NativeEndian()
-	)
-	native.PutUint32(b, uint32(route.LinkIndex))
-
-	req.AddData(nl.NewRtAttr(syscall.RTA_OIF, b))
+	if !isDropRoute(route.Type) {
+		var (
+			b      = make([]byte, 4)
+			native = nl.NativeEndian()
+		)
+		native.PutUint32(b, uint32(route.LinkIndex))
 
-	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
-	return err
+		req.AddData(nl.NewRtAttr(syscall.RTA_OIF, b))
+	}
+	return nil
 }
 
 // RouteList gets a list of routes in the system.
@@ -373,74 +878,192 @@ func RouteListFiltered(family int, filter *Route, filterMask uint64) ([]Route, e
 // RouteListFiltered gets a list of routes in the system filtered with specified rules.
 // All rules must be defined in RouteFilter struct
 func (h *Handle) RouteListFiltered(family int, filter *Route, filterMask uint64) ([]Route, error) {
-	req := h.newNetlinkRequest(syscall.RTM_GETROUTE, syscall.NLM_F_DUMP)
-	infmsg := nl.NewIfInfomsg(family)
-	req.AddData(infmsg)
-
+	req := h.routeDumpRequest(family, filter, filterMask)
 	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWROUTE)
 	if err != nil {
+		if errors.Is(err, syscall.ENOENT) && h.strictCheck {
+			// the kernel reports a missing table when filtering by it
+			return nil, nil
+		}
 		return nil, err
 	}
 
 	var res []Route
 	for _, m := range msgs {
-		msg := nl.DeserializeRtMsg(m)
-		if msg.Flags&syscall.RTM_F_CLONED != 0 {
-			// Ignore cloned routes
-			continue
+		route, ok, err := filterDumpedRoute(m, filter, filterMask)
+		if err != nil {
+			return nil, err
 		}
-		if msg.Table != syscall.RT_TABLE_MAIN {
-			if filter == nil || filter != nil && filterMask&RT_FILTER_TABLE == 0 {
-				// Ignore non-main tables
-				continue
-			}
+		if !ok {
+			continue
 		}
-		route, err := deserializeRoute(m)
+		res = append(res, route)
+	}
+	return res, nil
+}
+
+// RouteListChunked dumps the routes of the given table and calls fn with
+// batches of at most chunkSize routes as they are received, so the whole
+// table is never held in memory. The slice passed to fn is reused for the
+// next batch. Returning an error from fn stops the dump and RouteListChunked
+// returns that error.
+func RouteListChunked(table int, chunkSize int, fn func([]Route) error) error {
+	return pkgHandle.RouteListChunked(table, chunkSize, fn)
+}
+
+// RouteListChunked dumps the routes of the given table and calls fn with
+// batches of at most chunkSize routes as they are received, so the whole
+// table is never held in memory. The slice passed to fn is reused for the
+// next batch. Returning an error from fn stops the dump and RouteListChunked
+// returns that error.
+func (h *Handle) RouteListChunked(table int, chunkSize int, fn func([]Route) error) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("invalid chunk size %d", chunkSize)
+	}
+	filter := &Route{Table: table}
+	req := h.routeDumpRequest(FAMILY_ALL, filter, RT_FILTER_TABLE)
+
+	chunk := make([]Route, 0, chunkSize)
+	var cbErr error
+	err := req.ExecuteIter(syscall.NETLINK_ROUTE, syscall.RTM_NEWROUTE, func(m []byte) bool {
+		route, ok, err := filterDumpedRoute(m, filter, RT_FILTER_TABLE)
 		if err != nil {
-			return nil, err
+			cbErr = err
+			return false
 		}
-		if filter != nil {
-			switch {
-			case filterMask&RT_FILTER_TABLE != 0 && filter.Table != syscall.RT_TABLE_UNSPEC && route.Table != filter.Table:
-				continue
-			case filterMask&RT_FILTER_PROTOCOL != 0 && route.Protocol != filter.Protocol:
-				continue
-			case filterMask&RT_FILTER_SCOPE != 0 && route.Scope != filter.Scope:
-				continue
-			case filterMask&RT_FILTER_TYPE != 0 && route.Type != filter.Type:
-				continue
-			case filterMask&RT_FILTER_TOS != 0 && route.Tos != filter.Tos:
-				continue
-			case filterMask&RT_FILTER_OIF != 0 && route.LinkIndex != filter.LinkIndex:
-				continue
-			case filterMask&RT_FILTER_IIF != 0 && route.ILinkIndex != filter.ILinkIndex:
-				continue
-			case filterMask&RT_FILTER_GW != 0 && !route.Gw.Equal(filter.Gw):
-				continue
-			case filterMask&RT_FILTER_SRC != 0 && !route.Src.Equal(filter.Src):
-				continue
-			case filterMask&RT_FILTER_DST != 0:
-				if filter.MPLSDst == nil || route.MPLSDst == nil || (*filter.MPLSDst) != (*route.MPLSDst) {
-					if filter.Dst == nil {
-						if route.Dst != nil {
-							continue
-						}
-					} else {
-						if route.Dst == nil {
-							continue
-						}
-						aMaskLen, aMaskBits := route.Dst.Mask.Size()
-						bMaskLen, bMaskBits := filter.Dst.Mask.Size()
-						if !(route.Dst.IP.Equal(filter.Dst.IP) && aMaskLen == bMaskLen && aMaskBits == bMaskBits) {
-							continue
-						}
-					}
+		if !ok {
+			return true
+		}
+		chunk = append(chunk, route)
+		if len(chunk) < chunkSize {
+			return true
+		}
+		cbErr = fn(chunk)
+		chunk = chunk[:0]
+		return cbErr == nil
+	})
+	if cbErr != nil {
+		return cbErr
+	}
+	if err != nil {
+		if errors.Is(err, syscall.ENOENT) && h.strictCheck {
+			// the kernel reports a missing table when filtering by it
+			return nil
+		}
+		return err
+	}
+	if len(chunk) > 0 {
+		return fn(chunk)
+	}
+	return nil
+}
+
+// filterDumpedRoute parses a route from an RTM_NEWROUTE dump message and
+// reports whether it passes the filter used by RouteListFiltered.
+func filterDumpedRoute(m []byte, filter *Route, filterMask uint64) (Route, bool, error) {
+	msg := nl.DeserializeRtMsg(m)
+	if msg.Flags&syscall.RTM_F_CLONED != 0 {
+		// Ignore cloned routes
+		return Route{}, false, nil
+	}
+	if msg.Table != syscall.RT_TABLE_MAIN {
+		if filter == nil || filter != nil && filterMask&RT_FILTER_TABLE == 0 {
+			// Ignore non-main tables
+			return Route{}, false, nil
+		}
+	}
+	route, err := deserializeRoute(m)
+	if err != nil {
+		return Route{}, false, err
+	}
+	if filter != nil && !routeMatches(route, filter, filterMask) {
+		return Route{}, false, nil
+	}
+	return route, true, nil
+}
+
+// routeMatches returns whether route matches the fields of filter selected
+// by filterMask.
+func routeMatches(route Route, filter *Route, filterMask uint64) bool {
+	switch {
+	case filterMask&RT_FILTER_TABLE != 0 && filter.Table != syscall.RT_TABLE_UNSPEC && route.Table != filter.Table:
+		return false
+	case filterMask&RT_FILTER_PROTOCOL != 0 && route.Protocol != filter.Protocol:
+		return false
+	case filterMask&RT_FILTER_SCOPE != 0 && route.Scope != filter.Scope:
+		return false
+	case filterMask&RT_FILTER_TYPE != 0 && route.Type != filter.Type:
+		return false
+	case filterMask&RT_FILTER_TOS != 0 && route.Tos != filter.Tos:
+		return false
+	case filterMask&RT_FILTER_OIF != 0 && route.LinkIndex != filter.LinkIndex:
+		return false
+	case filterMask&RT_FILTER_IIF != 0 && route.ILinkIndex != filter.ILinkIndex:
+		return false
+	case filterMask&RT_FILTER_GW != 0 && !route.Gw.Equal(filter.Gw):
+		return false
+	case filterMask&RT_FILTER_SRC != 0 && !route.Src.Equal(filter.Src):
+		return false
+	case filterMask&RT_FILTER_DST != 0:
+		if filter.MPLSDst == nil || route.MPLSDst == nil || (*filter.MPLSDst) != (*route.MPLSDst) {
+			if filter.Dst == nil {
+				if route.Dst != nil {
+					return false
+				}
+			} else {
+				if route.Dst == nil {
+					return false
+				}
+				aMaskLen, aMaskBits := route.Dst.Mask.Size()
+				bMaskLen, bMaskBits := filter.Dst.Mask.Size()
+				if !(route.Dst.IP.Equal(filter.Dst.IP) && aMaskLen == bMaskLen && aMaskBits == bMaskBits) {
+					return false
 				}
 			}
 		}
-		res = append(res, route)
 	}
-	return res, nil
+	return true
+}
+
+// routeDumpRequest builds the RTM_GETROUTE dump request for RouteListFiltered.
+// When strict checking is enabled on the handle, the filter is carried in
+// the request so the kernel only dumps matching routes. The kernel only
+// accepts RTA_TABLE and RTA_OIF in a dump request, the protocol and type
+// go in the rtmsg header. A zero protocol in the header does not filter,
+// so RTPROT_UNSPEC routes are still selected by filterDumpedRoute.
+func (h *Handle) routeDumpRequest(family int, filter *Route, filterMask uint64) *nl.NetlinkRequest {
+	req := h.newNetlinkRequest(syscall.RTM_GETROUTE, syscall.NLM_F_DUMP)
+	if !h.strictCheck {
+		infmsg := nl.NewIfInfomsg(family)
+		req.AddData(infmsg)
+		return req
+	}
+
+	// strict checking requires a clean rtmsg header
+	msg := &nl.RtMsg{}
+	msg.Family = uint8(family)
+	var table int
+	if filter == nil || filterMask&RT_FILTER_TABLE == 0 {
+		table = syscall.RT_TABLE_MAIN
+	} else if filter.Table != syscall.RT_TABLE_UNSPEC {
+		table = filter.Table
+	}
+	if filter != nil {
+		if filterMask&RT_FILTER_PROTOCOL != 0 {
+			msg.Protocol = uint8(filter.Protocol)
+		}
+		if filterMask&RT_FILTER_TYPE != 0 {
+			msg.Type = uint8(filter.Type)
+		}
+	}
+	req.AddData(msg)
+	if table != syscall.RT_TABLE_UNSPEC {
+		req.AddData(nl.NewRtAttr(syscall.RTA_TABLE, nl.Uint32Attr(uint32(table))))
+	}
+	if filter != nil && filterMask&RT_FILTER_OIF != 0 && filter.LinkIndex > 0 {
+		req.AddData(nl.NewRtAttr(syscall.RTA_OIF, nl.Uint32Attr(uint32(filter.LinkIndex))))
+	}
+	return req
 }
 
 // deserializeRoute decodes a binary netlink message into a Route struct
@@ -465,8 +1088,19 @@ func deserializeRoute(m []byte) (Route, error) {
 		switch attr.Attr.Type {
 		case syscall.RTA_GATEWAY:
 			route.Gw = net.IP(attr.Value)
+		case nl.RTA_VIA:
+			via := &Via{}
+			if err := via.Decode(attr.Value); err != nil {
+				return route, err
+			}
+			route.Via = via
 		case syscall.RTA_PREFSRC:
 			route.Src = net.IP(attr.Value)
+		case syscall.RTA_SRC:
+			route.SrcNet = &net.IPNet{
+				IP:   attr.Value,
+				Mask: net.CIDRMask(int(msg.Src_len), 8*len(attr.Value)),
+			}
 		case syscall.RTA_DST:
 			if msg.Family == nl.FAMILY_MPLS {
 				stack := nl.DecodeMPLSStack(attr.Value)
@@ -487,6 +1121,7 @@ func deserializeRoute(m []byte) (Route, error) {
 		case syscall.RTA_PRIORITY:
 			route.Priority = int(native.Uint32(attr.Value[0:4]))
 		case syscall.RTA_TABLE:
+			// tables above 255 only fit here, rtm_table is then RT_TABLE_COMPAT
 			route.Table = int(native.Uint32(attr.Value[0:4]))
 		case syscall.RTA_MULTIPATH:
 			parseRtNexthop := func(value []byte) (*NexthopInfo, []byte, error) {
@@ -511,11 +1146,19 @@ func deserializeRoute(m []byte) (Route, error) {
 					switch attr.Attr.Type {
 					case syscall.RTA_GATEWAY:
 						info.Gw = net.IP(attr.Value)
+					case nl.RTA_VIA:
+						via := &Via{}
+						if err := via.Decode(attr.Value); err != nil {
+							return nil, nil, err
+						}
+						info.Via = via
 					case nl.RTA_NEWDST:
 						var d Destination
 						switch msg.Family {
 						case nl.FAMILY_MPLS:
 							d = &MPLSDestination{}
+						default:
+							continue
 						}
 						if err := d.Decode(attr.Value); err != nil {
 							return nil, nil, err
@@ -537,6 +1180,11 @@ func deserializeRoute(m []byte) (Route, error) {
 						if err := e.Decode(encap.Value); err != nil {
 							return nil, nil, err
 						}
+					case nl.LWTUNNEL_ENCAP_ILA:
+						e = &ILAEncap{}
+						if err := e.Decode(encap.Value); err != nil {
+							return nil, nil, err
+						}
 					}
 					info.Encap = e
 				}
@@ -557,6 +1205,8 @@ func deserializeRoute(m []byte) (Route, error) {
 			switch msg.Family {
 			case nl.FAMILY_MPLS:
 				d = &MPLSDestination{}
+			default:
+				continue
 			}
 			if err := d.Decode(attr.Value); err != nil {
 				return route, err
@@ -566,6 +1216,40 @@ func deserializeRoute(m []byte) (Route, error) {
 			encapType = attr
 		case nl.RTA_ENCAP:
 			encap = attr
+		case syscall.RTA_FLOW:
+			route.Realm = int(native.Uint32(attr.Value[0:4]))
+		case syscall.RTA_METRICS:
+			metrics, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return route, err
+			}
+			for _, metric := range metrics {
+				if metric.Attr.Type == nl.RTAX_CC_ALGO || len(metric.Value) < 4 {
+					// not a numeric metric
+					continue
+				}
+				if route.Metrics == nil {
+					route.Metrics = make(map[int]uint32)
+				}
+				route.Metrics[int(metric.Attr.Type)] = native.Uint32(metric.Value[0:4])
+			}
+		case nl.RTA_MFC_STATS:
+			// struct rta_mfc_stats
+			if len(attr.Value) >= 24 {
+				route.MfcStats = &MfcStats{
+					Packets: native.Uint64(attr.Value[0:8]),
+					Bytes:   native.Uint64(attr.Value[8:16]),
+					WrongIf: native.Uint64(attr.Value[16:24]),
+				}
+			}
+		case nl.RTA_TTL_PROPAGATE:
+			ttlPropagate := int(attr.Value[0])
+			route.TTLPropagate = &ttlPropagate
+		case nl.RTA_UID:
+			uid := native.Uint32(attr.Value[0:4])
+			route.Uid = &uid
+		case nl.RTA_NH_ID:
+			route.NHID = native.Uint32(attr.Value[0:4])
 		}
 	}
 
@@ -578,6 +1262,11 @@ func deserializeRoute(m []byte) (Route, error) {
 			if err := e.Decode(encap.Value); err != nil {
 				return route, err
 			}
+		case nl.LWTUNNEL_ENCAP_ILA:
+			e = &ILAEncap{}
+			if err := e.Decode(encap.Value); err != nil {
+				return route, err
+			}
 		}
 		route.Encap = e
 	}
@@ -586,14 +1275,34 @@ func deserializeRoute(m []byte) (Route, error) {
 }
 
 // RouteGet gets a route to a specific destination from the host system.
+// The Src of the returned route is the source address the kernel would
+// pick for the destination.
 // Equivalent to: 'ip route get'.
 func RouteGet(destination net.IP) ([]Route, error) {
 	return pkgHandle.RouteGet(destination)
 }
 
 // RouteGet gets a route to a specific destination from the host system.
+// The Src of the returned route is the source address the kernel would
+// pick for the destination.
 // Equivalent to: 'ip route get'.
 func (h *Handle) RouteGet(destination net.IP) ([]Route, error) {
+	return h.RouteGetWithOptions(destination, nil)
+}
+
+// RouteGetWithOptions gets a route to a specific destination from the host system.
+// With options.FibMatch the matching fib entry is returned, whose Src is only
+// set if the route was configured with a preferred source.
+// Equivalent to: 'ip route get <> fibmatch' when options.FibMatch is set.
+func RouteGetWithOptions(destination net.IP, options *RouteGetOptions) ([]Route, error) {
+	return pkgHandle.RouteGetWithOptions(destination, options)
+}
+
+// RouteGetWithOptions gets a route to a specific destination from the host system.
+// With options.FibMatch the matching fib entry is returned, whose Src is only
+// set if the route was configured with a preferred source.
+// Equivalent to: 'ip route get <> fibmatch' when options.FibMatch is set.
+func (h *Handle) RouteGetWithOptions(destination net.IP, options *RouteGetOptions) ([]Route, error) {
 	req := h.newNetlinkRequest(syscall.RTM_GETROUTE, syscall.NLM_F_REQUEST)
 	family := nl.GetIPFamily(destination)
 	var destinationData []byte
@@ -608,10 +1317,19 @@ func (h *Handle) RouteGet(destination net.IP) ([]Route, error) {
 	msg := &nl.RtMsg{}
 	msg.Family = uint8(family)
 	msg.Dst_len = bitlen
+	if options != nil && options.FibMatch {
+		msg.Flags |= nl.RTM_F_FIB_MATCH
+	}
+	if options != nil && options.Notify {
+		msg.Flags |= syscall.RTM_F_NOTIFY
+	}
 	req.AddData(msg)
 
 	rtaDst := nl.NewRtAttr(syscall.RTA_DST, destinationData)
 	req.AddData(rtaDst)
+	if options != nil && options.Uid != nil {
+		req.AddData(nl.NewRtAttr(nl.RTA_UID, nl.Uint32Attr(*options.Uid)))
+	}
 
 	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWROUTE)
 	if err != nil {
@@ -630,6 +1348,89 @@ func (h *Handle) RouteGet(destination net.IP) ([]Route, error) {
 
 }
 
+// RouteGetExact reports whether the route with the destination, table and
+// the gateway or output interface of route exists, and returns the kernel's
+// version of it. The fib entry the kernel matches for the destination is
+// checked first. The lookup goes through the rules and picks the longest
+// prefix, so when it does not land on the route the kernel is asked for
+// the routes of the table of the route, and of its output interface if
+// set, on a socket with strict checking enabled. That dump only costs the
+// size of the table, but the handle's socket is used only if it already
+// has strict checking, otherwise a socket is opened for the call. On
+// kernels without strict checking all the routes are dumped instead.
+func RouteGetExact(route *Route) (bool, *Route, error) {
+	return pkgHandle.RouteGetExact(route)
+}
+
+// RouteGetExact reports whether the route with the destination, table and
+// the gateway or output interface of route exists, and returns the kernel's
+// version of it. The fib entry the kernel matches for the destination is
+// checked first. The lookup goes through the rules and picks the longest
+// prefix, so when it does not land on the route the kernel is asked for
+// the routes of the table of the route, and of its output interface if
+// set, on a socket with strict checking enabled. That dump only costs the
+// size of the table, but the handle's socket is used only if it already
+// has strict checking, otherwise a socket is opened for the call. On
+// kernels without strict checking all the routes are dumped instead.
+func (h *Handle) RouteGetExact(route *Route) (bool, *Route, error) {
+	filter := *route
+	if filter.Table == 0 {
+		filter.Table = syscall.RT_TABLE_MAIN
+	}
+	filterMask := RT_FILTER_TABLE | RT_FILTER_DST
+	if filter.Gw != nil {
+		filterMask |= RT_FILTER_GW
+	}
+	if filter.LinkIndex > 0 {
+		filterMask |= RT_FILTER_OIF
+	}
+
+	family := routeFamily(route)
+	var dst net.IP
+	switch {
+	case route.Dst != nil:
+		dst = route.Dst.IP.Mask(route.Dst.Mask)
+	case family == FAMILY_V6:
+		dst = net.IPv6zero
+	default:
+		dst = net.IPv4zero
+	}
+	// a missing route or table fails the lookup
+	routes, err := h.RouteGetWithOptions(dst, &RouteGetOptions{FibMatch: true})
+	if err == nil {
+		for i := range routes {
+			if routeMatches(routes[i], &filter, filterMask) {
+				return true, &routes[i], nil
+			}
+		}
+	}
+
+	// the dump is filtered by the kernel on a strict socket, the handle's
+	// own when it has one and a short lived one otherwise
+	sh := h
+	if !h.strictCheck {
+		ns := h.ns
+		if h.sockets == nil {
+			ns = netns.None()
+		}
+		if sh, err = newHandle(ns, netns.None(), syscall.NETLINK_ROUTE); err != nil {
+			return false, nil, err
+		}
+		defer sh.Close()
+		if err := sh.SetStrictCheck(true); err != nil {
+			return false, nil, err
+		}
+	}
+	routes, err = sh.RouteListFiltered(family, &filter, filterMask)
+	if err != nil {
+		return false, nil, err
+	}
+	if len(routes) == 0 {
+		return false, nil, nil
+	}
+	return true, &routes[0], nil
+}
+
 // RouteSubscribe takes a chan down which notifications will be sent
 // when routes are added or deleted. Close the 'done' chan to stop subscription.
 func RouteSubscribe(ch chan<- RouteUpdate, done <-chan struct{}) error {
@@ -653,6 +1454,23 @@ func routeSubscribeAt(newNs, curNs netns.NsHandle, ch chan<- RouteUpdate, done <
 			s.Close()
 		}()
 	}
+	receiveRouteUpdates(s, ch)
+	return nil
+}
+
+// RouteSubscribe works like the package level RouteSubscribe, but subscribes
+// in the handle's network namespace. The subscription is also stopped
+// when the handle is closed.
+func (h *Handle) RouteSubscribe(ch chan<- RouteUpdate, done <-chan struct{}) error {
+	s, err := h.subscribe(done, syscall.NETLINK_ROUTE, syscall.RTNLGRP_IPV4_ROUTE, syscall.RTNLGRP_IPV6_ROUTE)
+	if err != nil {
+		return err
+	}
+	receiveRouteUpdates(s, ch)
+	return nil
+}
+
+func receiveRouteUpdates(s *nl.NetlinkSocket, ch chan<- RouteUpdate) {
 	go func() {
 		defer close(ch)
 		for {
@@ -669,6 +1487,4 @@ func routeSubscribeAt(newNs, curNs netns.NsHandle, ch chan<- RouteUpdate, done <
 			}
 		}
 	}()
-
-	return nil
 }
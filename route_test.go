@@ -3,11 +3,18 @@
 package netlink
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net"
+	"reflect"
+	"runtime"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/vishvananda/netlink/nl"
 	"github.com/vishvananda/netns"
 )
 
@@ -67,288 +74,1704 @@ func TestRouteAddDel(t *testing.T) {
 
 }
 
-func TestRouteReplace(t *testing.T) {
+func TestRouteGetSrc(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
 
-	// get loopback interface
-	link, err := LinkByName("lo")
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	peer, err := LinkByName("bar")
 	if err != nil {
 		t.Fatal(err)
 	}
+	addrs := []*Addr{
+		{IPNet: &net.IPNet{IP: net.IPv4(192, 168, 77, 1), Mask: net.CIDRMask(24, 32)}},
+		{IPNet: &net.IPNet{IP: net.ParseIP("2001:db8:77::1"), Mask: net.CIDRMask(64, 128)}, Flags: syscall.IFA_F_NODAD},
+	}
+	for _, addr := range addrs {
+		if err := AddrAdd(veth, addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := LinkSetUp(veth); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(peer); err != nil {
+		t.Fatal(err)
+	}
 
-	// bring the interface up
+	dsts := []net.IP{net.IPv4(192, 168, 77, 2), net.ParseIP("2001:db8:77::2")}
+	for i, dst := range dsts {
+		routes, err := RouteGet(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(routes) != 1 {
+			t.Fatalf("Got %d routes to %s, expected 1", len(routes), dst)
+		}
+		if routes[0].LinkIndex != veth.Index {
+			t.Fatalf("Route to %s goes through %d, expected %d", dst, routes[0].LinkIndex, veth.Index)
+		}
+		if !routes[0].Src.Equal(addrs[i].IP) {
+			t.Fatalf("Source to %s is %s, expected %s", dst, routes[0].Src, addrs[i].IP)
+		}
+	}
+}
+
+func TestRouteGetFibMatch(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
 	if err := LinkSetUp(link); err != nil {
 		t.Fatal(err)
 	}
 
-	// add a gateway route
 	dst := &net.IPNet{
 		IP:   net.IPv4(192, 168, 0, 0),
 		Mask: net.CIDRMask(24, 32),
 	}
-
-	ip := net.IPv4(127, 1, 1, 1)
-	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Src: ip}
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Src: net.IPv4(127, 1, 1, 1)}
 	if err := RouteAdd(&route); err != nil {
 		t.Fatal(err)
 	}
-	routes, err := RouteList(link, FAMILY_V4)
+
+	dstIP := net.IPv4(192, 168, 0, 42)
+	routes, err := RouteGet(dstIP)
 	if err != nil {
 		t.Fatal(err)
 	}
 	if len(routes) != 1 {
-		t.Fatal("Route not added properly")
+		t.Fatal("Route not found")
+	}
+	if ones, _ := routes[0].Dst.Mask.Size(); !routes[0].Dst.IP.Equal(dstIP) || ones != 32 {
+		t.Fatalf("Expected the resolved host route, got %s", routes[0].Dst)
 	}
 
-	ip = net.IPv4(127, 1, 1, 2)
-	route = Route{LinkIndex: link.Attrs().Index, Dst: dst, Src: ip}
-	if err := RouteReplace(&route); err != nil {
+	routes, err = RouteGetWithOptions(dstIP, &RouteGetOptions{FibMatch: true})
+	if err != nil {
 		t.Fatal(err)
 	}
+	if len(routes) != 1 {
+		t.Fatal("Fib match not found")
+	}
+	if routes[0].Dst == nil || routes[0].Dst.String() != dst.String() {
+		t.Fatalf("Expected the configured prefix %s, got %s", dst, routes[0].Dst)
+	}
+}
 
-	routes, err = RouteList(link, FAMILY_V4)
+func TestRouteHighTable(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
 	if err != nil {
 		t.Fatal(err)
 	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
 
-	if len(routes) != 1 || !routes[0].Src.Equal(ip) {
-		t.Fatal("Route not replaced properly")
+	dst := &net.IPNet{IP: net.IPv4(192, 0, 2, 0), Mask: net.CIDRMask(24, 32)}
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Table: 100000}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
 	}
 
-	if err := RouteDel(&route); err != nil {
+	routes, err := RouteListFiltered(FAMILY_V4, &Route{Table: route.Table}, RT_FILTER_TABLE)
+	if err != nil {
 		t.Fatal(err)
 	}
-	routes, err = RouteList(link, FAMILY_V4)
+	if len(routes) != 1 || routes[0].Table != route.Table {
+		t.Fatalf("Routes of table %d are %v", route.Table, routes)
+	}
+
+	routes, err = RouteListFiltered(FAMILY_V4, &Route{Table: syscall.RT_TABLE_UNSPEC}, RT_FILTER_TABLE)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(routes) != 0 {
-		t.Fatal("Route not removed properly")
+	found := false
+	for _, r := range routes {
+		if r.Dst != nil && r.Dst.String() == dst.String() {
+			if r.Table != route.Table {
+				t.Fatalf("Route reported in table %d, expected %d", r.Table, route.Table)
+			}
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Route not found in %v", routes)
 	}
 
+	if err := RouteDel(&route); err != nil {
+		t.Fatal(err)
+	}
 }
 
-func TestRouteAddIncomplete(t *testing.T) {
+func TestRouteGetUid(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
 
-	// get loopback interface
 	link, err := LinkByName("lo")
 	if err != nil {
 		t.Fatal(err)
 	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
 
-	// bring the interface up
-	if err = LinkSetUp(link); err != nil {
+	// the route is only reachable for uid 1000 through its rule
+	dst := &net.IPNet{IP: net.IPv4(192, 0, 2, 0), Mask: net.CIDRMask(24, 32)}
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Table: 100}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	rule := NewRule()
+	rule.Priority = 100
+	rule.Table = 100
+	rule.UIDRange = &RuleUIDRange{Start: 1000, End: 1000}
+	if err := RuleAdd(rule); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := RuleList(syscall.AF_INET)
+	if err != nil {
 		t.Fatal(err)
 	}
+	found := false
+	for _, r := range rules {
+		if r.Priority == rule.Priority && r.UIDRange != nil && *r.UIDRange == *rule.UIDRange {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Rule with uid range not found: %v", rules)
+	}
 
-	route := Route{LinkIndex: link.Attrs().Index}
-	if err := RouteAdd(&route); err == nil {
-		t.Fatal("Adding incomplete route should fail")
+	dstIP := net.IPv4(192, 0, 2, 1)
+	uid := uint32(1000)
+	routes, err := RouteGetWithOptions(dstIP, &RouteGetOptions{Uid: &uid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].LinkIndex != link.Attrs().Index {
+		t.Fatalf("Route not resolved for uid %d: %v", uid, routes)
+	}
+	if routes[0].Uid == nil || *routes[0].Uid != uid {
+		t.Fatalf("Route uid is %v, expected %d", routes[0].Uid, uid)
 	}
-}
 
-func expectRouteUpdate(ch <-chan RouteUpdate, t uint16, dst net.IP) bool {
-	for {
-		timeout := time.After(time.Minute)
-		select {
-		case update := <-ch:
-			if update.Type == t && update.Route.Dst.IP.Equal(dst) {
-				return true
-			}
-		case <-timeout:
-			return false
-		}
+	other := uint32(1001)
+	if _, err := RouteGetWithOptions(dstIP, &RouteGetOptions{Uid: &other}); err == nil {
+		t.Fatalf("Route resolved for uid %d", other)
 	}
 }
 
-func TestRouteSubscribe(t *testing.T) {
+func TestRouteGetExact(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
 
-	ch := make(chan RouteUpdate)
-	done := make(chan struct{})
-	defer close(done)
-	if err := RouteSubscribe(ch, done); err != nil {
-		t.Fatal(err)
-	}
-
-	// get loopback interface
 	link, err := LinkByName("lo")
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// bring the interface up
-	if err = LinkSetUp(link); err != nil {
+	if err := LinkSetUp(link); err != nil {
 		t.Fatal(err)
 	}
 
-	// add a gateway route
 	dst := &net.IPNet{
 		IP:   net.IPv4(192, 168, 0, 0),
 		Mask: net.CIDRMask(24, 32),
 	}
-
-	ip := net.IPv4(127, 1, 1, 1)
-	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Src: ip}
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst}
 	if err := RouteAdd(&route); err != nil {
 		t.Fatal(err)
 	}
 
-	if !expectRouteUpdate(ch, syscall.RTM_NEWROUTE, dst.IP) {
-		t.Fatal("Add update not received as expected")
+	found, exact, err := RouteGetExact(&route)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || exact.Dst.String() != dst.String() || exact.Table != syscall.RT_TABLE_MAIN {
+		t.Fatalf("Route not found: %v", exact)
+	}
+
+	// the same prefix in another table and a more specific route hiding
+	// it from the lookup
+	other := Route{LinkIndex: link.Attrs().Index, Dst: dst, Table: 100}
+	if found, _, err := RouteGetExact(&other); err != nil || found {
+		t.Fatalf("Route of table %d found before being added: %v", other.Table, err)
+	}
+	if err := RouteAdd(&other); err != nil {
+		t.Fatal(err)
+	}
+	specific := Route{LinkIndex: link.Attrs().Index, Dst: &net.IPNet{IP: dst.IP, Mask: net.CIDRMask(25, 32)}}
+	if err := RouteAdd(&specific); err != nil {
+		t.Fatal(err)
 	}
+	for _, r := range []Route{route, other} {
+		found, exact, err := RouteGetExact(&r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found || exact.Dst.String() != dst.String() || exact.Table != r.Table && r.Table != 0 {
+			t.Fatalf("Route of table %d not found: %v", r.Table, exact)
+		}
+	}
+
+	gw := route
+	gw.LinkIndex = 0
+	gw.Gw = net.IPv4(127, 0, 0, 2)
+	if found, _, err := RouteGetExact(&gw); err != nil || found {
+		t.Fatalf("Route with a different nexthop found: %v", err)
+	}
+
 	if err := RouteDel(&route); err != nil {
 		t.Fatal(err)
 	}
-	if !expectRouteUpdate(ch, syscall.RTM_DELROUTE, dst.IP) {
-		t.Fatal("Del update not received as expected")
+	if found, _, err := RouteGetExact(&route); err != nil || found {
+		t.Fatalf("Route found after being deleted: %v", err)
 	}
 }
 
-func TestRouteSubscribeAt(t *testing.T) {
-	skipUnlessRoot(t)
+func TestRouteDelByOif(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
 
-	// Create an handle on a custom netns
-	newNs, err := netns.New()
+	if err := LinkAdd(&Dummy{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkAdd(&Dummy{LinkAttrs{Name: "bar"}}); err != nil {
+		t.Fatal(err)
+	}
+	foo, err := LinkByName("foo")
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer newNs.Close()
+	bar, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range []Link{foo, bar} {
+		if err := LinkSetUp(link); err != nil {
+			t.Fatal(err)
+		}
+	}
 
-	nh, err := NewHandleAt(newNs)
+	routes := []Route{
+		{
+			LinkIndex: foo.Attrs().Index,
+			Dst:       &net.IPNet{IP: net.IPv4(192, 168, 0, 0), Mask: net.CIDRMask(24, 32)},
+		},
+		{
+			LinkIndex: foo.Attrs().Index,
+			Dst:       &net.IPNet{IP: net.IPv4(192, 168, 1, 0), Mask: net.CIDRMask(24, 32)},
+			Table:     100,
+		},
+		{
+			Dst: &net.IPNet{IP: net.IPv4(192, 168, 2, 0), Mask: net.CIDRMask(24, 32)},
+			MultiPath: []*NexthopInfo{
+				{LinkIndex: foo.Attrs().Index},
+				{LinkIndex: bar.Attrs().Index},
+			},
+		},
+		{
+			LinkIndex: bar.Attrs().Index,
+			Dst:       &net.IPNet{IP: net.IPv4(192, 168, 3, 0), Mask: net.CIDRMask(24, 32)},
+		},
+	}
+	for i := range routes {
+		if err := RouteAdd(&routes[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := RouteDelByOif(foo.Attrs().Index)
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer nh.Delete()
+	// the kernel may have added IPv6 link-local routes on foo as well
+	if n < 3 {
+		t.Fatalf("Deleted %d routes, expected at least 3", n)
+	}
 
-	// Subscribe for Route events on the custom netns
-	ch := make(chan RouteUpdate)
-	done := make(chan struct{})
-	defer close(done)
-	if err := RouteSubscribeAt(newNs, ch, done); err != nil {
+	left, err := RouteListFiltered(FAMILY_V4, &Route{Table: syscall.RT_TABLE_UNSPEC}, RT_FILTER_TABLE)
+	if err != nil {
 		t.Fatal(err)
 	}
+	found := false
+	for _, route := range left {
+		if routeUsesOif(&route, foo.Attrs().Index) {
+			t.Fatalf("Route %s was not deleted", route)
+		}
+		if route.Dst != nil && route.Dst.String() == routes[3].Dst.String() {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("Route on other link was deleted")
+	}
+}
 
-	// get loopback interface
-	link, err := nh.LinkByName("lo")
+func TestRouteAddBatch(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
 	if err != nil {
 		t.Fatal(err)
 	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
 
-	// bring the interface up
-	if err = nh.LinkSetUp(link); err != nil {
+	idx := link.Attrs().Index
+	routes := []*Route{
+		{LinkIndex: idx, Dst: &net.IPNet{IP: net.IPv4(192, 168, 0, 0), Mask: net.CIDRMask(24, 32)}},
+		// unreachable gateway
+		{LinkIndex: idx, Dst: &net.IPNet{IP: net.IPv4(192, 168, 1, 0), Mask: net.CIDRMask(24, 32)}, Gw: net.IPv4(10, 0, 0, 1)},
+		{LinkIndex: idx, Dst: &net.IPNet{IP: net.IPv4(192, 168, 2, 0), Mask: net.CIDRMask(24, 32)}},
+		// duplicate of the first route
+		{LinkIndex: idx, Dst: &net.IPNet{IP: net.IPv4(192, 168, 0, 0), Mask: net.CIDRMask(24, 32)}},
+		// invalid route, rejected before being sent
+		{LinkIndex: idx},
+	}
+	errs, err := RouteAddBatch(routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != len(routes) {
+		t.Fatalf("Got %d errors, expected %d", len(errs), len(routes))
+	}
+	if errs[0] != nil || errs[2] != nil {
+		t.Fatalf("Valid routes failed: %v", errs)
+	}
+	if errs[1] != syscall.ENETUNREACH {
+		t.Fatalf("Unreachable gateway error is %v, expected %v", errs[1], syscall.ENETUNREACH)
+	}
+	if errs[3] != syscall.EEXIST {
+		t.Fatalf("Duplicate route error is %v, expected %v", errs[3], syscall.EEXIST)
+	}
+	if errs[4] == nil {
+		t.Fatal("Invalid route did not fail")
+	}
+
+	list, err := RouteList(link, FAMILY_V4)
+	if err != nil {
 		t.Fatal(err)
 	}
+	if len(list) != 2 {
+		t.Fatalf("Got %d routes, expected 2", len(list))
+	}
+}
 
-	// add a gateway route
-	dst := &net.IPNet{
-		IP:   net.IPv4(192, 169, 0, 0),
-		Mask: net.CIDRMask(24, 32),
+func benchmarkRoutes(b *testing.B, n int) []*Route {
+	link, err := LinkByName("lo")
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		b.Fatal(err)
+	}
+	routes := make([]*Route, n)
+	for i := range routes {
+		routes[i] = &Route{
+			LinkIndex: link.Attrs().Index,
+			Dst: &net.IPNet{
+				IP:   net.IPv4(10, byte(i>>16), byte(i>>8), byte(i)),
+				Mask: net.CIDRMask(32, 32),
+			},
+		}
+	}
+	return routes
+}
+
+func BenchmarkRouteAdd(b *testing.B) {
+	tearDown := setUpNetlinkTest(b)
+	defer tearDown()
+
+	routes := benchmarkRoutes(b, b.N)
+	b.ResetTimer()
+	for _, route := range routes {
+		if err := RouteAdd(route); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRouteAddBatch(b *testing.B) {
+	tearDown := setUpNetlinkTest(b)
+	defer tearDown()
+
+	routes := benchmarkRoutes(b, b.N)
+	b.ResetTimer()
+	errs, err := RouteAddBatch(routes)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, err := range errs {
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRouteListChunked(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		route := &Route{
+			LinkIndex: link.Attrs().Index,
+			Dst:       &net.IPNet{IP: net.IPv4(10, 0, 0, byte(i)), Mask: net.CIDRMask(32, 32)},
+			Table:     200,
+		}
+		if err := RouteAdd(route); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var sizes []int
+	total := 0
+	err = RouteListChunked(200, 4, func(routes []Route) error {
+		sizes = append(sizes, len(routes))
+		for _, route := range routes {
+			if route.Table != 200 {
+				t.Fatalf("Route from table %d dumped", route.Table)
+			}
+		}
+		total += len(routes)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 10 || len(sizes) != 3 || sizes[0] != 4 || sizes[2] != 2 {
+		t.Fatalf("Routes dumped in chunks %v, expected [4 4 2]", sizes)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = RouteListChunked(200, 4, func(routes []Route) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Fatalf("Dump not stopped by the callback: %v after %d calls", err, calls)
+	}
+
+	// the socket of the handle must still be usable after an aborted dump
+	routes, err := RouteListFiltered(FAMILY_V4, &Route{Table: 200}, RT_FILTER_TABLE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 10 {
+		t.Fatalf("%d routes listed after aborted dump, expected 10", len(routes))
+	}
+}
+
+func benchmarkRouteList(b *testing.B, n int, list func(sample func()) error) {
+	tearDown := setUpNetlinkTest(b)
+	defer tearDown()
+
+	routes := benchmarkRoutes(b, n)
+	for _, route := range routes {
+		route.Table = 200
+	}
+	if _, err := RouteAddBatch(routes); err != nil {
+		b.Fatal(err)
+	}
+	routes = nil
+
+	var peak uint64
+	sample := func() {
+		var stats runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&stats)
+		if stats.HeapAlloc > peak {
+			peak = stats.HeapAlloc
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := list(sample); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(peak), "peak-heap-B")
+}
+
+// BenchmarkRouteListChunked reports a peak heap that does not depend on the
+// table size, unlike BenchmarkRouteListFiltered.
+func BenchmarkRouteListChunked(b *testing.B) {
+	for _, n := range []int{1000, 10000, 50000} {
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			benchmarkRouteList(b, n, func(sample func()) error {
+				return RouteListChunked(200, 256, func(routes []Route) error {
+					sample()
+					return nil
+				})
+			})
+		})
+	}
+}
+
+func BenchmarkRouteListFiltered(b *testing.B) {
+	for _, n := range []int{1000, 10000, 50000} {
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			benchmarkRouteList(b, n, func(sample func()) error {
+				routes, err := RouteListFiltered(FAMILY_V4, &Route{Table: 200}, RT_FILTER_TABLE)
+				sample()
+				runtime.KeepAlive(routes)
+				return err
+			})
+		})
+	}
+}
+
+func TestRouteDefaultPriority(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	peer, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(veth); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(peer); err != nil {
+		t.Fatal(err)
+	}
+
+	defaults := []*Route{
+		{
+			LinkIndex: veth.Index,
+			Dst:       &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
+			Priority:  200,
+		},
+		{
+			LinkIndex: veth.Index,
+			Dst:       &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)},
+			Priority:  200,
+		},
+	}
+	families := []int{FAMILY_V4, FAMILY_V6}
+	listDefault := func(family int) []Route {
+		routes, err := RouteListFiltered(family, &Route{LinkIndex: veth.Index, Dst: nil}, RT_FILTER_OIF|RT_FILTER_DST)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return routes
+	}
+	for i, route := range defaults {
+		if err := RouteAdd(route); err != nil {
+			t.Fatal(err)
+		}
+		routes := listDefault(families[i])
+		if len(routes) != 1 || routes[0].Priority != 200 {
+			t.Fatalf("Default routes of family %d are %v, expected metric 200", families[i], routes)
+		}
+	}
+
+	for i, route := range defaults {
+		if err := RouteSetPriority(route, 300); err != nil {
+			t.Fatal(err)
+		}
+		routes := listDefault(families[i])
+		if len(routes) != 1 || routes[0].Priority != 300 || route.Priority != 300 {
+			t.Fatalf("Default routes of family %d are %v, expected metric 300", families[i], routes)
+		}
+	}
+
+	// IPv6 routes added without a metric get the kernel default
+	route := &Route{
+		LinkIndex: veth.Index,
+		Dst:       &net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(64, 128)},
+	}
+	if err := RouteAdd(route); err != nil {
+		t.Fatal(err)
+	}
+	if err := RouteSetPriority(route, 100); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteListFiltered(FAMILY_V6, &Route{Dst: route.Dst}, RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Priority != 100 {
+		t.Fatalf("Routes to %s are %v, expected a single one with metric 100", route.Dst, routes)
+	}
+}
+
+func TestRouteRealm(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := &net.IPNet{
+		IP:   net.IPv4(192, 168, 0, 0),
+		Mask: net.CIDRMask(24, 32),
+	}
+	// destination realm 2, source realm 1
+	realm := 1<<16 | 2
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Realm: realm}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := RouteList(link, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not added properly")
+	}
+	if routes[0].Realm != realm {
+		t.Fatalf("Route realm is %#x, should be %#x", routes[0].Realm, realm)
+	}
+}
+
+func TestRouteNotifyFlag(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := &net.IPNet{
+		IP:   net.IPv4(192, 168, 0, 0),
+		Mask: net.CIDRMask(24, 32),
+	}
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst}
+	route.SetFlag(FLAG_NOTIFY)
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := RouteGetWithOptions(net.IPv4(192, 168, 0, 1), &RouteGetOptions{Notify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not found")
+	}
+	if routes[0].Flags&int(FLAG_NOTIFY) == 0 {
+		t.Fatalf("Route flags %v should contain notify", routes[0].ListFlags())
+	}
+}
+
+func TestRouteListFilteredStrictCheck(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	lo, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(lo); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkAdd(&Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		dst := &net.IPNet{IP: net.IPv4(192, 168, byte(i), 0), Mask: net.CIDRMask(24, 32)}
+		if err := RouteAdd(&Route{LinkIndex: lo.Attrs().Index, Dst: dst}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dst := &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)}
+	if err := RouteAdd(&Route{LinkIndex: link.Attrs().Index, Dst: dst}); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := NewHandle(syscall.NETLINK_ROUTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Delete()
+
+	filter := &Route{LinkIndex: link.Attrs().Index}
+	dump := func() int {
+		req := h.routeDumpRequest(FAMILY_V4, filter, RT_FILTER_OIF)
+		msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWROUTE)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(msgs)
+	}
+
+	all := dump()
+	if err := h.SetStrictCheck(true); err != nil {
+		t.Fatal(err)
+	}
+	if !h.strictCheck {
+		t.Skip("Kernel does not support NETLINK_GET_STRICT_CHK")
+	}
+	if filtered := dump(); filtered != 1 || filtered >= all {
+		t.Fatalf("Expected 1 route out of %d with strict check, got %d", all, filtered)
+	}
+
+	routes, err := h.RouteListFiltered(FAMILY_V4, filter, RT_FILTER_OIF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].Dst.String() != dst.String() {
+		t.Fatalf("Expected only route to %s, got %v", dst, routes)
+	}
+}
+
+func TestRouteListFilteredProtocol(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	const protoBGP = 186
+	for i, proto := range []int{protoBGP, protoBGP, syscall.RTPROT_STATIC} {
+		dst := &net.IPNet{IP: net.IPv4(192, 168, byte(i), 0), Mask: net.CIDRMask(24, 32)}
+		if err := RouteAdd(&Route{LinkIndex: link.Attrs().Index, Dst: dst, Protocol: proto}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h, err := NewHandle(syscall.NETLINK_ROUTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Delete()
+
+	check := func() {
+		for proto, expected := range map[int]int{protoBGP: 2, syscall.RTPROT_STATIC: 1} {
+			routes, err := h.RouteListFiltered(FAMILY_V4, &Route{Protocol: proto}, RT_FILTER_PROTOCOL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(routes) != expected {
+				t.Fatalf("Expected %d routes of protocol %d, got %v", expected, proto, routes)
+			}
+			for _, route := range routes {
+				if route.Protocol != proto {
+					t.Fatalf("Route %s has protocol %d, expected %d", route, route.Protocol, proto)
+				}
+			}
+		}
+	}
+
+	check()
+	if err := h.SetStrictCheck(true); err != nil {
+		t.Fatal(err)
+	}
+	if !h.strictCheck {
+		t.Skip("Kernel does not support NETLINK_GET_STRICT_CHK")
+	}
+	// the kernel filters the dump by itself
+	req := h.routeDumpRequest(FAMILY_V4, &Route{Protocol: protoBGP}, RT_FILTER_PROTOCOL)
+	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWROUTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("Expected the kernel to dump 2 routes, got %d", len(msgs))
+	}
+	check()
+}
+
+func TestRouteMetrics(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := map[int]uint32{
+		nl.RTAX_QUICKACK:    1,
+		syscall.RTAX_ADVMSS: 1400,
+	}
+	dst := &net.IPNet{
+		IP:   net.IPv4(192, 168, 0, 0),
+		Mask: net.CIDRMask(24, 32),
+	}
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Metrics: metrics}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := RouteList(link, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not added properly")
+	}
+	if !reflect.DeepEqual(routes[0].Metrics, metrics) {
+		t.Fatalf("Route metrics are %v, should be %v", routes[0].Metrics, metrics)
+	}
+}
+
+func TestRouteReplace(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// get loopback interface
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bring the interface up
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	// add a gateway route
+	dst := &net.IPNet{
+		IP:   net.IPv4(192, 168, 0, 0),
+		Mask: net.CIDRMask(24, 32),
+	}
+
+	ip := net.IPv4(127, 1, 1, 1)
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Src: ip}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteList(link, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not added properly")
+	}
+
+	ip = net.IPv4(127, 1, 1, 2)
+	route = Route{LinkIndex: link.Attrs().Index, Dst: dst, Src: ip}
+	if err := RouteReplace(&route); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err = RouteList(link, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(routes) != 1 || !routes[0].Src.Equal(ip) {
+		t.Fatal("Route not replaced properly")
+	}
+
+	if err := RouteDel(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err = RouteList(link, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 0 {
+		t.Fatal("Route not removed properly")
+	}
+
+}
+
+func TestRouteAddIncomplete(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// get loopback interface
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bring the interface up
+	if err = LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	route := Route{LinkIndex: link.Attrs().Index}
+	if err := RouteAdd(&route); err == nil {
+		t.Fatal("Adding incomplete route should fail")
+	}
+}
+
+func expectRouteUpdate(ch <-chan RouteUpdate, t uint16, dst net.IP) bool {
+	for {
+		timeout := time.After(time.Minute)
+		select {
+		case update := <-ch:
+			if update.Type == t && update.Route.Dst.IP.Equal(dst) {
+				return true
+			}
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+func TestRouteSubscribe(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	ch := make(chan RouteUpdate)
+	done := make(chan struct{})
+	defer close(done)
+	if err := RouteSubscribe(ch, done); err != nil {
+		t.Fatal(err)
+	}
+
+	// get loopback interface
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bring the interface up
+	if err = LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	// add a gateway route
+	dst := &net.IPNet{
+		IP:   net.IPv4(192, 168, 0, 0),
+		Mask: net.CIDRMask(24, 32),
+	}
+
+	ip := net.IPv4(127, 1, 1, 1)
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Src: ip}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+
+	if !expectRouteUpdate(ch, syscall.RTM_NEWROUTE, dst.IP) {
+		t.Fatal("Add update not received as expected")
+	}
+	if err := RouteDel(&route); err != nil {
+		t.Fatal(err)
+	}
+	if !expectRouteUpdate(ch, syscall.RTM_DELROUTE, dst.IP) {
+		t.Fatal("Del update not received as expected")
+	}
+}
+
+func TestRouteSubscribeAt(t *testing.T) {
+	skipUnlessRoot(t)
+
+	// Create an handle on a custom netns
+	newNs, err := netns.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer newNs.Close()
+
+	nh, err := NewHandleAt(newNs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nh.Delete()
+
+	// Subscribe for Route events on the custom netns
+	ch := make(chan RouteUpdate)
+	done := make(chan struct{})
+	defer close(done)
+	if err := RouteSubscribeAt(newNs, ch, done); err != nil {
+		t.Fatal(err)
+	}
+
+	// get loopback interface
+	link, err := nh.LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bring the interface up
+	if err = nh.LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	// add a gateway route
+	dst := &net.IPNet{
+		IP:   net.IPv4(192, 169, 0, 0),
+		Mask: net.CIDRMask(24, 32),
+	}
+
+	ip := net.IPv4(127, 100, 1, 1)
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Src: ip}
+	if err := nh.RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+
+	if !expectRouteUpdate(ch, syscall.RTM_NEWROUTE, dst.IP) {
+		t.Fatal("Add update not received as expected")
+	}
+	if err := nh.RouteDel(&route); err != nil {
+		t.Fatal(err)
+	}
+	if !expectRouteUpdate(ch, syscall.RTM_DELROUTE, dst.IP) {
+		t.Fatal("Del update not received as expected")
+	}
+}
+
+func TestRouteFilterAllTables(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// get loopback interface
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bring the interface up
+	if err = LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	// add a gateway route
+	dst := &net.IPNet{
+		IP:   net.IPv4(1, 1, 1, 1),
+		Mask: net.CIDRMask(32, 32),
+	}
+
+	tables := []int{1000, 1001, 1002}
+	src := net.IPv4(127, 3, 3, 3)
+	for _, table := range tables {
+		route := Route{
+			LinkIndex: link.Attrs().Index,
+			Dst:       dst,
+			Src:       src,
+			Scope:     syscall.RT_SCOPE_LINK,
+			Priority:  13,
+			Table:     table,
+			Type:      syscall.RTN_UNICAST,
+			Tos:       14,
+		}
+		if err := RouteAdd(&route); err != nil {
+			t.Fatal(err)
+		}
+	}
+	routes, err := RouteListFiltered(FAMILY_V4, &Route{
+		Dst:   dst,
+		Src:   src,
+		Scope: syscall.RT_SCOPE_LINK,
+		Table: syscall.RT_TABLE_UNSPEC,
+		Type:  syscall.RTN_UNICAST,
+		Tos:   14,
+	}, RT_FILTER_DST|RT_FILTER_SRC|RT_FILTER_SCOPE|RT_FILTER_TABLE|RT_FILTER_TYPE|RT_FILTER_TOS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 3 {
+		t.Fatal("Routes not added properly")
+	}
+
+	for _, route := range routes {
+		if route.Scope != syscall.RT_SCOPE_LINK {
+			t.Fatal("Invalid Scope. Route not added properly")
+		}
+		if route.Priority != 13 {
+			t.Fatal("Invalid Priority. Route not added properly")
+		}
+		if !tableIDIn(tables, route.Table) {
+			t.Fatalf("Invalid Table %d. Route not added properly", route.Table)
+		}
+		if route.Type != syscall.RTN_UNICAST {
+			t.Fatal("Invalid Type. Route not added properly")
+		}
+		if route.Tos != 14 {
+			t.Fatal("Invalid Tos. Route not added properly")
+		}
+	}
+}
+
+func tableIDIn(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRouteExtraFields(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// get loopback interface
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bring the interface up
+	if err = LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	// add a gateway route
+	dst := &net.IPNet{
+		IP:   net.IPv4(1, 1, 1, 1),
+		Mask: net.CIDRMask(32, 32),
+	}
+
+	src := net.IPv4(127, 3, 3, 3)
+	route := Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       dst,
+		Src:       src,
+		Scope:     syscall.RT_SCOPE_LINK,
+		Priority:  13,
+		Table:     syscall.RT_TABLE_MAIN,
+		Type:      syscall.RTN_UNICAST,
+		Tos:       14,
+	}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteListFiltered(FAMILY_V4, &Route{
+		Dst:   dst,
+		Src:   src,
+		Scope: syscall.RT_SCOPE_LINK,
+		Table: syscall.RT_TABLE_MAIN,
+		Type:  syscall.RTN_UNICAST,
+		Tos:   14,
+	}, RT_FILTER_DST|RT_FILTER_SRC|RT_FILTER_SCOPE|RT_FILTER_TABLE|RT_FILTER_TYPE|RT_FILTER_TOS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not added properly")
+	}
+
+	if routes[0].Scope != syscall.RT_SCOPE_LINK {
+		t.Fatal("Invalid Scope. Route not added properly")
+	}
+	if routes[0].Priority != 13 {
+		t.Fatal("Invalid Priority. Route not added properly")
+	}
+	if routes[0].Table != syscall.RT_TABLE_MAIN {
+		t.Fatal("Invalid Scope. Route not added properly")
+	}
+	if routes[0].Type != syscall.RTN_UNICAST {
+		t.Fatal("Invalid Type. Route not added properly")
+	}
+	if routes[0].Tos != 14 {
+		t.Fatal("Invalid Tos. Route not added properly")
+	}
+}
+
+func TestRouteMultiPath(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// get loopback interface
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bring the interface up
+	if err = LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	// add a gateway route
+	dst := &net.IPNet{
+		IP:   net.IPv4(192, 168, 0, 0),
+		Mask: net.CIDRMask(24, 32),
+	}
+
+	idx := link.Attrs().Index
+	route := Route{Dst: dst, MultiPath: []*NexthopInfo{&NexthopInfo{LinkIndex: idx}, &NexthopInfo{LinkIndex: idx}}}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteList(nil, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("MultiPath Route not added properly")
+	}
+	if len(routes[0].MultiPath) != 2 {
+		t.Fatal("MultiPath Route not added properly")
+	}
+}
+
+func TestFilterDefaultRoute(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// get loopback interface
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bring the interface up
+	if err = LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	address := &Addr{
+		IPNet: &net.IPNet{
+			IP:   net.IPv4(127, 0, 0, 2),
+			Mask: net.CIDRMask(24, 32),
+		},
+	}
+	if err = AddrAdd(link, address); err != nil {
+		t.Fatal(err)
+	}
+
+	// Add default route
+	gw := net.IPv4(127, 0, 0, 2)
+
+	defaultRoute := Route{
+		Dst: nil,
+		Gw:  gw,
+	}
+
+	if err := RouteAdd(&defaultRoute); err != nil {
+		t.Fatal(err)
+	}
+
+	// add an extra route
+	dst := &net.IPNet{
+		IP:   net.IPv4(192, 168, 0, 0),
+		Mask: net.CIDRMask(24, 32),
+	}
+
+	extraRoute := Route{
+		Dst: dst,
+		Gw:  gw,
+	}
+
+	if err := RouteAdd(&extraRoute); err != nil {
+		t.Fatal(err)
+	}
+	var filterTests = []struct {
+		filter   *Route
+		mask     uint64
+		expected net.IP
+	}{
+		{
+			&Route{Dst: nil},
+			RT_FILTER_DST,
+			gw,
+		},
+		{
+			&Route{Dst: dst},
+			RT_FILTER_DST,
+			gw,
+		},
+	}
+
+	for _, f := range filterTests {
+		routes, err := RouteListFiltered(FAMILY_V4, f.filter, f.mask)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(routes) != 1 {
+			t.Fatal("Route not filtered properly")
+		}
+		if !routes[0].Gw.Equal(gw) {
+			t.Fatal("Unexpected Gateway")
+		}
+	}
+
+}
+
+func TestRouteString(t *testing.T) {
+	mplsDst := 100
+	route := Route{
+		LinkIndex: 1,
+		MPLSDst:   &mplsDst,
+		NewDst:    &MPLSDestination{Labels: []int{200}},
+	}
+	if s := route.String(); !strings.Contains(s, "Dst: 100 ") {
+		t.Fatalf("Route string does not show the MPLS label: %s", s)
+	}
+
+	nh := &NexthopInfo{LinkIndex: 1, Gw: net.ParseIP("10.0.0.1")}
+	if s := nh.String(); !strings.Contains(s, "Gw: 10.0.0.1 ") {
+		t.Fatalf("Nexthop string does not show the gateway: %s", s)
+	}
+}
+
+func TestMPLSRouteAddDel(t *testing.T) {
+	tearDown := setUpMPLSNetlinkTest(t)
+	defer tearDown()
+
+	// get loopback interface
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bring the interface up
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	mplsDst := 100
+	route := Route{
+		LinkIndex: link.Attrs().Index,
+		MPLSDst:   &mplsDst,
+		NewDst: &MPLSDestination{
+			Labels: []int{200, 300},
+		},
+	}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteList(link, FAMILY_MPLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not added properly")
+	}
+	if routes[0].MPLSDst == nil || *routes[0].MPLSDst != mplsDst {
+		t.Fatalf("Route has wrong incoming label: %v", routes[0])
+	}
+	newDst, ok := routes[0].NewDst.(*MPLSDestination)
+	if !ok || !reflect.DeepEqual(newDst.Labels, []int{200, 300}) {
+		t.Fatalf("Route has wrong outgoing labels: %v", routes[0])
+	}
+	if s := routes[0].String(); !strings.Contains(s, "Dst: 100 ") || !strings.Contains(s, "NewDst: 200/300") {
+		t.Fatalf("Route string does not show labels: %s", s)
+	}
+
+	if err := RouteDel(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err = RouteList(link, FAMILY_MPLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 0 {
+		t.Fatal("Route not removed properly")
+	}
+
+}
+
+func TestMPLSRouteVia(t *testing.T) {
+	tearDown := setUpMPLSNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(veth); err != nil {
+		t.Fatal(err)
+	}
+	peer, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(peer); err != nil {
+		t.Fatal(err)
+	}
+
+	mplsDst := 100
+	route := Route{
+		LinkIndex: veth.Index,
+		MPLSDst:   &mplsDst,
+		Via:       &Via{AddrFamily: FAMILY_V6, Addr: net.ParseIP("fe80::1")},
+	}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteList(veth, FAMILY_MPLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not added properly")
+	}
+	via := routes[0].Via
+	if via == nil || via.AddrFamily != FAMILY_V6 || !via.Addr.Equal(route.Via.Addr) {
+		t.Fatalf("Route has wrong via: %v", routes[0])
+	}
+	if err := RouteDel(&route); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouteViaIPv6(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(veth); err != nil {
+		t.Fatal(err)
+	}
+	peer, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(peer); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := &net.IPNet{IP: net.IPv4(192, 168, 0, 0), Mask: net.CIDRMask(24, 32)}
+	route := Route{
+		LinkIndex: veth.Index,
+		Dst:       dst,
+		Via:       &Via{AddrFamily: FAMILY_V6, Addr: net.ParseIP("fe80::1")},
+	}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteListFiltered(FAMILY_V4, &Route{Dst: dst}, RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not added properly")
+	}
+	via := routes[0].Via
+	if via == nil || via.AddrFamily != FAMILY_V6 || !via.Addr.Equal(route.Via.Addr) {
+		t.Fatalf("Route has wrong via: %v", routes[0])
+	}
+	if !strings.Contains(routes[0].String(), "Via: Family: 10, Address: fe80::1") {
+		t.Fatalf("Route string does not show the via: %s", routes[0])
+	}
+	if err := RouteDel(&route); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouteSrcNetIPv6(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	_, dst, _ := net.ParseCIDR("fd00:1::/64")
+	_, src, _ := net.ParseCIDR("2001:db8:1::/64")
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, SrcNet: src}
+	if err := RouteAdd(&route); err != nil {
+		if errors.Is(err, syscall.EAFNOSUPPORT) || errors.Is(err, syscall.EOPNOTSUPP) {
+			t.Skip("Kernel built without CONFIG_IPV6_SUBTREES")
+		}
+		t.Fatal(err)
+	}
+
+	req := pkgHandle.newNetlinkRequest(syscall.RTM_GETROUTE, syscall.NLM_F_DUMP)
+	req.AddData(nl.NewIfInfomsg(FAMILY_V6))
+	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWROUTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found *Route
+	for _, m := range msgs {
+		r, err := deserializeRoute(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Dst != nil && r.Dst.String() == dst.String() {
+			if srcLen := nl.DeserializeRtMsg(m).Src_len; srcLen != 64 {
+				t.Fatalf("rtm_src_len is %d, expected 64", srcLen)
+			}
+			found = &r
+		}
+	}
+	if found == nil {
+		t.Fatal("Route not added properly")
 	}
-
-	ip := net.IPv4(127, 100, 1, 1)
-	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Src: ip}
-	if err := nh.RouteAdd(&route); err != nil {
-		t.Fatal(err)
+	if found.SrcNet == nil || found.SrcNet.String() != src.String() {
+		t.Fatalf("Route has source prefix %v, expected %v", found.SrcNet, src)
+	}
+	if !strings.Contains(found.String(), "SrcNet: 2001:db8:1::/64") {
+		t.Fatalf("Route string does not show the source prefix: %s", found)
 	}
 
-	if !expectRouteUpdate(ch, syscall.RTM_NEWROUTE, dst.IP) {
-		t.Fatal("Add update not received as expected")
+	if err := RouteDel(&route); err != nil {
+		t.Fatal(err)
 	}
-	if err := nh.RouteDel(&route); err != nil {
+	routes, err := RouteListFiltered(FAMILY_V6, &Route{Dst: dst}, RT_FILTER_DST)
+	if err != nil {
 		t.Fatal(err)
 	}
-	if !expectRouteUpdate(ch, syscall.RTM_DELROUTE, dst.IP) {
-		t.Fatal("Del update not received as expected")
+	if len(routes) != 0 {
+		t.Fatalf("Route not removed properly: %v", routes)
 	}
 }
 
-func TestRouteFilterAllTables(t *testing.T) {
-	tearDown := setUpNetlinkTest(t)
-	defer tearDown()
+// mroute socket options, from linux/mroute.h
+const (
+	mrtInit   = 200
+	mrtAddVif = 202
+	mrtAddMfc = 204
+)
 
-	// get loopback interface
-	link, err := LinkByName("lo")
+// setUpMrouteVifs opens a multicast routing socket and adds the two
+// ends of a veth pair as its vifs 0 and 1, the socket must stay open
+// for the vifs to exist.
+func setUpMrouteVifs(t *testing.T) (int, []int) {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_IGMP)
 	if err != nil {
 		t.Fatal(err)
 	}
-	// bring the interface up
-	if err = LinkSetUp(link); err != nil {
-		t.Fatal(err)
+	if err := syscall.SetsockoptInt(fd, syscall.IPPROTO_IP, mrtInit, 1); err != nil {
+		syscall.Close(fd)
+		t.Skipf("Multicast routing not available: %v", err)
 	}
 
-	// add a gateway route
-	dst := &net.IPNet{
-		IP:   net.IPv4(1, 1, 1, 1),
-		Mask: net.CIDRMask(32, 32),
+	if err := LinkAdd(&Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}); err != nil {
+		t.Fatal(err)
 	}
-
-	tables := []int{1000, 1001, 1002}
-	src := net.IPv4(127, 3, 3, 3)
-	for _, table := range tables {
-		route := Route{
-			LinkIndex: link.Attrs().Index,
-			Dst:       dst,
-			Src:       src,
-			Scope:     syscall.RT_SCOPE_LINK,
-			Priority:  13,
-			Table:     table,
-			Type:      syscall.RTN_UNICAST,
-			Tos:       14,
+	var vifs []int
+	for _, name := range []string{"foo", "bar"} {
+		link, err := LinkByName(name)
+		if err != nil {
+			t.Fatal(err)
 		}
-		if err := RouteAdd(&route); err != nil {
+		if err := LinkSetUp(link); err != nil {
+			t.Fatal(err)
+		}
+		// struct vifctl with VIFF_USE_IFINDEX
+		vif := make([]byte, 16)
+		native.PutUint16(vif[0:], uint16(len(vifs)))
+		vif[2] = 0x8
+		vif[3] = 1
+		native.PutUint32(vif[8:], uint32(link.Attrs().Index))
+		if err := syscall.SetsockoptString(fd, syscall.IPPROTO_IP, mrtAddVif, string(vif)); err != nil {
 			t.Fatal(err)
 		}
+		vifs = append(vifs, link.Attrs().Index)
 	}
-	routes, err := RouteListFiltered(FAMILY_V4, &Route{
-		Dst:   dst,
-		Src:   src,
-		Scope: syscall.RT_SCOPE_LINK,
-		Table: syscall.RT_TABLE_UNSPEC,
-		Type:  syscall.RTN_UNICAST,
-		Tos:   14,
-	}, RT_FILTER_DST|RT_FILTER_SRC|RT_FILTER_SCOPE|RT_FILTER_TABLE|RT_FILTER_TYPE|RT_FILTER_TOS)
+	return fd, vifs
+}
+
+func TestRouteMfcStats(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	fd, vifs := setUpMrouteVifs(t)
+	defer syscall.Close(fd)
+
+	// struct mfcctl forwarding 10.0.0.1 -> 239.1.1.1 from foo to bar
+	origin, group := net.IPv4(10, 0, 0, 1).To4(), net.IPv4(239, 1, 1, 1).To4()
+	mfc := make([]byte, 60)
+	copy(mfc[0:], origin)
+	copy(mfc[4:], group)
+	native.PutUint16(mfc[8:], 0)
+	mfc[10+1] = 1
+	if err := syscall.SetsockoptString(fd, syscall.IPPROTO_IP, mrtAddMfc, string(mfc)); err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := RouteListFiltered(nl.RTNL_FAMILY_IPMR, &Route{Table: syscall.RT_TABLE_UNSPEC}, RT_FILTER_TABLE)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(routes) != 3 {
-		t.Fatal("Routes not added properly")
+	var found *Route
+	for i, route := range routes {
+		if route.Dst != nil && route.Dst.IP.Equal(group) {
+			found = &routes[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("Multicast route to %s not found in %v", group, routes)
+	}
+	if found.ILinkIndex != vifs[0] {
+		t.Fatalf("Multicast route comes from %d, expected %d", found.ILinkIndex, vifs[0])
 	}
+	if found.MfcStats == nil {
+		t.Fatalf("Multicast route has no stats: %s", found)
+	}
+	if found.MfcStats.Packets != 0 || found.MfcStats.Bytes != 0 || found.MfcStats.WrongIf != 0 {
+		t.Fatalf("Unused multicast route has stats %+v", *found.MfcStats)
+	}
+}
 
-	for _, route := range routes {
-		if route.Scope != syscall.RT_SCOPE_LINK {
-			t.Fatal("Invalid Scope. Route not added properly")
-		}
-		if route.Priority != 13 {
-			t.Fatal("Invalid Priority. Route not added properly")
-		}
-		if !tableIDIn(tables, route.Table) {
-			t.Fatalf("Invalid Table %d. Route not added properly", route.Table)
+func TestRouteNormalizedWeights(t *testing.T) {
+	tests := []struct {
+		hops     []int
+		expected []float64
+	}{
+		{nil, nil},
+		{[]int{4}, []float64{1}},
+		{[]int{0, 0, 0, 0}, []float64{0.25, 0.25, 0.25, 0.25}},
+		{[]int{0, 2}, []float64{0.25, 0.75}},
+		{[]int{1, 0, 6}, []float64{0.2, 0.1, 0.7}},
+	}
+	for _, test := range tests {
+		route := Route{}
+		for _, hops := range test.hops {
+			route.MultiPath = append(route.MultiPath, &NexthopInfo{Hops: hops})
 		}
-		if route.Type != syscall.RTN_UNICAST {
-			t.Fatal("Invalid Type. Route not added properly")
+		weights := route.NormalizedWeights()
+		if len(weights) != len(test.expected) || (weights == nil) != (test.expected == nil) {
+			t.Fatalf("Weights of %v are %v, expected %v", test.hops, weights, test.expected)
 		}
-		if route.Tos != 14 {
-			t.Fatal("Invalid Tos. Route not added properly")
+		sum := 0.0
+		for i := range weights {
+			if math.Abs(weights[i]-test.expected[i]) > 1e-9 {
+				t.Fatalf("Weights of %v are %v, expected %v", test.hops, weights, test.expected)
+			}
+			sum += weights[i]
 		}
-	}
-}
-
-func tableIDIn(ids []int, id int) bool {
-	for _, v := range ids {
-		if v == id {
-			return true
+		if len(weights) > 0 && math.Abs(sum-1) > 1e-9 {
+			t.Fatalf("Weights of %v sum to %f", test.hops, sum)
 		}
 	}
-	return false
 }
 
-func TestRouteExtraFields(t *testing.T) {
-	tearDown := setUpNetlinkTest(t)
+func TestRouteMultiPathEncap(t *testing.T) {
+	tearDown := setUpMPLSNetlinkTest(t)
 	defer tearDown()
 
 	// get loopback interface
@@ -361,60 +1784,37 @@ func TestRouteExtraFields(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// add a gateway route
 	dst := &net.IPNet{
-		IP:   net.IPv4(1, 1, 1, 1),
-		Mask: net.CIDRMask(32, 32),
+		IP:   net.IPv4(192, 168, 0, 0),
+		Mask: net.CIDRMask(24, 32),
 	}
-
-	src := net.IPv4(127, 3, 3, 3)
+	idx := link.Attrs().Index
 	route := Route{
-		LinkIndex: link.Attrs().Index,
-		Dst:       dst,
-		Src:       src,
-		Scope:     syscall.RT_SCOPE_LINK,
-		Priority:  13,
-		Table:     syscall.RT_TABLE_MAIN,
-		Type:      syscall.RTN_UNICAST,
-		Tos:       14,
+		Dst: dst,
+		MultiPath: []*NexthopInfo{
+			&NexthopInfo{LinkIndex: idx, Encap: &MPLSEncap{Labels: []int{100}}},
+			&NexthopInfo{LinkIndex: idx, Encap: &MPLSEncap{Labels: []int{200, 300}}},
+		},
 	}
 	if err := RouteAdd(&route); err != nil {
 		t.Fatal(err)
 	}
-	routes, err := RouteListFiltered(FAMILY_V4, &Route{
-		Dst:   dst,
-		Src:   src,
-		Scope: syscall.RT_SCOPE_LINK,
-		Table: syscall.RT_TABLE_MAIN,
-		Type:  syscall.RTN_UNICAST,
-		Tos:   14,
-	}, RT_FILTER_DST|RT_FILTER_SRC|RT_FILTER_SCOPE|RT_FILTER_TABLE|RT_FILTER_TYPE|RT_FILTER_TOS)
+	routes, err := RouteList(nil, FAMILY_V4)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(routes) != 1 {
-		t.Fatal("Route not added properly")
-	}
-
-	if routes[0].Scope != syscall.RT_SCOPE_LINK {
-		t.Fatal("Invalid Scope. Route not added properly")
-	}
-	if routes[0].Priority != 13 {
-		t.Fatal("Invalid Priority. Route not added properly")
-	}
-	if routes[0].Table != syscall.RT_TABLE_MAIN {
-		t.Fatal("Invalid Scope. Route not added properly")
-	}
-	if routes[0].Type != syscall.RTN_UNICAST {
-		t.Fatal("Invalid Type. Route not added properly")
+	if len(routes) != 1 || len(routes[0].MultiPath) != 2 {
+		t.Fatal("MultiPath Route not added properly")
 	}
-	if routes[0].Tos != 14 {
-		t.Fatal("Invalid Tos. Route not added properly")
+	for i, nh := range routes[0].MultiPath {
+		if nh.Encap == nil || nh.Encap.String() != route.MultiPath[i].Encap.String() {
+			t.Fatalf("Nexthop %d encap is %v, expected %v", i, nh.Encap, route.MultiPath[i].Encap)
+		}
 	}
 }
 
-func TestRouteMultiPath(t *testing.T) {
-	tearDown := setUpNetlinkTest(t)
+func TestRouteILAEncap(t *testing.T) {
+	tearDown := setUpNetlinkTestWithKModule(t, "ila")
 	defer tearDown()
 
 	// get loopback interface
@@ -427,30 +1827,37 @@ func TestRouteMultiPath(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// add a gateway route
+	// the identifier 2000::1 is a LUID
 	dst := &net.IPNet{
-		IP:   net.IPv4(192, 168, 0, 0),
-		Mask: net.CIDRMask(24, 32),
+		IP:   net.ParseIP("2001:db8:1:2:2000::1"),
+		Mask: net.CIDRMask(128, 128),
 	}
-
-	idx := link.Attrs().Index
-	route := Route{Dst: dst, MultiPath: []*NexthopInfo{&NexthopInfo{LinkIndex: idx}, &NexthopInfo{LinkIndex: idx}}}
+	encap := &ILAEncap{
+		Locator:   0x2001000000000001,
+		CsumMode:  ILA_CSUM_NO_ACTION,
+		IdentType: ILA_ATYPE_LUID,
+		HookType:  ILA_HOOK_ROUTE_OUTPUT,
+	}
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Encap: encap}
 	if err := RouteAdd(&route); err != nil {
 		t.Fatal(err)
 	}
-	routes, err := RouteList(nil, FAMILY_V4)
+	routes, err := RouteListFiltered(FAMILY_V6, &Route{Dst: dst}, RT_FILTER_DST)
 	if err != nil {
 		t.Fatal(err)
 	}
 	if len(routes) != 1 {
-		t.Fatal("MultiPath Route not added properly")
+		t.Fatal("ILA route not added properly")
 	}
-	if len(routes[0].MultiPath) != 2 {
-		t.Fatal("MultiPath Route not added properly")
+	if !reflect.DeepEqual(routes[0].Encap, encap) {
+		t.Fatalf("Route encap is %v, expected %v", routes[0].Encap, encap)
+	}
+	if err := RouteDel(&route); err != nil {
+		t.Fatal(err)
 	}
 }
 
-func TestFilterDefaultRoute(t *testing.T) {
+func TestRouteDropTypes(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
 
@@ -464,75 +1871,52 @@ func TestFilterDefaultRoute(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	address := &Addr{
-		IPNet: &net.IPNet{
-			IP:   net.IPv4(127, 0, 0, 2),
-			Mask: net.CIDRMask(24, 32),
-		},
-	}
-	if err = AddrAdd(link, address); err != nil {
-		t.Fatal(err)
-	}
-
-	// Add default route
-	gw := net.IPv4(127, 0, 0, 2)
-
-	defaultRoute := Route{
-		Dst: nil,
-		Gw:  gw,
-	}
-
-	if err := RouteAdd(&defaultRoute); err != nil {
-		t.Fatal(err)
-	}
-
-	// add an extra route
-	dst := &net.IPNet{
-		IP:   net.IPv4(192, 168, 0, 0),
-		Mask: net.CIDRMask(24, 32),
-	}
-
-	extraRoute := Route{
-		Dst: dst,
-		Gw:  gw,
-	}
-
-	if err := RouteAdd(&extraRoute); err != nil {
-		t.Fatal(err)
-	}
-	var filterTests = []struct {
-		filter   *Route
-		mask     uint64
-		expected net.IP
+	var typeTests = []struct {
+		newRoute func(*net.IPNet) *Route
+		typ      int
+		name     string
 	}{
-		{
-			&Route{Dst: nil},
-			RT_FILTER_DST,
-			gw,
-		},
-		{
-			&Route{Dst: dst},
-			RT_FILTER_DST,
-			gw,
-		},
+		{NewBlackholeRoute, syscall.RTN_BLACKHOLE, "blackhole"},
+		{NewUnreachableRoute, syscall.RTN_UNREACHABLE, "unreachable"},
+		{NewProhibitRoute, syscall.RTN_PROHIBIT, "prohibit"},
 	}
 
-	for _, f := range filterTests {
-		routes, err := RouteListFiltered(FAMILY_V4, f.filter, f.mask)
+	for i, tt := range typeTests {
+		dst := &net.IPNet{
+			IP:   net.IPv4(192, 168, byte(i), 0),
+			Mask: net.CIDRMask(24, 32),
+		}
+		route := tt.newRoute(dst)
+		if err := RouteAdd(route); err != nil {
+			t.Fatal(err)
+		}
+		routes, err := RouteListFiltered(FAMILY_V4, &Route{Dst: dst}, RT_FILTER_DST)
 		if err != nil {
 			t.Fatal(err)
 		}
 		if len(routes) != 1 {
-			t.Fatal("Route not filtered properly")
+			t.Fatalf("%s route not added properly", tt.name)
 		}
-		if !routes[0].Gw.Equal(gw) {
-			t.Fatal("Unexpected Gateway")
+		if routes[0].Type != tt.typ {
+			t.Fatalf("Invalid Type %d, expected %d", routes[0].Type, tt.typ)
+		}
+		if !strings.Contains(routes[0].String(), "Type: "+tt.name) {
+			t.Fatalf("Route string %q does not contain type %s", routes[0], tt.name)
+		}
+		if err := RouteDel(route); err != nil {
+			t.Fatal(err)
+		}
+		routes, err = RouteListFiltered(FAMILY_V4, &Route{Dst: dst}, RT_FILTER_DST)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(routes) != 0 {
+			t.Fatalf("%s route not removed properly", tt.name)
 		}
 	}
-
 }
 
-func TestMPLSRouteAddDel(t *testing.T) {
+func TestMPLSRouteTTLPropagate(t *testing.T) {
 	tearDown := setUpMPLSNetlinkTest(t)
 	defer tearDown()
 
@@ -548,12 +1932,14 @@ func TestMPLSRouteAddDel(t *testing.T) {
 	}
 
 	mplsDst := 100
+	ttlPropagate := 0
 	route := Route{
 		LinkIndex: link.Attrs().Index,
 		MPLSDst:   &mplsDst,
 		NewDst: &MPLSDestination{
 			Labels: []int{200, 300},
 		},
+		TTLPropagate: &ttlPropagate,
 	}
 	if err := RouteAdd(&route); err != nil {
 		t.Fatal(err)
@@ -565,16 +1951,48 @@ func TestMPLSRouteAddDel(t *testing.T) {
 	if len(routes) != 1 {
 		t.Fatal("Route not added properly")
 	}
+	if routes[0].TTLPropagate == nil || *routes[0].TTLPropagate != ttlPropagate {
+		t.Fatalf("TTLPropagate not round-tripped: %v", routes[0])
+	}
 
 	if err := RouteDel(&route); err != nil {
 		t.Fatal(err)
 	}
-	routes, err = RouteList(link, FAMILY_MPLS)
+}
+
+func TestRouteLinkDownFlag(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo", TxQLen: testTxQLen}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(routes) != 0 {
-		t.Fatal("Route not removed properly")
+	// the peer stays down, so foo has no carrier
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
 	}
 
+	dst := &net.IPNet{
+		IP:   net.IPv4(192, 168, 0, 0),
+		Mask: net.CIDRMask(24, 32),
+	}
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Scope: SCOPE_LINK}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteList(link, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not added properly")
+	}
+	if routes[0].Flags&int(FLAG_LINKDOWN) == 0 {
+		t.Fatalf("Route flags %v do not contain linkdown", routes[0].ListFlags())
+	}
 }
@@ -9,6 +9,7 @@ import (
 type Rule struct {
 	Priority          int
 	Family            int
+	Action            int // one of nl.FR_ACT_*, a table lookup when unset
 	Table             int
 	Mark              int
 	Mask              int
@@ -21,6 +22,15 @@ type Rule struct {
 	OifName           string
 	SuppressIfgroup   int
 	SuppressPrefixlen int
+	L3mdev            bool   // look up the table of the VRF device the packet uses
+	Dscp              *uint8 // the 6 bit dscp, nil matches any
+	UIDRange          *RuleUIDRange
+}
+
+// RuleUIDRange is the inclusive range of uids a rule matches.
+type RuleUIDRange struct {
+	Start uint32
+	End   uint32
 }
 
 func (r Rule) String() string {
@@ -1,6 +1,7 @@
 package netlink
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"syscall"
@@ -8,19 +9,70 @@ import (
 	"github.com/vishvananda/netlink/nl"
 )
 
-// RuleAdd adds a rule to the system.
+// dscpError is returned by RuleAdd when the kernel can't match the dscp of
+// an IPv4 rule.
+var dscpError = fmt.Errorf("kernel does not support FRA_DSCP, IPv4 rules can only match a dscp within the tos bits")
+
+// RuleAdd adds a rule to the system. A Dscp that doesn't fit in the tos
+// bits of an IPv4 rule needs FRA_DSCP, without it the rule is not added.
 // Equivalent to: ip rule add
 func RuleAdd(rule *Rule) error {
 	return pkgHandle.RuleAdd(rule)
 }
 
-// RuleAdd adds a rule to the system.
+// RuleAdd adds a rule to the system. A Dscp that doesn't fit in the tos
+// bits of an IPv4 rule needs FRA_DSCP, without it the rule is not added.
 // Equivalent to: ip rule add
 func (h *Handle) RuleAdd(rule *Rule) error {
+	if rule.Dscp != nil && !ruleDscpInTos(ruleFamily(rule), *rule.Dscp) {
+		if err := h.ruleProbeDscp(ruleFamily(rule), *rule.Dscp); err != nil {
+			return err
+		}
+	}
 	req := h.newNetlinkRequest(syscall.RTM_NEWRULE, syscall.NLM_F_CREATE|syscall.NLM_F_EXCL|syscall.NLM_F_ACK)
 	return ruleHandle(rule, req)
 }
 
+// ruleProbeDscp tells if the kernel applies FRA_DSCP. Kernels without it
+// silently ignore the attribute, so a rule that does nothing is added with
+// it and the one echoed back is checked and deleted.
+func (h *Handle) ruleProbeDscp(family int, dscp uint8) error {
+	probe := NewRule()
+	probe.Family = family
+	probe.Action = nl.FR_ACT_NOP
+	probe.Dscp = &dscp
+	req := h.newNetlinkRequest(syscall.RTM_NEWRULE, syscall.NLM_F_CREATE|syscall.NLM_F_EXCL|syscall.NLM_F_ACK|syscall.NLM_F_ECHO)
+	if err := ruleRequest(probe, req); err != nil {
+		return err
+	}
+	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWRULE)
+	if err != nil {
+		return err
+	}
+	applied := false
+	for _, m := range msgs {
+		msg := nl.DeserializeRtMsg(m)
+		attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+		if err != nil {
+			return err
+		}
+		for _, attr := range attrs {
+			if attr.Attr.Type == nl.FRA_DSCP {
+				applied = true
+			}
+		}
+		del := h.newNetlinkRequest(syscall.RTM_DELRULE, syscall.NLM_F_ACK)
+		del.AddRawData(m)
+		if _, err := del.Execute(syscall.NETLINK_ROUTE, 0); err != nil {
+			return err
+		}
+	}
+	if !applied {
+		return dscpError
+	}
+	return nil
+}
+
 // RuleDel deletes a rule from the system.
 // Equivalent to: ip rule del
 func RuleDel(rule *Rule) error {
@@ -30,11 +82,40 @@ func RuleDel(rule *Rule) error {
 // RuleDel deletes a rule from the system.
 // Equivalent to: ip rule del
 func (h *Handle) RuleDel(rule *Rule) error {
-	req := h.newNetlinkRequest(syscall.RTM_DELRULE, syscall.NLM_F_CREATE|syscall.NLM_F_EXCL|syscall.NLM_F_ACK)
+	req := h.newNetlinkRequest(syscall.RTM_DELRULE, syscall.NLM_F_ACK)
 	return ruleHandle(rule, req)
 }
 
 func ruleHandle(rule *Rule, req *nl.NetlinkRequest) error {
+	if err := ruleRequest(rule, req); err != nil {
+		return err
+	}
+	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
+	return err
+}
+
+// ruleFamily returns the family of the rule, taken from its addresses first.
+func ruleFamily(rule *Rule) int {
+	switch {
+	case rule.Src != nil && rule.Src.IP != nil:
+		return nl.GetIPFamily(rule.Src.IP)
+	case rule.Dst != nil && rule.Dst.IP != nil:
+		return nl.GetIPFamily(rule.Dst.IP)
+	case rule.Family != 0:
+		return rule.Family
+	}
+	return syscall.AF_INET
+}
+
+// ruleDscpInTos tells if the dscp can go in the tos of the header, which
+// IPv4 rules only match on the tos bits.
+func ruleDscpInTos(family int, dscp uint8) bool {
+	return family == syscall.AF_INET6 || dscp<<2&^nl.IPTOS_TOS_MASK == 0
+}
+
+// ruleRequest adds the rule to req. The dscp goes in the tos of the header
+// when it fits and in FRA_DSCP otherwise.
+func ruleRequest(rule *Rule, req *nl.NetlinkRequest) error {
 	msg := nl.NewRtMsg()
 	msg.Family = syscall.AF_INET
 	if rule.Family != 0 {
@@ -80,6 +161,20 @@ func ruleHandle(rule *Rule, req *nl.NetlinkRequest) error {
 		}
 	}
 
+	// the action goes in the type of the header, NewRtMsg leaves it to
+	// RTN_UNICAST which is FR_ACT_TO_TBL
+	switch {
+	case rule.Action != nl.FR_ACT_UNSPEC:
+		msg.Type = uint8(rule.Action)
+	case rule.Goto >= 0:
+		msg.Type = nl.FR_ACT_GOTO
+	}
+
+	dscpInTos := rule.Dscp != nil && ruleDscpInTos(int(msg.Family), *rule.Dscp)
+	if dscpInTos {
+		msg.Tos = *rule.Dscp << 2
+	}
+
 	req.AddData(msg)
 	for i := range rtAttrs {
 		req.AddData(rtAttrs[i])
@@ -130,20 +225,29 @@ func ruleHandle(rule *Rule, req *nl.NetlinkRequest) error {
 		}
 	}
 	if rule.IifName != "" {
-		req.AddData(nl.NewRtAttr(nl.FRA_IIFNAME, []byte(rule.IifName)))
+		req.AddData(nl.NewRtAttr(nl.FRA_IIFNAME, nl.ZeroTerminated(rule.IifName)))
 	}
 	if rule.OifName != "" {
-		req.AddData(nl.NewRtAttr(nl.FRA_OIFNAME, []byte(rule.OifName)))
+		req.AddData(nl.NewRtAttr(nl.FRA_OIFNAME, nl.ZeroTerminated(rule.OifName)))
+	}
+	if rule.L3mdev {
+		req.AddData(nl.NewRtAttr(nl.FRA_L3MDEV, nl.Uint8Attr(1)))
 	}
 	if rule.Goto >= 0 {
-		msg.Type = nl.FR_ACT_NOP
 		b := make([]byte, 4)
 		native.PutUint32(b, uint32(rule.Goto))
 		req.AddData(nl.NewRtAttr(nl.FRA_GOTO, b))
 	}
-
-	_, err := req.Execute(syscall.NETLINK_ROUTE, 0)
-	return err
+	if rule.UIDRange != nil {
+		b := make([]byte, 8)
+		native.PutUint32(b[0:4], rule.UIDRange.Start)
+		native.PutUint32(b[4:8], rule.UIDRange.End)
+		req.AddData(nl.NewRtAttr(nl.FRA_UID_RANGE, b))
+	}
+	if rule.Dscp != nil && !dscpInTos {
+		req.AddData(nl.NewRtAttr(nl.FRA_DSCP, nl.Uint8Attr(*rule.Dscp)))
+	}
+	return nil
 }
 
 // RuleList lists rules in the system.
@@ -156,7 +260,9 @@ func RuleList(family int) ([]Rule, error) {
 // Equivalent to: ip rule list
 func (h *Handle) RuleList(family int) ([]Rule, error) {
 	req := h.newNetlinkRequest(syscall.RTM_GETRULE, syscall.NLM_F_DUMP|syscall.NLM_F_REQUEST)
-	msg := nl.NewIfInfomsg(family)
+	// a struct fib_rule_hdr, which has the layout of a struct rtmsg,
+	// strict checking rejects anything but the family
+	msg := &nl.RtMsg{RtMsg: syscall.RtMsg{Family: uint8(family)}}
 	req.AddData(msg)
 
 	msgs, err := req.Execute(syscall.NETLINK_ROUTE, syscall.RTM_NEWRULE)
@@ -174,6 +280,11 @@ func (h *Handle) RuleList(family int) ([]Rule, error) {
 		}
 
 		rule := NewRule()
+		rule.Action = int(msg.Type)
+		if msg.Tos != 0 {
+			dscp := msg.Tos >> 2
+			rule.Dscp = &dscp
+		}
 
 		for j := range attrs {
 			switch attrs[j].Attr.Type {
@@ -196,9 +307,9 @@ func (h *Handle) RuleList(family int) ([]Rule, error) {
 			case nl.FRA_TUN_ID:
 				rule.TunID = uint(native.Uint64(attrs[j].Value[0:4]))
 			case nl.FRA_IIFNAME:
-				rule.IifName = string(attrs[j].Value[:len(attrs[j].Value)-1])
+				rule.IifName = string(bytes.TrimRight(attrs[j].Value, "\x00"))
 			case nl.FRA_OIFNAME:
-				rule.OifName = string(attrs[j].Value[:len(attrs[j].Value)-1])
+				rule.OifName = string(bytes.TrimRight(attrs[j].Value, "\x00"))
 			case nl.FRA_SUPPRESS_PREFIXLEN:
 				i := native.Uint32(attrs[j].Value[0:4])
 				if i != 0xffffffff {
@@ -215,6 +326,16 @@ func (h *Handle) RuleList(family int) ([]Rule, error) {
 				rule.Goto = int(native.Uint32(attrs[j].Value[0:4]))
 			case nl.FRA_PRIORITY:
 				rule.Priority = int(native.Uint32(attrs[j].Value[0:4]))
+			case nl.FRA_L3MDEV:
+				rule.L3mdev = attrs[j].Value[0] != 0
+			case nl.FRA_UID_RANGE:
+				rule.UIDRange = &RuleUIDRange{
+					Start: native.Uint32(attrs[j].Value[0:4]),
+					End:   native.Uint32(attrs[j].Value[4:8]),
+				}
+			case nl.FRA_DSCP:
+				dscp := attrs[j].Value[0]
+				rule.Dscp = &dscp
 			}
 		}
 		res = append(res, *rule)
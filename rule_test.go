@@ -6,6 +6,8 @@ import (
 	"net"
 	"syscall"
 	"testing"
+
+	"github.com/vishvananda/netlink/nl"
 )
 
 func TestRuleAddDel(t *testing.T) {
@@ -68,3 +70,228 @@ func TestRuleAddDel(t *testing.T) {
 		t.Fatal("Rule not removed properly")
 	}
 }
+
+func TestRuleAddDelL3mdev(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// the table of an l3mdev rule comes from the device
+	l3mdev := NewRule()
+	l3mdev.Priority = 1000
+	l3mdev.L3mdev = true
+	if err := RuleAdd(l3mdev); err != nil {
+		t.Fatal(err)
+	}
+	srcNet := &net.IPNet{IP: net.IPv4(172, 16, 0, 0), Mask: net.CIDRMask(16, 32)}
+	rule := NewRule()
+	rule.Priority = 1001
+	rule.Table = 100
+	rule.Src = srcNet
+	if err := RuleAdd(rule); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := RuleList(syscall.AF_INET)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var foundL3mdev, foundRule bool
+	for _, r := range rules {
+		switch r.Priority {
+		case l3mdev.Priority:
+			if !r.L3mdev || r.Table != syscall.RT_TABLE_UNSPEC {
+				t.Fatalf("Rule %d is not an l3mdev rule: %+v", r.Priority, r)
+			}
+			foundL3mdev = true
+		case rule.Priority:
+			if r.L3mdev || r.Table != rule.Table || r.Src == nil || r.Src.String() != srcNet.String() {
+				t.Fatalf("Rule %d has different options than the one added: %+v", r.Priority, r)
+			}
+			foundRule = true
+		}
+	}
+	if !foundL3mdev || !foundRule {
+		t.Fatalf("Rules not added properly: %v", rules)
+	}
+
+	if err := RuleDel(l3mdev); err != nil {
+		t.Fatal(err)
+	}
+	rules, err = RuleList(syscall.AF_INET)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rules {
+		if r.L3mdev {
+			t.Fatalf("l3mdev rule not removed: %+v", r)
+		}
+	}
+}
+
+func TestRuleAddDelActions(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	gotoRule := NewRule()
+	gotoRule.Priority = 100
+	gotoRule.Mark = 1
+	gotoRule.Goto = 200
+	if err := RuleAdd(gotoRule); err != nil {
+		t.Fatal(err)
+	}
+	blackhole := NewRule()
+	blackhole.Priority = 200
+	blackhole.Action = nl.FR_ACT_BLACKHOLE
+	if err := RuleAdd(blackhole); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := RuleList(syscall.AF_INET)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var foundGoto, foundBlackhole bool
+	for _, r := range rules {
+		switch r.Priority {
+		case gotoRule.Priority:
+			if r.Action != nl.FR_ACT_GOTO || r.Goto != gotoRule.Goto {
+				t.Fatalf("Rule %d is not a goto rule: %+v", r.Priority, r)
+			}
+			foundGoto = true
+		case blackhole.Priority:
+			if r.Action != nl.FR_ACT_BLACKHOLE {
+				t.Fatalf("Rule %d is not a blackhole rule: %+v", r.Priority, r)
+			}
+			foundBlackhole = true
+		default:
+			if r.Action != nl.FR_ACT_TO_TBL {
+				t.Fatalf("Rule %d is not a table lookup: %+v", r.Priority, r)
+			}
+		}
+	}
+	if !foundGoto || !foundBlackhole {
+		t.Fatalf("Rules not added properly: %v", rules)
+	}
+
+	if err := RuleDel(gotoRule); err != nil {
+		t.Fatal(err)
+	}
+	if err := RuleDel(blackhole); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRuleIifOifName(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	veth := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+
+	// iif lo matches the locally generated traffic, the names of
+	// missing devices are kept until they show up
+	added := []*Rule{NewRule(), NewRule(), NewRule()}
+	added[0].IifName = "foo"
+	added[0].OifName = "bar"
+	added[1].IifName = "lo"
+	added[2].OifName = "baz"
+	for i, rule := range added {
+		rule.Priority = 100 + i
+		rule.Table = 100
+		if err := RuleAdd(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rules, err := RuleList(syscall.AF_INET)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range added {
+		found := false
+		for _, r := range rules {
+			if r.Priority != rule.Priority {
+				continue
+			}
+			if r.IifName != rule.IifName || r.OifName != rule.OifName {
+				t.Fatalf("Rule %d has different devices than the one added: iif %q oif %q", r.Priority, r.IifName, r.OifName)
+			}
+			found = true
+		}
+		if !found {
+			t.Fatalf("Rule %d not found", rule.Priority)
+		}
+	}
+
+	for _, rule := range added {
+		if err := RuleDel(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRuleDscp(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// EF needs FRA_DSCP for IPv4, 4 fits in the tos bits
+	for _, tc := range []struct {
+		family int
+		dscp   uint8
+	}{
+		{syscall.AF_INET, 4},
+		{syscall.AF_INET, 46},
+		{syscall.AF_INET6, 46},
+	} {
+		family := tc.family
+		dscp := tc.dscp
+		rule := NewRule()
+		rule.Family = family
+		rule.Priority = 100
+		rule.Table = 100
+		rule.Dscp = &dscp
+		if err := RuleAdd(rule); err != nil {
+			if err == dscpError {
+				t.Logf("Kernel can't match the full dscp of IPv4 rules")
+				rules, err := RuleList(family)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, r := range rules {
+					if r.Dscp != nil || r.Priority == rule.Priority {
+						t.Fatalf("Rule %d was left behind", r.Priority)
+					}
+				}
+				continue
+			}
+			t.Fatal(err)
+		}
+
+		rules, err := RuleList(family)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, r := range rules {
+			if r.Priority != rule.Priority {
+				if r.Dscp != nil {
+					t.Fatalf("Rule %d was left behind", r.Priority)
+				}
+				continue
+			}
+			if r.Dscp == nil || *r.Dscp != dscp {
+				t.Fatalf("Rule %d has a different dscp than the one added: %v", r.Priority, r.Dscp)
+			}
+			found = true
+		}
+		if !found {
+			t.Fatalf("Rule %d not found", rule.Priority)
+		}
+
+		if err := RuleDel(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
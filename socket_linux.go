@@ -123,11 +123,6 @@ func SocketGet(local, remote net.Addr) (*Socket, error) {
 		return nil, ErrNotImplemented
 	}
 
-	s, err := nl.Subscribe(syscall.NETLINK_INET_DIAG)
-	if err != nil {
-		return nil, err
-	}
-	defer s.Close()
 	req := nl.NewNetlinkRequest(nl.SOCK_DIAG_BY_FAMILY, 0)
 	req.AddData(&socketRequest{
 		Family:   syscall.AF_INET,
@@ -140,8 +135,7 @@ func SocketGet(local, remote net.Addr) (*Socket, error) {
 			Cookie:          [2]uint32{nl.TCPDIAG_NOCOOKIE, nl.TCPDIAG_NOCOOKIE},
 		},
 	})
-	s.Send(req)
-	msgs, err := s.Receive()
+	msgs, err := req.Execute(syscall.NETLINK_INET_DIAG, nl.SOCK_DIAG_BY_FAMILY)
 	if err != nil {
 		return nil, err
 	}
@@ -152,7 +146,7 @@ func SocketGet(local, remote net.Addr) (*Socket, error) {
 		return nil, fmt.Errorf("multiple (%d) matching sockets", len(msgs))
 	}
 	sock := &Socket{}
-	if err := sock.deserialize(msgs[0].Data); err != nil {
+	if err := sock.deserialize(msgs[0]); err != nil {
 		return nil, err
 	}
 	return sock, nil
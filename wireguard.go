@@ -0,0 +1,31 @@
+package netlink
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"time"
+)
+
+// WireguardPeer is a peer of a wireguard link, identified by its public
+// key. A zero PresharedKey means none. ReplaceAllowedIPs drops the
+// allowed ips the peer already has instead of adding to them. The zone of
+// a link-local Endpoint is set as the name or the index of its link and
+// reported as the index. LastHandshakeTime, RxBytes and TxBytes are only
+// reported by the kernel.
+type WireguardPeer struct {
+	PublicKey                   [32]byte
+	PresharedKey                [32]byte
+	Endpoint                    *net.UDPAddr
+	PersistentKeepaliveInterval *uint16 // in seconds, 0 disables it, nil leaves it unchanged
+	AllowedIPs                  []net.IPNet
+	ReplaceAllowedIPs           bool
+	LastHandshakeTime           time.Time
+	RxBytes                     uint64
+	TxBytes                     uint64
+}
+
+func (p *WireguardPeer) String() string {
+	return fmt.Sprintf("{PublicKey: %s, Endpoint: %v, AllowedIPs: %v}",
+		base64.StdEncoding.EncodeToString(p.PublicKey[:]), p.Endpoint, p.AllowedIPs)
+}
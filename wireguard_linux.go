@@ -0,0 +1,337 @@
+package netlink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/vishvananda/netlink/nl"
+)
+
+// WireguardPeerList gets the peers of the wireguard link.
+// Equivalent to: `wg show $link`
+func WireguardPeerList(link Link) ([]WireguardPeer, error) {
+	return pkgHandle.WireguardPeerList(link)
+}
+
+// WireguardPeerList gets the peers of the wireguard link.
+// Equivalent to: `wg show $link`
+func (h *Handle) WireguardPeerList(link Link) ([]WireguardPeer, error) {
+	base := link.Attrs()
+	h.ensureIndex(base)
+
+	req, err := h.newWireguardRequest(nl.WG_CMD_GET_DEVICE, syscall.NLM_F_DUMP)
+	if err != nil {
+		return nil, err
+	}
+	req.AddData(nl.NewRtAttr(nl.WGDEVICE_A_IFINDEX, nl.Uint32Attr(uint32(base.Index))))
+	msgs, err := req.Execute(syscall.NETLINK_GENERIC, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// The kernel splits devices with many peers or allowed ips over
+	// several messages, a peer continued in the next message is
+	// repeated with the same public key.
+	var peers []WireguardPeer
+	for _, m := range msgs {
+		attrs, err := nl.ParseRouteAttr(m[nl.SizeofGenlmsg:])
+		if err != nil {
+			return nil, err
+		}
+		for _, attr := range attrs {
+			if attr.Attr.Type&^syscall.NLA_F_NESTED != nl.WGDEVICE_A_PEERS {
+				continue
+			}
+			entries, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				peer, err := parseWireguardPeer(entry.Value)
+				if err != nil {
+					return nil, err
+				}
+				if n := len(peers); n > 0 && peers[n-1].PublicKey == peer.PublicKey {
+					peers[n-1].AllowedIPs = append(peers[n-1].AllowedIPs, peer.AllowedIPs...)
+					continue
+				}
+				peers = append(peers, peer)
+			}
+		}
+	}
+	return peers, nil
+}
+
+// WireguardAddPeer adds the peer to the wireguard link, or updates the
+// peer with the same public key, without touching the other peers.
+// Equivalent to: `wg set $link peer $key ...`
+func WireguardAddPeer(link Link, peer WireguardPeer) error {
+	return pkgHandle.WireguardAddPeer(link, peer)
+}
+
+// WireguardAddPeer adds the peer to the wireguard link, or updates the
+// peer with the same public key, without touching the other peers.
+// Equivalent to: `wg set $link peer $key ...`
+func (h *Handle) WireguardAddPeer(link Link, peer WireguardPeer) error {
+	var flags uint32
+	if peer.ReplaceAllowedIPs {
+		flags |= nl.WGPEER_F_REPLACE_ALLOWEDIPS
+	}
+	if peer.Endpoint != nil && peer.Endpoint.Zone != "" {
+		index, err := h.wireguardZoneIndex(peer.Endpoint.Zone)
+		if err != nil {
+			return err
+		}
+		endpoint := *peer.Endpoint
+		endpoint.Zone = strconv.FormatUint(uint64(index), 10)
+		peer.Endpoint = &endpoint
+	}
+	return h.wireguardSetPeer(link, peer.toNlData(flags))
+}
+
+// WireguardRemovePeer removes the peer with the public key from the
+// wireguard link. Removing a peer the link does not have is not an error.
+// Equivalent to: `wg set $link peer $key remove`
+func WireguardRemovePeer(link Link, publicKey [32]byte) error {
+	return pkgHandle.WireguardRemovePeer(link, publicKey)
+}
+
+// WireguardRemovePeer removes the peer with the public key from the
+// wireguard link. Removing a peer the link does not have is not an error.
+// Equivalent to: `wg set $link peer $key remove`
+func (h *Handle) WireguardRemovePeer(link Link, publicKey [32]byte) error {
+	peer := WireguardPeer{PublicKey: publicKey}
+	return h.wireguardSetPeer(link, peer.toNlData(nl.WGPEER_F_REMOVE_ME))
+}
+
+// WireguardSetPrivateKey sets the private key of the wireguard link, the
+// kernel derives its public key from it.
+// Equivalent to: `wg set $link private-key $file`
+func WireguardSetPrivateKey(link Link, key [32]byte) error {
+	return pkgHandle.WireguardSetPrivateKey(link, key)
+}
+
+// WireguardSetPrivateKey sets the private key of the wireguard link, the
+// kernel derives its public key from it.
+// Equivalent to: `wg set $link private-key $file`
+func (h *Handle) WireguardSetPrivateKey(link Link, key [32]byte) error {
+	return h.wireguardSetDevice(link, nl.NewRtAttr(nl.WGDEVICE_A_PRIVATE_KEY, key[:]))
+}
+
+// WireguardSetListenPort sets the UDP port the wireguard link listens on,
+// 0 lets the kernel pick one.
+// Equivalent to: `wg set $link listen-port $port`
+func WireguardSetListenPort(link Link, port uint16) error {
+	return pkgHandle.WireguardSetListenPort(link, port)
+}
+
+// WireguardSetListenPort sets the UDP port the wireguard link listens on,
+// 0 lets the kernel pick one.
+// Equivalent to: `wg set $link listen-port $port`
+func (h *Handle) WireguardSetListenPort(link Link, port uint16) error {
+	return h.wireguardSetDevice(link, nl.NewRtAttr(nl.WGDEVICE_A_LISTEN_PORT, nl.Uint16Attr(port)))
+}
+
+func (h *Handle) wireguardSetPeer(link Link, peer *nl.RtAttr) error {
+	peers := nl.NewRtAttr(nl.WGDEVICE_A_PEERS|syscall.NLA_F_NESTED, nil)
+	peers.AddChild(peer)
+	return h.wireguardSetDevice(link, peers)
+}
+
+func (h *Handle) wireguardSetDevice(link Link, attr *nl.RtAttr) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+
+	req, err := h.newWireguardRequest(nl.WG_CMD_SET_DEVICE, syscall.NLM_F_ACK)
+	if err != nil {
+		return err
+	}
+	req.AddData(nl.NewRtAttr(nl.WGDEVICE_A_IFINDEX, nl.Uint32Attr(uint32(base.Index))))
+	req.AddData(attr)
+
+	_, err = req.Execute(syscall.NETLINK_GENERIC, 0)
+	return err
+}
+
+func (h *Handle) newWireguardRequest(cmd uint8, flags int) (*nl.NetlinkRequest, error) {
+	f, err := h.GenlFamilyGet(nl.WG_GENL_NAME)
+	if err != nil {
+		return nil, err
+	}
+	req := h.newNetlinkRequest(int(f.ID), flags)
+	req.AddData(&nl.Genlmsg{
+		Command: cmd,
+		Version: nl.WG_GENL_VERSION,
+	})
+	return req, nil
+}
+
+// toNlData encodes the peer as an entry of WGDEVICE_A_PEERS, the kernel
+// ignores the type of the entries.
+func (p *WireguardPeer) toNlData(flags uint32) *nl.RtAttr {
+	peer := nl.NewRtAttr(syscall.NLA_F_NESTED, nil)
+	peer.AddRtAttr(nl.WGPEER_A_PUBLIC_KEY, p.PublicKey[:])
+	peer.AddRtAttr(nl.WGPEER_A_FLAGS, nl.Uint32Attr(flags))
+	if flags&nl.WGPEER_F_REMOVE_ME != 0 {
+		return peer
+	}
+	if p.PresharedKey != [nl.WG_KEY_LEN]byte{} {
+		peer.AddRtAttr(nl.WGPEER_A_PRESHARED_KEY, p.PresharedKey[:])
+	}
+	if p.Endpoint != nil {
+		peer.AddRtAttr(nl.WGPEER_A_ENDPOINT, encodeWireguardEndpoint(p.Endpoint))
+	}
+	if p.PersistentKeepaliveInterval != nil {
+		peer.AddRtAttr(nl.WGPEER_A_PERSISTENT_KEEPALIVE_INTERVAL, nl.Uint16Attr(*p.PersistentKeepaliveInterval))
+	}
+	if len(p.AllowedIPs) > 0 {
+		ips := peer.AddRtAttr(nl.WGPEER_A_ALLOWEDIPS|syscall.NLA_F_NESTED, nil)
+		for _, ipNet := range p.AllowedIPs {
+			family, ip := syscall.AF_INET6, ipNet.IP.To16()
+			if ip4 := ipNet.IP.To4(); ip4 != nil {
+				family, ip = syscall.AF_INET, ip4
+			}
+			ones, _ := ipNet.Mask.Size()
+			entry := ips.AddRtAttr(syscall.NLA_F_NESTED, nil)
+			entry.AddRtAttr(nl.WGALLOWEDIP_A_FAMILY, nl.Uint16Attr(uint16(family)))
+			entry.AddRtAttr(nl.WGALLOWEDIP_A_IPADDR, []byte(ip))
+			entry.AddRtAttr(nl.WGALLOWEDIP_A_CIDR_MASK, nl.Uint8Attr(uint8(ones)))
+		}
+	}
+	return peer
+}
+
+func parseWireguardPeer(b []byte) (WireguardPeer, error) {
+	var peer WireguardPeer
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return peer, err
+	}
+	for _, attr := range attrs {
+		switch attr.Attr.Type &^ syscall.NLA_F_NESTED {
+		case nl.WGPEER_A_PUBLIC_KEY:
+			copy(peer.PublicKey[:], attr.Value)
+		case nl.WGPEER_A_PRESHARED_KEY:
+			copy(peer.PresharedKey[:], attr.Value)
+		case nl.WGPEER_A_ENDPOINT:
+			peer.Endpoint, err = decodeWireguardEndpoint(attr.Value)
+			if err != nil {
+				return peer, err
+			}
+		case nl.WGPEER_A_PERSISTENT_KEEPALIVE_INTERVAL:
+			interval := native.Uint16(attr.Value)
+			peer.PersistentKeepaliveInterval = &interval
+		case nl.WGPEER_A_LAST_HANDSHAKE_TIME:
+			// struct __kernel_timespec
+			var ts struct{ Sec, Nsec int64 }
+			if err := binary.Read(bytes.NewReader(attr.Value), native, &ts); err != nil {
+				return peer, err
+			}
+			if ts.Sec != 0 || ts.Nsec != 0 {
+				peer.LastHandshakeTime = time.Unix(ts.Sec, ts.Nsec)
+			}
+		case nl.WGPEER_A_RX_BYTES:
+			peer.RxBytes = native.Uint64(attr.Value)
+		case nl.WGPEER_A_TX_BYTES:
+			peer.TxBytes = native.Uint64(attr.Value)
+		case nl.WGPEER_A_ALLOWEDIPS:
+			peer.AllowedIPs, err = parseWireguardAllowedIPs(attr.Value)
+			if err != nil {
+				return peer, err
+			}
+		}
+	}
+	return peer, nil
+}
+
+func parseWireguardAllowedIPs(b []byte) ([]net.IPNet, error) {
+	entries, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return nil, err
+	}
+	var ipNets []net.IPNet
+	for _, entry := range entries {
+		attrs, err := nl.ParseRouteAttr(entry.Value)
+		if err != nil {
+			return nil, err
+		}
+		var ip net.IP
+		var ones int
+		for _, attr := range attrs {
+			switch attr.Attr.Type {
+			case nl.WGALLOWEDIP_A_IPADDR:
+				ip = net.IP(attr.Value)
+			case nl.WGALLOWEDIP_A_CIDR_MASK:
+				ones = int(attr.Value[0])
+			}
+		}
+		ipNets = append(ipNets, net.IPNet{IP: ip, Mask: net.CIDRMask(ones, 8*len(ip))})
+	}
+	return ipNets, nil
+}
+
+// The endpoint is a struct sockaddr_in or sockaddr_in6, the zone of
+// link-local endpoints is the index of their link.
+func encodeWireguardEndpoint(addr *net.UDPAddr) []byte {
+	if ip4 := addr.IP.To4(); ip4 != nil {
+		b := make([]byte, syscall.SizeofSockaddrInet4)
+		native.PutUint16(b, syscall.AF_INET)
+		binary.BigEndian.PutUint16(b[2:], uint16(addr.Port))
+		copy(b[4:], ip4)
+		return b
+	}
+	b := make([]byte, syscall.SizeofSockaddrInet6)
+	native.PutUint16(b, syscall.AF_INET6)
+	binary.BigEndian.PutUint16(b[2:], uint16(addr.Port))
+	copy(b[8:], addr.IP.To16())
+	index, _ := strconv.ParseUint(addr.Zone, 10, 32)
+	native.PutUint32(b[24:], uint32(index))
+	return b
+}
+
+// wireguardZoneIndex returns the sin6_scope_id of the zone of an endpoint,
+// the index of a link of the handle's namespace or its name.
+func (h *Handle) wireguardZoneIndex(zone string) (uint32, error) {
+	if index, err := strconv.ParseUint(zone, 10, 32); err == nil {
+		return uint32(index), nil
+	}
+	link, err := h.LinkByName(zone)
+	if err != nil {
+		return 0, fmt.Errorf("endpoint zone %s: %w", zone, err)
+	}
+	return uint32(link.Attrs().Index), nil
+}
+
+func decodeWireguardEndpoint(b []byte) (*net.UDPAddr, error) {
+	if len(b) < 2 {
+		return nil, fmt.Errorf("wireguard endpoint too short: %d bytes", len(b))
+	}
+	switch native.Uint16(b) {
+	case syscall.AF_INET:
+		if len(b) < syscall.SizeofSockaddrInet4 {
+			break
+		}
+		return &net.UDPAddr{
+			IP:   net.IP(append([]byte(nil), b[4:8]...)),
+			Port: int(binary.BigEndian.Uint16(b[2:])),
+		}, nil
+	case syscall.AF_INET6:
+		if len(b) < syscall.SizeofSockaddrInet6 {
+			break
+		}
+		addr := &net.UDPAddr{
+			IP:   net.IP(append([]byte(nil), b[8:24]...)),
+			Port: int(binary.BigEndian.Uint16(b[2:])),
+		}
+		if index := native.Uint32(b[24:28]); index != 0 {
+			addr.Zone = strconv.FormatUint(uint64(index), 10)
+		}
+		return addr, nil
+	}
+	return nil, fmt.Errorf("invalid wireguard endpoint of family %d", native.Uint16(b))
+}
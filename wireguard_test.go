@@ -0,0 +1,214 @@
+// +build linux
+
+package netlink
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"net"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWireguardAddRemovePeer(t *testing.T) {
+	tearDown := setUpNetlinkTestWithKModule(t, "wireguard")
+	defer tearDown()
+
+	if err := LinkAdd(&GenericLink{LinkAttrs: LinkAttrs{Name: "wg0"}, LinkType: "wireguard"}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("wg0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, v4Net, _ := net.ParseCIDR("10.0.0.0/24")
+	_, v6Net, _ := net.ParseCIDR("fd00::/64")
+	keepalive := uint16(25)
+	first := WireguardPeer{
+		PublicKey:                   [32]byte{1},
+		Endpoint:                    &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 51820},
+		PersistentKeepaliveInterval: &keepalive,
+		AllowedIPs:                  []net.IPNet{*v4Net},
+	}
+	second := WireguardPeer{
+		PublicKey:  [32]byte{2},
+		AllowedIPs: []net.IPNet{*v6Net},
+	}
+	if err := WireguardAddPeer(link, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := WireguardAddPeer(link, second); err != nil {
+		t.Fatal(err)
+	}
+	// updating the peer without an interval keeps the one it has
+	if err := WireguardAddPeer(link, WireguardPeer{PublicKey: first.PublicKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	peers, err := WireguardPeerList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 2 {
+		t.Fatalf("Found %d peers, expected 2: %v", len(peers), peers)
+	}
+	for _, peer := range peers {
+		if peer.PublicKey != first.PublicKey {
+			continue
+		}
+		if !peer.Endpoint.IP.Equal(first.Endpoint.IP) || peer.Endpoint.Port != first.Endpoint.Port {
+			t.Fatalf("Endpoint is %v, expected %v", peer.Endpoint, first.Endpoint)
+		}
+		if peer.PersistentKeepaliveInterval == nil || *peer.PersistentKeepaliveInterval != keepalive {
+			t.Fatalf("Keepalive is %v, expected %d", peer.PersistentKeepaliveInterval, keepalive)
+		}
+		if len(peer.AllowedIPs) != 1 || peer.AllowedIPs[0].String() != v4Net.String() {
+			t.Fatalf("Allowed ips are %v, expected %v", peer.AllowedIPs, v4Net)
+		}
+		// the link has no private key so no handshake can happen
+		if !peer.LastHandshakeTime.IsZero() || peer.RxBytes != 0 || peer.TxBytes != 0 {
+			t.Fatalf("Unexpected handshake at %v with %d/%d bytes", peer.LastHandshakeTime, peer.RxBytes, peer.TxBytes)
+		}
+	}
+
+	if err := WireguardRemovePeer(link, first.PublicKey); err != nil {
+		t.Fatal(err)
+	}
+	peers, err = WireguardPeerList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || peers[0].PublicKey != second.PublicKey {
+		t.Fatalf("Peers are %v, expected only %v", peers, second)
+	}
+	if len(peers[0].AllowedIPs) != 1 || peers[0].AllowedIPs[0].String() != v6Net.String() {
+		t.Fatalf("Allowed ips are %v, expected %v", peers[0].AllowedIPs, v6Net)
+	}
+}
+
+func TestWireguardHandshake(t *testing.T) {
+	tearDown := setUpNetlinkTestWithKModule(t, "wireguard")
+	defer tearDown()
+
+	lo, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(lo); err != nil {
+		t.Fatal(err)
+	}
+
+	// wg0 and wg1 are each other's peer over lo
+	var (
+		links [2]Link
+		keys  [2]*ecdh.PrivateKey
+	)
+	ports := [2]uint16{51820, 51821}
+	for i, name := range []string{"wg0", "wg1"} {
+		if err := LinkAdd(&GenericLink{LinkAttrs: LinkAttrs{Name: name}, LinkType: "wireguard"}); err != nil {
+			t.Fatal(err)
+		}
+		if links[i], err = LinkByName(name); err != nil {
+			t.Fatal(err)
+		}
+		if keys[i], err = ecdh.X25519().GenerateKey(rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+		var key [32]byte
+		copy(key[:], keys[i].Bytes())
+		if err := WireguardSetPrivateKey(links[i], key); err != nil {
+			t.Fatal(err)
+		}
+		if err := WireguardSetListenPort(links[i], ports[i]); err != nil {
+			t.Fatal(err)
+		}
+		if err := LinkSetUp(links[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the keepalive of an up link starts the handshake right away
+	keepalive := uint16(1)
+	for i := range links {
+		peer := WireguardPeer{
+			Endpoint:                    &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: int(ports[1-i])},
+			PersistentKeepaliveInterval: &keepalive,
+			AllowedIPs:                  []net.IPNet{{IP: net.IPv4(10, 0, 0, byte(2-i)), Mask: net.CIDRMask(32, 32)}},
+		}
+		copy(peer.PublicKey[:], keys[1-i].PublicKey().Bytes())
+		if err := WireguardAddPeer(links[i], peer); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := range links {
+		var peer WireguardPeer
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(100 * time.Millisecond) {
+			peers, err := WireguardPeerList(links[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(peers) != 1 {
+				t.Fatalf("Found %d peers on %s, expected 1: %v", len(peers), links[i].Attrs().Name, peers)
+			}
+			peer = peers[0]
+			if !peer.LastHandshakeTime.IsZero() && peer.RxBytes != 0 && peer.TxBytes != 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("No handshake on %s: %v at %v with %d/%d bytes", links[i].Attrs().Name,
+					peer, peer.LastHandshakeTime, peer.RxBytes, peer.TxBytes)
+			}
+		}
+		if peer.Endpoint == nil || !peer.Endpoint.IP.Equal(net.IPv4(127, 0, 0, 1)) || peer.Endpoint.Port != int(ports[1-i]) {
+			t.Fatalf("Endpoint of the peer of %s is %v, expected 127.0.0.1:%d", links[i].Attrs().Name, peer.Endpoint, ports[1-i])
+		}
+	}
+}
+
+func TestWireguardEndpointDecode(t *testing.T) {
+	lo, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := pkgHandle.wireguardZoneIndex("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(index) != lo.Attrs().Index {
+		t.Fatalf("Zone lo resolved to %d, expected %d", index, lo.Attrs().Index)
+	}
+	endpoints := []*net.UDPAddr{
+		{IP: net.ParseIP("192.0.2.1").To4(), Port: 51820},
+		{IP: net.ParseIP("2001:db8::1"), Port: 443},
+		{IP: net.ParseIP("fe80::1"), Port: 51821, Zone: strconv.Itoa(lo.Attrs().Index)},
+	}
+	for _, endpoint := range endpoints {
+		decoded, err := decodeWireguardEndpoint(encodeWireguardEndpoint(endpoint))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.String() != endpoint.String() {
+			t.Fatalf("Endpoint decoded as %v, expected %v", decoded, endpoint)
+		}
+	}
+
+	// sockaddr_in6 of [2001:db8::2]:51820 as sent by the kernel
+	raw := make([]byte, syscall.SizeofSockaddrInet6)
+	native.PutUint16(raw, syscall.AF_INET6)
+	raw[2], raw[3] = 0xca, 0x6c
+	copy(raw[8:], net.ParseIP("2001:db8::2"))
+	decoded, err := decodeWireguardEndpoint(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.IP.Equal(net.ParseIP("2001:db8::2")) || decoded.Port != 51820 || decoded.Zone != "" {
+		t.Fatalf("Endpoint decoded as %v, expected [2001:db8::2]:51820", decoded)
+	}
+
+	if _, err := decodeWireguardEndpoint(raw[:8]); err == nil {
+		t.Fatal("Expected an error for a truncated endpoint")
+	}
+}